@@ -3,9 +3,13 @@ package app
 import (
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/logutils"
 	"github.com/urfave/cli/v2"
+
+	"github.com/kkoch986/gopl/resolver"
 )
 
 func enableLogger(ctx *cli.Context) {
@@ -48,14 +52,96 @@ var App = &cli.App{
 			Name:    "shell",
 			Aliases: []string{"s", ""},
 			Usage:   "Enter the interactive query shell",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.BoolFlag{
 					Name:    "verbose",
 					Aliases: []string{"vv"},
 					Value:   false,
 				},
-			},
+				explainFlag,
+			}, limitFlags...),
 			Action: handleLogger(interactive),
 		},
+		{
+			Name:      "run",
+			Aliases:   []string{"r"},
+			Usage:     "consult the given file, running its directives and then the goal",
+			ArgsUsage: "<filename>",
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:    "goal",
+					Aliases: []string{"g"},
+					Usage:   "goal to run once the file is loaded",
+				},
+				explainFlag,
+				&cli.BoolFlag{
+					Name:  "profile",
+					Usage: "print a table of the time spent in each predicate to stderr once finished",
+				},
+				&cli.StringFlag{
+					Name:  "profile-sort",
+					Usage: "column the profile table is sorted by (" + strings.Join(resolver.ProfileColumns, ", ") + ")",
+					Value: "cpu",
+				},
+				&cli.StringFlag{
+					Name:  "profile-output",
+					Usage: "write the profile to `FILE` for use with go tool pprof",
+				},
+				&cli.BoolFlag{
+					Name:  "coverage",
+					Usage: "print how many times each clause in the file was used to stderr once finished",
+				},
+				&cli.StringFlag{
+					Name:  "coverage-html",
+					Usage: "write an HTML coverage report marking the clauses that were and werent used to `FILE`",
+				},
+			}, limitFlags...),
+			Action: run,
+		},
+		{
+			Name:      "test",
+			Aliases:   []string{"t"},
+			Usage:     "consult the given files and run the tests between their begin_tests and end_tests directives",
+			ArgsUsage: "<filename> [filenames...]",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "maximum time each test can run for unless it has a timeout option (0 for no limit)",
+					Value: 30 * time.Second,
+				},
+				&cli.StringFlag{
+					Name:  "junit",
+					Usage: "write the results as JUnit XML to `FILE`",
+				},
+				&cli.BoolFlag{
+					Name:    "verbose",
+					Aliases: []string{"v"},
+					Usage:   "list the tests which passed as well",
+				},
+				&cli.BoolFlag{
+					Name:  "coverage",
+					Usage: "print how many times each clause in the files was used to stderr once finished",
+				},
+				&cli.StringFlag{
+					Name:  "coverage-html",
+					Usage: "write an HTML coverage report marking the clauses that were and werent used to `FILE`",
+				},
+			},
+			Action: test,
+		},
+		{
+			Name:      "datalog",
+			Aliases:   []string{"d"},
+			Usage:     "consult the given file and evaluate its rules bottom-up, printing the facts they derive",
+			ArgsUsage: "<filename>",
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:    "goal",
+					Aliases: []string{"g"},
+					Usage:   "goal to run once the rules are evaluated, rather than printing the facts",
+				},
+			}, limitFlags...),
+			Action: datalog,
+		},
 	},
 }
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/engine"
+)
+
+/**
+ * datalog consults a file and evaluates it bottom-up, see engine.SetDatalog.
+ * Every derived fact is printed, grouped by predicate, unless there is a --goal in which case
+ * it is run like it is by run and the exit code is 1 if it fails.
+ */
+func datalog(c *cli.Context) error {
+	if err := datalogFile(c); err != nil {
+		return cli.Exit(err, 1)
+	}
+	return nil
+}
+
+func datalogFile(c *cli.Context) error {
+	filename := c.Args().First()
+	if filename == "" {
+		_ = cli.ShowCommandHelp(c, "datalog")
+		return errors.New("Filename is required")
+	}
+	log.SetOutput(ioutil.Discard)
+
+	e, err := engine.New(limitOptions(c)...)
+	if err != nil {
+		return err
+	}
+	if err := e.ConsultFile(filename); err != nil {
+		return err
+	}
+	relations, err := e.Datalog()
+	if err != nil {
+		return err
+	}
+
+	goal := c.String("goal")
+	if goal == "" {
+		for _, r := range relations {
+			for _, f := range r.Facts {
+				fmt.Fprintf(os.Stdout, "%s.\n", ast.WriteTerm(f, ast.WriteOptions{Quoted: true}))
+			}
+		}
+		return nil
+	}
+	sols, err := e.Query(context.Background(), goal)
+	if err != nil {
+		return err
+	}
+	defer sols.Close()
+	if sols.Next() {
+		return nil
+	}
+	if err := sols.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("goal failed: %s", goal)
+}
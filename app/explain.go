@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// explainFlag is shared by every command which runs queries, see resolver.WithExplain
+var explainFlag = &cli.StringFlag{
+	Name:  "explain",
+	Usage: "print the proof of each solution as `FORMAT` (text, json or dot)",
+}
+
+// explainOptions turns on proof recording when --explain is given, checking the format is one we can write
+func explainOptions(c *cli.Context) ([]resolver.Option, error) {
+	switch c.String("explain") {
+	case "":
+		return nil, nil
+	case "text", "json", "dot":
+		return []resolver.Option{resolver.WithExplain()}, nil
+	}
+	return nil, fmt.Errorf("unknown explain format %q, expected text, json or dot", c.String("explain"))
+}
+
+// writeProofs writes the proofs in the given --explain format
+func writeProofs(w io.Writer, format string, proofs []*resolver.Proof) error {
+	switch format {
+	case "json":
+		return resolver.WriteProofJSON(w, proofs)
+	case "dot":
+		return resolver.WriteProofDOT(w, proofs)
+	}
+	return resolver.WriteProofText(w, proofs)
+}
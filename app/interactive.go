@@ -3,27 +3,31 @@ package app
 // Define the interactive shell used for querying
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	"github.com/c-bata/go-prompt"
 
 	"github.com/kkoch986/gopl/ast"
 	"github.com/kkoch986/gopl/indexer"
-	"github.com/kkoch986/gopl/lexer"
-	"github.com/kkoch986/gopl/parser"
+	"github.com/kkoch986/gopl/library"
 	"github.com/kkoch986/gopl/raw"
 	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
 )
 
 type QueryCLI struct {
 	I indexer.Indexer
 	R *resolver.R
 	H *history
+	// Explain is the format proofs are printed in after each solution, empty to not print them
+	Explain string
 }
 
 func completer(d prompt.Document) []prompt.Suggest {
@@ -39,21 +43,37 @@ func (q *QueryCLI) execCommand(t string) {
 	// insert the command into the history
 	go q.H.Insert(t)
 
-	// lex and parse the input
-	l := lexer.New([]rune("?- " + t))
-	if bsrSet, errs := parser.Parse(l); len(errs) > 0 {
-		log.Println(errs)
+	// parse the input, the final `.` is optional
+	if query, err := q.R.Ops().ParseQuery(strings.TrimSuffix(strings.TrimSpace(t), ".")); err != nil {
+		fmt.Println(err)
 	} else {
-		a := ast.BuildStatementList(bsrSet.GetRoot())
+		a := []ast.Statement{query}
 		output := make(chan *resolver.Bindings, 1)
 		log.Println("Resolving...")
-		go q.R.ResolveStatementList(a, &resolver.Bindings{}, output)
+
+		// cancelling stops the search for more solutions once the user is done with this query
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.R.ResolveStatementList(ctx, a, &resolver.Bindings{}, output)
 		for v := range output {
+			if v.IsException() {
+				if v.Exception.GetType() == ast.T_Atom && v.Exception.String() == "$aborted" {
+					fmt.Println("% Execution Aborted")
+				} else {
+					fmt.Println("Uncaught exception:", ast.WriteTerm(v.Exception, ast.WriteOptions{Quoted: true}))
+				}
+				return
+			}
 			if v.Empty() {
 				fmt.Println("Yes.")
 			} else {
 				fmt.Println("OUTPUT", v)
 			}
+			if q.Explain != "" {
+				if err := writeProofs(os.Stdout, q.Explain, v.Proofs()); err != nil {
+					log.Println(err)
+				}
+			}
 
 			t := prompt.Input(">", completer)
 			if t != ";" {
@@ -99,11 +119,23 @@ func interactive(c *cli.Context) error {
 		return err
 	}
 
+	if err := library.Load(i); err != nil {
+		log.Fatal(err)
+		return err
+	}
+
+	explain, err := explainOptions(c)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
 	shell := &QueryCLI{
-		I: i,
-		R: resolver.New(i),
-		H: h,
+		I:       i,
+		R:       resolver.New(i, append(limitOptions(c), explain...)...),
+		H:       h,
+		Explain: c.String("explain"),
 	}
+	shell.R.SetTracer(&stepper{})
 	err = shell.Run()
 	if err != nil {
 		log.Fatal(err)
@@ -123,27 +155,50 @@ func compile(c *cli.Context) error {
 	}
 	fmt.Printf("Compiling %s\n", filename)
 
-	// Parse the file
-	l := lexer.NewFile(filename)
-	if bsrSet, errs := parser.Parse(l); len(errs) > 0 {
-		log.Fatal(errs)
-		return errors.New("Parser Error")
-	} else {
-		a := ast.BuildStatementList(bsrSet.GetRoot())
-		fmt.Println(a)
+	// Parse the file, reporting every syntax error in it
+	a, err := syntax.ParseFile(filename)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+	a, err = expand(a)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("%s: %w", filename, err), 1)
+	}
+	fmt.Println(a)
 
-		// open the file for writing
-		f, err := os.Create(outfile)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+	// open the file for writing
+	f, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return raw.Serialize(a, f)
+}
 
-		err = raw.Serialize(a, f)
+/**
+ * expand runs the clauses through term_expansion/2 and goal_expansion/2 as consulting them would, so the raw
+ * output holds the expanded clauses. Each clause is indexed once it is expanded so the hooks can be defined
+ * in the file itself, the directives are kept but not run.
+ */
+func expand(statements []ast.Statement) ([]ast.Statement, error) {
+	i := indexer.NewDefault()
+	if err := library.Load(i); err != nil {
+		return nil, err
+	}
+	r := resolver.New(i)
+	expanded := []ast.Statement{}
+	for _, s := range statements {
+		clauses, err := r.Expand(context.Background(), s)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		for _, c := range clauses {
+			if c.GetType() != ast.T_Query {
+				i.IndexStatement(c)
+			}
+		}
+		expanded = append(expanded, clauses...)
 	}
-	return nil
-
+	return expanded, nil
 }
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/kkoch986/gopl/engine"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// limitFlags are shared by every command which runs queries, see resolver.Limits
+var limitFlags = []cli.Flag{
+	&cli.Int64Flag{
+		Name:  "max-inferences",
+		Usage: "maximum number of inferences a query can make (0 for no limit)",
+	},
+	&cli.IntFlag{
+		Name:  "max-depth",
+		Usage: "maximum depth of nested rule calls in a query (0 for no limit)",
+	},
+	&cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "maximum time a query can run for, i.e. 5s (0 for no limit)",
+	},
+	&cli.IntFlag{
+		Name:  "max-bindings",
+		Usage: "maximum number of variable bindings a query can hold (0 for no limit)",
+	},
+}
+
+// limitOptions builds the resolver options from the limit flags
+func limitOptions(c *cli.Context) []resolver.Option {
+	return []resolver.Option{
+		resolver.WithLimits(resolver.Limits{
+			MaxInferences: c.Int64("max-inferences"),
+			MaxDepth:      c.Int("max-depth"),
+			Timeout:       c.Duration("timeout"),
+			MaxBindings:   c.Int("max-bindings"),
+		}),
+	}
+}
+
+/**
+ * run consults a file and then runs the goal given with --goal (if there is one).
+ * Only the first solution of the goal is used, the exit code is 1 if it fails or raises an exception.
+ * With --explain the proof of that solution is printed.
+ * With --profile or --profile-output the whole run is profiled, including the file's directives.
+ * The coverage flags report which of the file's clauses were used.
+ */
+func run(c *cli.Context) error {
+	if err := runFile(c); err != nil {
+		return cli.Exit(err, 1)
+	}
+	return nil
+}
+
+func runFile(c *cli.Context) (err error) {
+	filename := c.Args().First()
+	if filename == "" {
+		_ = cli.ShowCommandHelp(c, "run")
+		return errors.New("Filename is required")
+	}
+	log.SetOutput(ioutil.Discard)
+
+	explain, err := explainOptions(c)
+	if err != nil {
+		return err
+	}
+	opts := append(limitOptions(c), explain...)
+	if c.Bool("profile") || c.String("profile-output") != "" {
+		if !profileColumn(c.String("profile-sort")) {
+			return fmt.Errorf("unknown profile column %q, expected one of %s", c.String("profile-sort"), strings.Join(resolver.ProfileColumns, ", "))
+		}
+		profile := resolver.NewProfile()
+		opts = append(opts, resolver.WithProfile(profile))
+		defer func() {
+			if perr := writeProfile(c, profile); err == nil {
+				err = perr
+			}
+		}()
+	}
+
+	var coverage *resolver.Coverage
+	if c.Bool("coverage") || c.String("coverage-html") != "" {
+		coverage = resolver.NewCoverage()
+		opts = append(opts, resolver.WithCoverage(coverage))
+	}
+
+	e, err := engine.New(opts...)
+	if err != nil {
+		return err
+	}
+	if coverage != nil {
+		defer func() {
+			if cerr := writeCoverage(c, coverage.Clauses(e.Indexer())); err == nil {
+				err = cerr
+			}
+		}()
+	}
+	if err := e.ConsultFile(filename); err != nil {
+		return err
+	}
+
+	goal := c.String("goal")
+	if goal == "" {
+		return nil
+	}
+	sols, err := e.Query(context.Background(), goal)
+	if err != nil {
+		return err
+	}
+	defer sols.Close()
+	if sols.Next() {
+		if len(explain) > 0 {
+			return writeProofs(os.Stdout, c.String("explain"), sols.Proofs())
+		}
+		return nil
+	}
+	if err := sols.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("goal failed: %s", goal)
+}
+
+func profileColumn(name string) bool {
+	for _, column := range resolver.ProfileColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeProfile prints the profile table and writes the pprof file if they were asked for
+func writeProfile(c *cli.Context, profile *resolver.Profile) error {
+	if c.Bool("profile") {
+		if err := profile.WriteTable(os.Stderr, c.String("profile-sort")); err != nil {
+			return err
+		}
+	}
+	filename := c.String("profile-output")
+	if filename == "" {
+		return nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := profile.WritePprof(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeCoverage prints the coverage report and writes the HTML version if they were asked for
+func writeCoverage(c *cli.Context, clauses []resolver.ClauseCoverage) error {
+	if c.Bool("coverage") {
+		if err := resolver.WriteCoverageText(os.Stderr, clauses); err != nil {
+			return err
+		}
+	}
+	filename := c.String("coverage-html")
+	if filename == "" {
+		return nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := resolver.WriteCoverageHTML(f, clauses); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/kkoch986/gopl/engine"
+	"github.com/kkoch986/gopl/plunit"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+/**
+ * test consults all of the files and then runs the tests they declared with begin_tests/end_tests.
+ * The exit code is 1 if any of the tests failed.
+ */
+func test(c *cli.Context) error {
+	ok, err := testFiles(c)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+	if !ok {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+func testFiles(c *cli.Context) (ok bool, err error) {
+	if c.NArg() == 0 {
+		_ = cli.ShowCommandHelp(c, "test")
+		return false, errors.New("at least one file is required")
+	}
+	log.SetOutput(ioutil.Discard)
+
+	opts := []resolver.Option{}
+	var coverage *resolver.Coverage
+	if c.Bool("coverage") || c.String("coverage-html") != "" {
+		coverage = resolver.NewCoverage()
+		opts = append(opts, resolver.WithCoverage(coverage))
+	}
+	e, err := engine.New(opts...)
+	if err != nil {
+		return false, err
+	}
+	for _, filename := range c.Args().Slice() {
+		if err := e.ConsultFile(filename); err != nil {
+			return false, err
+		}
+	}
+
+	runner := &plunit.Runner{Timeout: c.Duration("timeout")}
+	results := runner.Run(context.Background(), e)
+	if err := plunit.WriteText(os.Stdout, results, c.Bool("verbose")); err != nil {
+		return false, err
+	}
+	if filename := c.String("junit"); filename != "" {
+		if err := writeJUnit(filename, results); err != nil {
+			return false, err
+		}
+	}
+	if coverage != nil {
+		if err := writeCoverage(c, coverage.Clauses(e.Indexer())); err != nil {
+			return false, err
+		}
+	}
+	return plunit.Summarize(results).OK(), nil
+}
+
+func writeJUnit(filename string, results []plunit.Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := plunit.WriteJUnit(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
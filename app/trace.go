@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+const stepperHelp = `Options:
+  c, <enter>  creep  - go to the next port
+  s           skip   - hide everything inside this goal
+  l           leap   - continue until the next spy point
+  a           abort  - stop the query
+  b           print the bindings of the goal's clause
+  h           show this help`
+
+// stepper is the interactive tracer used by the shell, it stops at every port and asks what to do next
+type stepper struct{}
+
+func noCompletions(d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{}
+}
+
+func (s *stepper) Trace(e *resolver.TraceEvent) resolver.Action {
+	for {
+		switch strings.TrimSpace(prompt.Input(e.String()+" ? ", noCompletions)) {
+		case "", "c":
+			return resolver.Creep
+		case "s":
+			return resolver.Skip
+		case "l":
+			return resolver.Leap
+		case "a":
+			return resolver.Abort
+		case "b":
+			printBindings(e.Bindings)
+		default:
+			fmt.Println(stepperHelp)
+		}
+	}
+}
+
+func printBindings(b map[string]ast.Term) {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("    %s = %s\n", name, ast.WriteTerm(b[name], ast.WriteOptions{Quoted: true}))
+	}
+}
@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"strings"
+)
+
+/**
+ * CompareTerms orders two terms using the standard order of terms:
+ *   Variable < Number < Atom < String < Compound
+ * Compound terms are ordered by arity, then name, then by their args from left to right.
+ * It returns -1, 0 or 1 similar to strings.Compare.
+ */
+func CompareTerms(a Term, b Term) int {
+	ra, rb := standardOrderRank(a), standardOrderRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch a.GetType() {
+	case T_Number:
+		av, bv := a.(*NumericLiteral).Value(), b.(*NumericLiteral).Value()
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+		return 0
+	case T_Fact:
+		af, bf := a.(*Fact), b.(*Fact)
+		if len(af.Args) != len(bf.Args) {
+			if len(af.Args) < len(bf.Args) {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(af.Head, bf.Head); c != 0 {
+			return c
+		}
+		for i := range af.Args {
+			if c := CompareTerms(af.Args[i], bf.Args[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	default:
+		return strings.Compare(a.String(), b.String())
+	}
+}
+
+func standardOrderRank(t Term) int {
+	switch t.GetType() {
+	case T_Variable:
+		return 0
+	case T_Number:
+		return 1
+	case T_Atom:
+		return 2
+	case T_String:
+		return 3
+	case T_Fact:
+		return 4
+	default:
+		return 5
+	}
+}
@@ -0,0 +1,186 @@
+package ast
+
+import (
+	"fmt"
+)
+
+/**
+ * Grammar rules (DCGs) are read as the term `-->`(Head, Body) and turned into ordinary rules when they are indexed.
+ * Each non-terminal is given two more arguments, the list before it is parsed and the list left after it,
+ * so `greeting --> [hello], name.` becomes `greeting(S0, S) :- S0 = [hello|S1], name(S1, S)`.
+ *   - lists are terminals and a string is the list of the codes of its characters
+ *   - `{Goal}` runs Goal without using up any of the list
+ *   - `call(G, Args...)` calls G with Args and the two lists
+ *   - `Head, Pushback --> Body` puts the terminals in Pushback back onto the list after Body is parsed
+ *   - `(A, B)` parses A and then B, the control constructs `!`, `;`, `->` and `\+` are rejected since rule bodies cant use them either
+ *   - a variable is parsed with phrase/3 when the rule is run
+ */
+
+// IsDCG reports whether the fact is a grammar rule
+func IsDCG(f *Fact) bool {
+	return f.Head == "-->" && len(f.Args) == 2
+}
+
+// TranslateDCG turns a grammar rule into the rule it stands for, it is an error if the rule has no translation
+func TranslateDCG(f *Fact) (*Rule, error) {
+	if !IsDCG(f) {
+		return nil, fmt.Errorf("%s is not a grammar rule", f)
+	}
+
+	// the lists are named S0, S1... skipping any names used in the rule so that they show up nicely in listings
+	used := make(map[string]bool)
+	for _, v := range f.ExtractVariables() {
+		used[v.String()] = true
+	}
+	next := 0
+	fresh := func() *Variable {
+		for {
+			name := fmt.Sprintf("S%d", next)
+			next++
+			if !used[name] {
+				return CreateVariable(name)
+			}
+		}
+	}
+
+	head, pushback := f.Args[0], Term(nil)
+	if h, ok := head.(*Fact); ok && h.Head == "," && len(h.Args) == 2 {
+		head, pushback = h.Args[0], h.Args[1]
+	}
+	s0, s := fresh(), fresh()
+	h := nonTerminal(head, s0, s)
+	if h == nil {
+		return nil, fmt.Errorf("the head of a grammar rule must be callable, found %s", WriteTerm(head, WriteOptions{Quoted: true}))
+	}
+
+	var goals []*Fact
+	var err error
+	if pushback == nil {
+		goals, err = TranslateDCGBody(f.Args[1], s0, s, fresh)
+	} else {
+		pb, ok := terminals(pushback)
+		if !ok {
+			return nil, fmt.Errorf("the pushback of a grammar rule must be a list, found %s", WriteTerm(pushback, WriteOptions{Quoted: true}))
+		}
+		mid := fresh()
+		goals, err = TranslateDCGBody(f.Args[1], s0, mid, fresh)
+		goals = append(goals, CreateFact("=", s, CreatePartialList(pb, mid)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body := Query{}
+	for _, g := range goals {
+		body = append(body, g)
+	}
+	h.Pos = f.Pos
+	return &Rule{Head: h, Body: &body, Pos: f.Pos}, nil
+}
+
+/**
+ * TranslateDCGBody translates the body of a grammar rule into the goals which parse it from the list s0,
+ * leaving the list s. fresh is called for each of the lists in between.
+ */
+func TranslateDCGBody(t Term, s0, s Term, fresh func() *Variable) ([]*Fact, error) {
+	switch v := t.(type) {
+	case *Variable:
+		return []*Fact{CreateFact("phrase", v, s0, s)}, nil
+	case *StringLiteral:
+		items, _ := terminals(v)
+		return []*Fact{CreateFact("=", s0, CreatePartialList(items, s))}, nil
+	case *Atom:
+		if v.String() == "!" {
+			return nil, fmt.Errorf("!/0 is not supported in grammar rules")
+		}
+		return []*Fact{nonTerminal(v, s0, s)}, nil
+	case *Fact:
+		switch {
+		case v.Head == "|" && (len(v.Args) == 0 || len(v.Args) == 2):
+			items, ok := terminals(v)
+			if !ok {
+				return nil, fmt.Errorf("a terminal in a grammar rule must be a list, found %s", WriteTerm(v, WriteOptions{Quoted: true}))
+			}
+			if len(items) == 0 {
+				return []*Fact{CreateFact("=", s0, s)}, nil
+			}
+			return []*Fact{CreateFact("=", s0, CreatePartialList(items, s))}, nil
+		case v.Head == "," && len(v.Args) == 2:
+			mid := fresh()
+			lhs, err := TranslateDCGBody(v.Args[0], s0, mid, fresh)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := TranslateDCGBody(v.Args[1], mid, s, fresh)
+			if err != nil {
+				return nil, err
+			}
+			return append(lhs, rhs...), nil
+		case (v.Head == ";" || v.Head == "->") && len(v.Args) == 2, v.Head == "\\+" && len(v.Args) == 1:
+			return nil, fmt.Errorf("%s/%d is not supported in grammar rules", FormatAtom(v.Head, true), len(v.Args))
+		case v.Head == "{}" && len(v.Args) == 1:
+			goals := goalsOf(v.Args[0])
+			if goals == nil {
+				return nil, fmt.Errorf("%s is not callable", WriteTerm(v.Args[0], WriteOptions{Quoted: true}))
+			}
+			return append(goals, CreateFact("=", s0, s)), nil
+		}
+		return []*Fact{nonTerminal(v, s0, s)}, nil
+	}
+	return nil, fmt.Errorf("%s is not a valid grammar rule body", WriteTerm(t, WriteOptions{Quoted: true}))
+}
+
+// nonTerminal adds the two lists to the arguments of a callable term, returning nil if it isnt callable
+func nonTerminal(t Term, s0, s Term) *Fact {
+	switch v := t.(type) {
+	case *Atom:
+		f := CreateFact(v.String(), s0, s)
+		f.Pos = v.Pos
+		return f
+	case *Fact:
+		if v.Head == "|" {
+			return nil
+		}
+		args := append(append([]Term{}, v.Args...), s0, s)
+		f := CreateFact(v.Head, args...)
+		f.Pos = v.Pos
+		return f
+	}
+	return nil
+}
+
+// terminals returns the items of a proper list or the codes of a string, reporting false for anything else
+func terminals(t Term) ([]Term, bool) {
+	if str, ok := t.(*StringLiteral); ok {
+		items := []Term{}
+		for _, c := range str.String() {
+			items = append(items, CreateNumericLiteral(float64(c)))
+		}
+		return items, true
+	}
+	if !IsProperList(t) {
+		return nil, false
+	}
+	items, _ := ListToSlice(t)
+	return items, true
+}
+
+// goalsOf flattens a conjunction into the goals it is made of, returning nil if any of them isnt callable
+func goalsOf(t Term) []*Fact {
+	switch v := t.(type) {
+	case *Variable:
+		return []*Fact{CreateFact("call", v)}
+	case *Atom:
+		return []*Fact{CreateFact(v.String())}
+	case *Fact:
+		if v.Head == "," && len(v.Args) == 2 {
+			lhs, rhs := goalsOf(v.Args[0]), goalsOf(v.Args[1])
+			if lhs == nil || rhs == nil {
+				return nil
+			}
+			return append(lhs, rhs...)
+		}
+		return []*Fact{v}
+	}
+	return nil
+}
@@ -18,6 +18,8 @@ func (s *Signature) String() string {
 type Fact struct {
 	Head string `json:"f"`
 	Args []Term `json:"a"`
+	// Pos is only set on facts which were parsed from source
+	Pos Position `json:"p"`
 }
 
 func CreateFact(h string, a ...Term) *Fact {
@@ -35,11 +37,12 @@ func (f *Fact) Anonymize(start int, prefix string, existing *map[string]string)
 		t := v.GetType()
 		switch t {
 		case T_Variable:
+			pos := v.(*Variable).Pos
 			if (*existing)[v.String()] != "" {
-				anonymousBody = append(anonymousBody, &Variable{(*existing)[v.String()]})
+				anonymousBody = append(anonymousBody, &Variable{string: (*existing)[v.String()], Pos: pos})
 			} else {
 				newVal := fmt.Sprintf("%s%d", prefix, start+used)
-				anonymousBody = append(anonymousBody, &Variable{newVal})
+				anonymousBody = append(anonymousBody, &Variable{string: newVal, Pos: pos})
 				(*existing)[v.String()] = newVal
 				used = used + 1
 			}
@@ -52,7 +55,7 @@ func (f *Fact) Anonymize(start int, prefix string, existing *map[string]string)
 		}
 	}
 
-	return &Fact{f.Head, anonymousBody}, used
+	return &Fact{Head: f.Head, Args: anonymousBody, Pos: f.Pos}, used
 }
 
 func (f *Fact) String() string {
@@ -63,10 +66,9 @@ func (f *Fact) String() string {
 	}
 
 	// TODO: handling pretty-printing lists (any fact with head == "|")
-	if f.Head == "|" {
-		if len(f.Args) == 0 {
-			return "L[]"
-		}
+	if f.Head == "|" && len(f.Args) == 0 {
+		return "L[]"
+	} else if f.Head == "|" && len(f.Args) == 2 {
 		return "L[" + prettyPrintList(f.Args) + "]"
 	}
 
@@ -94,6 +96,7 @@ func (f *Fact) MarshalJSON() ([]byte, error) {
 	m["t"] = "fact"
 	m["f"] = f.Head
 	m["a"] = f.Args
+	marshalPos(m, f.Pos)
 	return json.Marshal(m)
 }
 
@@ -125,7 +128,7 @@ func (f *Fact) UnmarshalJSON(b []byte) error {
 		f.Args = append(f.Args, t)
 	}
 
-	return nil
+	return unmarshalPos(rm, &f.Pos)
 }
 
 func (f *Fact) Signature() *Signature {
@@ -140,7 +143,8 @@ func prettyPrintList(a []Term) string {
 	left := a[0]
 	right := a[1]
 
-	if right.GetType() == T_Fact && right.(*Fact).Head == "|" {
+	// only a cell or the empty list continues the list, any other `|` is written like a tail
+	if r, ok := right.(*Fact); ok && r.Head == "|" && (len(r.Args) == 0 || len(r.Args) == 2) {
 		rightStr := prettyPrintList(right.(*Fact).Args)
 		if rightStr == "" {
 			return left.String()
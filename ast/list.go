@@ -0,0 +1,52 @@
+package ast
+
+/**
+ * Lists are represented as nested `|/2` facts terminated by the empty list `|/0`.
+ * These helpers build and take apart that encoding without going through the reader.
+ */
+
+// CreateList builds a proper list from the given items.
+func CreateList(items ...Term) *Fact {
+	return CreatePartialList(items, &Fact{Head: "|", Args: []Term{}}).(*Fact)
+}
+
+// CreatePartialList builds a list of the given items ending in tail (i.e. `[a,b|T]`).
+// If there are no items the tail itself is returned.
+func CreatePartialList(items []Term, tail Term) Term {
+	ret := tail
+	for i := len(items) - 1; i >= 0; i-- {
+		ret = &Fact{Head: "|", Args: []Term{items[i], ret}}
+	}
+	return ret
+}
+
+// IsEmptyList returns true if the given term is `[]`.
+func IsEmptyList(t Term) bool {
+	if t.GetType() != T_Fact {
+		return false
+	}
+	f := t.(*Fact)
+	return f.Head == "|" && len(f.Args) == 0
+}
+
+// ListToSlice walks a list and returns its items along with whatever terminates it.
+// For a proper list the returned tail is `[]`, for a partial list it will usually be a variable.
+// The term should be grounded before calling this since variables are not dereferenced.
+func ListToSlice(t Term) ([]Term, Term) {
+	items := []Term{}
+	for t.GetType() == T_Fact {
+		f := t.(*Fact)
+		if f.Head != "|" || len(f.Args) != 2 {
+			break
+		}
+		items = append(items, f.Args[0])
+		t = f.Args[1]
+	}
+	return items, t
+}
+
+// IsProperList returns true if the term is a list ending in `[]`.
+func IsProperList(t Term) bool {
+	_, tail := ListToSlice(t)
+	return IsEmptyList(tail)
+}
@@ -11,29 +11,39 @@ type Factor struct {
 	Expr *MathExpr
 }
 
-func (f *Factor) Anonymize(bindings *map[string]string, start int) (*Factor, int) {
+func (f *Factor) Anonymize(start int, prefix string, existing *map[string]string) (*Factor, int) {
 	if f.Num != nil {
 		return f, 0
 	}
 	if f.Var != nil {
 		varName := f.Var.String()
-		bound := (*bindings)[varName]
+		bound := (*existing)[varName]
 		if bound != "" {
-			return &Factor{CreateVariable(bound), nil, nil}, 0
+			return &Factor{&Variable{string: bound, Pos: f.Var.Pos}, nil, nil}, 0
 		} else {
-			newVar := fmt.Sprintf("_h%d", start)
-			(*bindings)[varName] = newVar
-			return &Factor{CreateVariable(newVar), nil, nil}, 1
+			newVar := fmt.Sprintf("%s%d", prefix, start)
+			(*existing)[varName] = newVar
+			return &Factor{&Variable{string: newVar, Pos: f.Var.Pos}, nil, nil}, 1
 		}
 	}
 	if f.Expr != nil {
-		newExpr, used := f.Expr.Anonymize(bindings, start)
+		newExpr, used := f.Expr.Anonymize(start, prefix, existing)
 		return &Factor{Expr: newExpr}, used
 	}
 	// i dont think we should normally get down here
 	return f, 0
 }
 
+func (f *Factor) ExtractVariables() []*Variable {
+	if f.Var != nil {
+		return []*Variable{f.Var}
+	}
+	if f.Expr != nil {
+		return f.Expr.ExtractVariables()
+	}
+	return []*Variable{}
+}
+
 func (f *Factor) String() string {
 	if f.Var != nil {
 		return f.Var.String()
@@ -63,9 +73,9 @@ func (f *Factor) MarshalJSON() ([]byte, error) {
 
 func (f *Factor) UnmarshalJSON(b []byte) error {
 	rm := make(map[string]json.RawMessage)
-	var v *Variable
-	var n *NumericLiteral
-	var e *MathExpr
+	v := &Variable{}
+	n := &NumericLiteral{}
+	e := &MathExpr{}
 	var val json.RawMessage
 	var ok bool
 
@@ -94,9 +104,17 @@ func (f *Factor) UnmarshalJSON(b []byte) error {
 		f.Expr = e
 	}
 
+	if f.empty() {
+		return fmt.Errorf("a factor needs a variable, number or expression: %s", b)
+	}
 	return nil
 }
 
+// empty is true for a factor without a variable, number or expression, i.e. one read from `null`
+func (f *Factor) empty() bool {
+	return f.Var == nil && f.Num == nil && f.Expr == nil
+}
+
 type MultOperator int
 
 const (
@@ -111,12 +129,23 @@ type Mult struct {
 	RHS      *Factor
 }
 
-func (m *Mult) Anonymize(bindings *map[string]string, start int) (*Mult, int) {
-	lhs, lused := m.LHS.Anonymize(bindings, start)
-	rhs, rused := m.RHS.Anonymize(bindings, start+lused)
+func (m *Mult) Anonymize(start int, prefix string, existing *map[string]string) (*Mult, int) {
+	lhs, lused := m.LHS.Anonymize(start, prefix, existing)
+	if m.RHS == nil {
+		return &Mult{lhs, m.Operator, nil}, lused
+	}
+	rhs, rused := m.RHS.Anonymize(start+lused, prefix, existing)
 	return &Mult{lhs, m.Operator, rhs}, (rused + lused)
 }
 
+func (m *Mult) ExtractVariables() []*Variable {
+	ret := m.LHS.ExtractVariables()
+	if m.RHS != nil {
+		ret = append(ret, m.RHS.ExtractVariables()...)
+	}
+	return ret
+}
+
 func (m *Mult) String() string {
 	switch m.Operator {
 	case OP_MultNoOp:
@@ -159,19 +188,27 @@ func (mu *Mult) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	err = json.Unmarshal(rm["r"], &rhs)
-	if err != nil {
-		return err
+	// operators without a right hand side dont have an r
+	if r, ok := rm["r"]; ok {
+		err = json.Unmarshal(r, &rhs)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = json.Unmarshal(rm["o"], &op)
 	if err != nil {
 		return err
 	}
+	if op < OP_Mult || op > OP_MultNoOp || lhs.empty() || (op != OP_MultNoOp && rhs.empty()) {
+		return fmt.Errorf("malformed product: %s", b)
+	}
 
 	// put it all back together
 	mu.LHS = &lhs
-	mu.RHS = &rhs
+	if mu.RHS = nil; op != OP_MultNoOp {
+		mu.RHS = &rhs
+	}
 	mu.Operator = op
 
 	return nil
@@ -195,6 +232,14 @@ func (m *MathExpr) GetType() TermType {
 	return T_MathExpr
 }
 
+func (m *MathExpr) ExtractVariables() []*Variable {
+	ret := m.LHS.ExtractVariables()
+	if m.RHS != nil {
+		ret = append(ret, m.RHS.ExtractVariables()...)
+	}
+	return ret
+}
+
 func (m *MathExpr) String() string {
 	switch m.Operator {
 	case OP_MathExprNoOp:
@@ -208,10 +253,12 @@ func (m *MathExpr) String() string {
 	}
 }
 
-func (m *MathExpr) Anonymize(bindings *map[string]string, start int) (*MathExpr, int) {
-	lhs, used := m.LHS.Anonymize(bindings, start)
-	start = start + used
-	rhs, rused := m.RHS.Anonymize(bindings, start+used)
+func (m *MathExpr) Anonymize(start int, prefix string, existing *map[string]string) (*MathExpr, int) {
+	lhs, used := m.LHS.Anonymize(start, prefix, existing)
+	if m.RHS == nil {
+		return &MathExpr{lhs, m.Operator, nil}, used
+	}
+	rhs, rused := m.RHS.Anonymize(start+used, prefix, existing)
 
 	return &MathExpr{lhs, m.Operator, rhs}, (used + rused)
 }
@@ -241,19 +288,27 @@ func (me *MathExpr) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	err = json.Unmarshal(rm["r"], &rhs)
-	if err != nil {
-		return err
+	// operators without a right hand side dont have an r
+	if r, ok := rm["r"]; ok {
+		err = json.Unmarshal(r, &rhs)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = json.Unmarshal(rm["o"], &op)
 	if err != nil {
 		return err
 	}
+	if op < OP_Add || op > OP_MathExprNoOp || lhs.LHS == nil || (op != OP_MathExprNoOp && rhs.LHS == nil) {
+		return fmt.Errorf("malformed expression: %s", b)
+	}
 
 	// put it all back together
 	me.LHS = &lhs
-	me.RHS = &rhs
+	if me.RHS = nil; op != OP_MathExprNoOp {
+		me.RHS = &rhs
+	}
 	me.Operator = op
 
 	return nil
@@ -262,22 +317,26 @@ func (me *MathExpr) UnmarshalJSON(b []byte) error {
 type MathAssignment struct {
 	LHS *Variable
 	RHS *MathExpr
+	Pos Position
 }
 
 func (m *MathAssignment) GetType() TermType {
 	return T_MathAssignment
 }
 
+func (m *MathAssignment) ExtractVariables() []*Variable {
+	return append([]*Variable{m.LHS}, m.RHS.ExtractVariables()...)
+}
+
 func (m *MathAssignment) String() string {
 	return fmt.Sprintf("%s is %s", m.LHS.String(), m.RHS.String())
 }
 
-func (m *MathAssignment) Anonymize(start int) (*MathAssignment, int) {
-	bindings := make(map[string]string)
-	lhsVar := fmt.Sprintf("_h%d", start)
-	bindings[m.LHS.String()] = lhsVar
-	rhs, count := m.RHS.Anonymize(&bindings, start+1)
-	return &MathAssignment{CreateVariable(lhsVar), rhs}, (count + 1)
+func (m *MathAssignment) Anonymize(start int, prefix string, existing *map[string]string) (*MathAssignment, int) {
+	lhs := &Factor{Var: m.LHS}
+	alhs, used := lhs.Anonymize(start, prefix, existing)
+	rhs, rused := m.RHS.Anonymize(start+used, prefix, existing)
+	return &MathAssignment{LHS: alhs.Var, RHS: rhs, Pos: m.Pos}, (used + rused)
 }
 
 func (ma *MathAssignment) MarshalJSON() ([]byte, error) {
@@ -285,11 +344,11 @@ func (ma *MathAssignment) MarshalJSON() ([]byte, error) {
 	m["t"] = "ma"
 	m["v"] = ma.LHS
 	m["e"] = ma.RHS
+	marshalPos(m, ma.Pos)
 	return json.Marshal(m)
 }
 
 func (ma *MathAssignment) UnmarshalJSON(b []byte) error {
-	fmt.Println("UNMARSHAL MATHASSIGN")
 	rm := make(map[string]json.RawMessage)
 	var v Variable
 	var rhs MathExpr
@@ -307,10 +366,52 @@ func (ma *MathAssignment) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
+	if rhs.LHS == nil {
+		return fmt.Errorf("malformed assignment: %s", b)
+	}
 
 	// put it all back together
 	ma.LHS = &v
 	ma.RHS = &rhs
 
-	return nil
+	return unmarshalPos(rm, &ma.Pos)
+}
+
+// Term returns the assignment as the term `is(LHS, RHS)` it was read from
+func (m *MathAssignment) Term() *Fact {
+	f := CreateFact("is", m.LHS, m.RHS.Term())
+	f.Pos = m.Pos
+	return f
+}
+
+// Term returns the expression as a term made of the arithmetic operators
+func (m *MathExpr) Term() Term {
+	switch m.Operator {
+	case OP_Add:
+		return CreateFact("+", m.LHS.Term(), m.RHS.Term())
+	case OP_Subtract:
+		return CreateFact("-", m.LHS.Term(), m.RHS.Term())
+	}
+	return m.LHS.Term()
+}
+
+// Term returns the product as a term made of the arithmetic operators
+func (m *Mult) Term() Term {
+	switch m.Operator {
+	case OP_Mult:
+		return CreateFact("*", m.LHS.Term(), m.RHS.Term())
+	case OP_Divide:
+		return CreateFact("/", m.LHS.Term(), m.RHS.Term())
+	}
+	return m.LHS.Term()
+}
+
+// Term returns the variable, number or expression in the factor
+func (f *Factor) Term() Term {
+	if f.Var != nil {
+		return f.Var
+	} else if f.Num != nil {
+		return f.Num
+	}
+	return f.Expr.Term()
 }
@@ -0,0 +1,184 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kkoch986/gopl/token"
+)
+
+/**
+ * Position is the span of source a term was parsed from, for clauses it runs from their first token to the `.` which ends them.
+ * File is only known once the statements are given one with SetFile, the zero value means the term wasnt parsed.
+ */
+type Position struct {
+	File      string `json:"f,omitempty"`
+	Line      int    `json:"l"`
+	Column    int    `json:"c"`
+	EndLine   int    `json:"el"`
+	EndColumn int    `json:"ec"`
+}
+
+// IsValid reports whether the position was set by the parser
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+/**
+ * PositionOf returns where a term was parsed from, terms created while running a program dont have one.
+ * A query's position spans its goals.
+ */
+func PositionOf(t Term) Position {
+	switch v := t.(type) {
+	case *Fact:
+		return v.Pos
+	case *Rule:
+		return v.Pos
+	case *Query:
+		return v.Pos()
+	case *Variable:
+		return v.Pos
+	case *Atom:
+		return v.Pos
+	case *StringLiteral:
+		return v.Pos
+	case *NumericLiteral:
+		return v.Pos
+	case *MathAssignment:
+		return v.Pos
+	}
+	return Position{}
+}
+
+// through returns the span from the start of p to the end of q
+func (p Position) through(q Position) Position {
+	p.EndLine, p.EndColumn = q.EndLine, q.EndColumn
+	return p
+}
+
+// SetFile records the file the statements were parsed from in their positions and the positions of all of their terms
+func SetFile(statements []Statement, file string) {
+	for _, s := range statements {
+		setFile(s, file)
+	}
+}
+
+func setFile(t Term, file string) {
+	switch v := t.(type) {
+	case *Fact:
+		v.Pos.File = file
+		for _, arg := range v.Args {
+			setFile(arg, file)
+		}
+	case *Rule:
+		v.Pos.File = file
+		setFile(v.Head, file)
+		setFile(v.Body, file)
+	case *Query:
+		for _, goal := range *v {
+			setFile(goal, file)
+		}
+	case *Variable:
+		v.Pos.File = file
+	case *Atom:
+		v.Pos.File = file
+	case *StringLiteral:
+		v.Pos.File = file
+	case *NumericLiteral:
+		v.Pos.File = file
+	case *MathAssignment:
+		v.Pos.File = file
+		setFile(v.LHS, file)
+		setFile(v.RHS, file)
+	case *MathExpr:
+		setFile(v.LHS, file)
+		if v.RHS != nil {
+			setFile(v.RHS, file)
+		}
+	case *Mult:
+		setFile(v.LHS, file)
+		if v.RHS != nil {
+			setFile(v.RHS, file)
+		}
+	case *Factor:
+		if v.Var != nil {
+			setFile(v.Var, file)
+		} else if v.Num != nil {
+			setFile(v.Num, file)
+		} else if v.Expr != nil {
+			setFile(v.Expr, file)
+		}
+	}
+}
+
+// marshalPos adds the position to a term's raw JSON when it has one
+func marshalPos(m map[string]interface{}, p Position) {
+	if p.IsValid() {
+		m["p"] = p
+	}
+}
+
+// unmarshalPos reads the position back out of a term's raw JSON, leaving it unset if there isnt one
+func unmarshalPos(rm map[string]json.RawMessage, p *Position) error {
+	raw, ok := rm["p"]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(raw, p)
+}
+
+/**
+ * LineIndex finds the line and column of a rune in the reader's input without rescanning the input each time.
+ * Columns are counted the same way as the lexer does, with tabs counting for 4.
+ */
+type LineIndex struct {
+	input []rune
+	// starts holds the offset of the first rune of each line
+	starts []int
+}
+
+// NewLineIndex indexes the lines of the input
+func NewLineIndex(input []rune) *LineIndex {
+	l := &LineIndex{input: input, starts: []int{0}}
+	for i, r := range input {
+		if r == '\n' {
+			l.starts = append(l.starts, i+1)
+		}
+	}
+	return l
+}
+
+// LineColumn returns the line and column of the rune at offset
+func (l *LineIndex) LineColumn(offset int) (line, col int) {
+	line = sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset })
+	col = 1
+	for _, r := range l.input[l.starts[line-1]:offset] {
+		if r == '\t' {
+			col += 4
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// Span returns the position from the start of the first token up to the last rune of the last one
+func (l *LineIndex) Span(first *token.Token, last *token.Token) Position {
+	p := Position{}
+	p.Line, p.Column = l.LineColumn(first.Lext())
+	p.EndLine, p.EndColumn = l.LineColumn(last.Rext() - 1)
+	return p
+}
+
+// Token returns the position of a single token
+func (l *LineIndex) Token(t *token.Token) Position {
+	return l.Span(t, t)
+}
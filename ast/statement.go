@@ -23,7 +23,11 @@ const (
 )
 
 func (s TermType) String() string {
-	return []string{"Query", "Rule", "Fact", "Variable", "Atom", "String", "Number"}[s]
+	names := []string{"Query", "Rule", "Fact", "Variable", "Atom", "String", "Number", "MathExpr", "MathAssignment", "Mult", "Factor"}
+	if s < 0 || int(s) >= len(names) {
+		return fmt.Sprintf("TermType(%d)", int(s))
+	}
+	return names[s]
 }
 
 // Statement can be a Query, Rule or Fact.
@@ -51,6 +55,20 @@ func (q *Query) GetType() TermType {
 	return T_Query
 }
 
+// Pos returns the span of the query's goals, it isnt valid unless they were parsed
+func (q *Query) Pos() Position {
+	var first, last Position
+	for _, goal := range *q {
+		if pos := PositionOf(goal); pos.IsValid() {
+			if !first.IsValid() {
+				first = pos
+			}
+			last = pos
+		}
+	}
+	return first.through(last)
+}
+
 func (q *Query) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
 	m["t"] = "query"
@@ -78,7 +96,11 @@ func (q *Query) UnmarshalJSON(b []byte) error {
 		if err != nil {
 			return err
 		}
-		t := string(temp["t"])
+		var t string
+		err = json.Unmarshal(temp["t"], &t)
+		if err != nil {
+			return err
+		}
 		if t == "ma" {
 			ma := &MathAssignment{}
 			err = json.Unmarshal(v, ma)
@@ -101,8 +123,8 @@ func (q *Query) UnmarshalJSON(b []byte) error {
 func CreateRule(h *Fact, q ...Statement) *Rule {
 	query := Query(q)
 	return &Rule{
-		h,
-		&query,
+		Head: h,
+		Body: &query,
 	}
 }
 
@@ -125,6 +147,7 @@ func (q *Query) Tail() *Query {
 type Rule struct {
 	Head *Fact
 	Body *Query
+	Pos  Position
 }
 
 func (r *Rule) GetType() TermType {
@@ -156,13 +179,19 @@ func (r *Rule) Anonymize(start int, prefix string) (*Rule, map[string]string, in
 
 	// now start anonymizing the body
 	for _, f := range *r.Body {
-		/// TODO: handle this for mathassignments
-		af, u := f.(*Fact).Anonymize(start+used, prefix, &existing)
-		used = used + u
-		anonymousBody = append(anonymousBody, af)
+		switch s := f.(type) {
+		case *Fact:
+			af, u := s.Anonymize(start+used, prefix, &existing)
+			used = used + u
+			anonymousBody = append(anonymousBody, af)
+		case *MathAssignment:
+			am, u := s.Anonymize(start+used, prefix, &existing)
+			used = used + u
+			anonymousBody = append(anonymousBody, am)
+		}
 	}
 
-	return &Rule{anonymousHead, &anonymousBody}, existing, used
+	return &Rule{Head: anonymousHead, Body: &anonymousBody, Pos: r.Pos}, existing, used
 }
 
 func (q *Rule) MarshalJSON() ([]byte, error) {
@@ -170,6 +199,7 @@ func (q *Rule) MarshalJSON() ([]byte, error) {
 	m["t"] = "rule"
 	m["h"] = q.Head
 	m["b"] = q.Body
+	marshalPos(m, q.Pos)
 	return json.Marshal(m)
 }
 
@@ -190,6 +220,64 @@ func (r *Rule) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
+	if r.Head == nil || r.Body == nil {
+		return fmt.Errorf("a rule needs a head and a body: %s", b)
+	}
 
-	return nil
+	return unmarshalPos(rm, &r.Pos)
+}
+
+/**
+ * ClauseTerm returns a fact or rule as the term it would be written as, i.e. for passing it to term_expansion/2.
+ * Rules are `:-(Head, Body)` with the goals of the body joined by `,` and facts without arguments are atoms.
+ */
+func ClauseTerm(s Statement) Term {
+	switch c := s.(type) {
+	case *Fact:
+		return callableTerm(c)
+	case *Rule:
+		return CreateFact(":-", callableTerm(c.Head), GoalsTerm(*c.Body))
+	}
+	return s
+}
+
+// GoalsTerm joins the goals of a query with `,`, the empty query is `true`
+func GoalsTerm(q Query) Term {
+	if len(q) == 0 {
+		return CreateAtom("true")
+	}
+	t := GoalTerm(q[len(q)-1])
+	for i := len(q) - 2; i >= 0; i-- {
+		t = CreateFact(",", GoalTerm(q[i]), t)
+	}
+	return t
+}
+
+// Conjuncts splits a term joined by `,` into its parts, anything else is a single part
+func Conjuncts(t Term) []Term {
+	if f, ok := t.(*Fact); ok && f.Head == "," && len(f.Args) == 2 {
+		return append(Conjuncts(f.Args[0]), Conjuncts(f.Args[1])...)
+	}
+	return []Term{t}
+}
+
+// GoalTerm returns a single goal of a query as a term
+func GoalTerm(s Statement) Term {
+	switch g := s.(type) {
+	case *Fact:
+		return callableTerm(g)
+	case *MathAssignment:
+		return g.Term()
+	}
+	return s
+}
+
+// callableTerm returns a fact with no arguments as the atom it is written as
+func callableTerm(f *Fact) Term {
+	if len(f.Args) == 0 {
+		a := CreateAtom(f.Head)
+		a.Pos = f.Pos
+		return a
+	}
+	return f
 }
@@ -3,6 +3,8 @@ package ast
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync/atomic"
 )
 
 /**
@@ -84,6 +86,7 @@ func UnmarshalJSONTerm(b []byte) (Term, error) {
  */
 type Variable struct {
 	string
+	Pos Position
 }
 
 func (v *Variable) GetType() TermType {
@@ -96,6 +99,7 @@ func (v *Variable) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
 	m["t"] = "var"
 	m["v"] = v.string
+	marshalPos(m, v.Pos)
 	return json.Marshal(m)
 }
 
@@ -111,11 +115,28 @@ func (v *Variable) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	v.string = s
-	return nil
+	return unmarshalPos(rm, &v.Pos)
 }
 
 func CreateVariable(v string) *Variable {
-	return &Variable{v}
+	return &Variable{string: v}
+}
+
+// anonymousVariables counts the variables created by CreateAnonymousVariable
+var anonymousVariables int64
+
+/**
+ * CreateAnonymousVariable creates the variable for a `_` in the source, named `_#<n>`.
+ * Each `_` is a different variable, so they are numbered across everything that is read.
+ * The lexer never reads a `#` as part of a variable name, so they cant clash with the variables a program uses.
+ */
+func CreateAnonymousVariable() *Variable {
+	return &Variable{string: fmt.Sprintf("_#%d", atomic.AddInt64(&anonymousVariables, 1))}
+}
+
+// IsAnonymous reports whether the variable was written as `_`, see CreateAnonymousVariable
+func IsAnonymous(v *Variable) bool {
+	return v.string == "_" || strings.HasPrefix(v.string, "_#")
 }
 
 /**
@@ -123,6 +144,7 @@ func CreateVariable(v string) *Variable {
  */
 type Atom struct {
 	string
+	Pos Position
 }
 
 func (v *Atom) GetType() TermType {
@@ -135,6 +157,7 @@ func (v *Atom) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
 	m["t"] = "atom"
 	m["v"] = v.string
+	marshalPos(m, v.Pos)
 	return json.Marshal(m)
 }
 func (v *Atom) UnmarshalJSON(b []byte) error {
@@ -149,11 +172,11 @@ func (v *Atom) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	v.string = s
-	return nil
+	return unmarshalPos(rm, &v.Pos)
 }
 
 func CreateAtom(v string) *Atom {
-	return &Atom{v}
+	return &Atom{string: v}
 }
 
 /**
@@ -161,6 +184,7 @@ func CreateAtom(v string) *Atom {
  */
 type StringLiteral struct {
 	string
+	Pos Position
 }
 
 func (v *StringLiteral) GetType() TermType {
@@ -173,6 +197,7 @@ func (v *StringLiteral) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
 	m["t"] = "str"
 	m["v"] = v.string
+	marshalPos(m, v.Pos)
 	return json.Marshal(m)
 }
 func (v *StringLiteral) UnmarshalJSON(b []byte) error {
@@ -187,11 +212,11 @@ func (v *StringLiteral) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	v.string = s
-	return nil
+	return unmarshalPos(rm, &v.Pos)
 }
 
 func CreateStringLiteral(v string) *StringLiteral {
-	return &StringLiteral{v}
+	return &StringLiteral{string: v}
 }
 
 /**
@@ -199,6 +224,7 @@ func CreateStringLiteral(v string) *StringLiteral {
  */
 type NumericLiteral struct {
 	float64
+	Pos Position
 }
 
 func (v *NumericLiteral) GetType() TermType {
@@ -217,6 +243,7 @@ func (v *NumericLiteral) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
 	m["t"] = "num"
 	m["v"] = v.float64
+	marshalPos(m, v.Pos)
 	return json.Marshal(m)
 }
 func (v *NumericLiteral) UnmarshalJSON(b []byte) error {
@@ -231,9 +258,9 @@ func (v *NumericLiteral) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	v.float64 = s
-	return nil
+	return unmarshalPos(rm, &v.Pos)
 }
 
 func CreateNumericLiteral(v float64) *NumericLiteral {
-	return &NumericLiteral{v}
+	return &NumericLiteral{float64: v}
 }
@@ -0,0 +1,235 @@
+package ast
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	plainAtomPattern  = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+	symbolAtomPattern = regexp.MustCompile(`^[+\-*/\\^<>=~:.?@#&$]+$`)
+)
+
+// WriteOptions controls how WriteTerm formats a term
+type WriteOptions struct {
+	// Quoted will add quotes to atoms and strings where they are needed to read the term back in
+	Quoted bool
+	// Ops writes compound terms whose functor is an operator in operator form, without it every term is written
+	// in canonical form like `-(a,b)`, the same as the ignore_ops option
+	Ops Operators
+}
+
+// Operators is the operator table terms are written with, the syntax package's Ops implements it
+type Operators interface {
+	// Operator returns the priority and type of the operator with the name and arity (1 for prefix and postfix, 2 for infix)
+	Operator(name string, arity int) (priority int, typ string, ok bool)
+}
+
+/**
+ * WriteTerm formats a term as text the way write/1 and friends would.
+ * Unlike String(), numbers are written without trailing zeros and lists are written using `[...]`.
+ * The term should be grounded first since variables are written as they are.
+ */
+func WriteTerm(t Term, opts WriteOptions) string {
+	var sb strings.Builder
+	writeTerm(&sb, t, opts, 1200)
+	return sb.String()
+}
+
+// writeTerm writes a term which is an argument with a priority of at most max, terms above it are put in brackets
+func writeTerm(sb *strings.Builder, t Term, opts WriteOptions, max int) {
+	switch t.GetType() {
+	case T_Number:
+		sb.WriteString(FormatNumber(t.(*NumericLiteral).Value()))
+	case T_Atom:
+		sb.WriteString(FormatAtom(t.String(), opts.Quoted))
+	case T_String:
+		if opts.Quoted {
+			sb.WriteString(quote(t.String(), '"'))
+		} else {
+			sb.WriteString(t.String())
+		}
+	case T_Fact:
+		f := t.(*Fact)
+		if f.Head == "|" && (len(f.Args) == 0 || len(f.Args) == 2) {
+			writeList(sb, f, opts)
+			return
+		}
+		if f.Head == "{}" && len(f.Args) == 1 {
+			sb.WriteString("{")
+			writeTerm(sb, f.Args[0], opts, 1200)
+			sb.WriteString("}")
+			return
+		}
+		if opts.Ops != nil && writeOperator(sb, f, opts, max) {
+			return
+		}
+		sb.WriteString(FormatAtom(f.Head, opts.Quoted))
+		sb.WriteString("(")
+		for i, a := range f.Args {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeTerm(sb, a, opts, 999)
+		}
+		sb.WriteString(")")
+	default:
+		sb.WriteString(t.String())
+	}
+}
+
+/**
+ * writeOperator writes a compound term in operator form if its functor is an operator, returning false if it isnt.
+ * Layout is only added around operators which are names, like `X is Y`, or where the text on either side would
+ * otherwise be read as a single token, like `a- -1`.
+ */
+func writeOperator(sb *strings.Builder, f *Fact, opts WriteOptions, max int) bool {
+	priority, typ, ok := opts.Ops.Operator(f.Head, len(f.Args))
+	if !ok {
+		return false
+	}
+	if len(f.Args) == 1 && typ[0] == 'f' && (f.Head == "-" || f.Head == "+") && f.Args[0].GetType() == T_Number {
+		// - 1 would be read back as the number -1
+		return false
+	}
+	if priority > max {
+		sb.WriteString("(")
+		defer sb.WriteString(")")
+	}
+	left, right := priority-1, priority-1
+	if typ[0] == 'y' {
+		left = priority
+	}
+	if typ[len(typ)-1] == 'y' {
+		right = priority
+	}
+
+	name := FormatAtom(f.Head, opts.Quoted)
+	switch {
+	case len(f.Args) == 2:
+		writeOperand(sb, f.Args[0], opts, left)
+		switch {
+		case f.Head == ",":
+			name = ","
+		case plainAtomPattern.MatchString(f.Head):
+			name = " " + name + " "
+		}
+		writeJoined(sb, name)
+		writeJoined(sb, operandText(f.Args[1], opts, right))
+	case typ[0] == 'f':
+		sb.WriteString(name)
+		arg := operandText(f.Args[0], opts, right)
+		if strings.HasPrefix(arg, "(") || ((f.Head == "-" || f.Head == "+") && arg != "" && unicode.IsDigit(rune(arg[0]))) {
+			// without the space the brackets would be read as the arguments of a compound term
+			// and a sign followed by a digit as a negative number
+			sb.WriteString(" ")
+		}
+		writeJoined(sb, arg)
+	default:
+		writeOperand(sb, f.Args[0], opts, left)
+		writeJoined(sb, name)
+	}
+	return true
+}
+
+// writeOperand writes the argument of an operator, an atom which is an operator itself is put in brackets
+func writeOperand(sb *strings.Builder, t Term, opts WriteOptions, max int) {
+	if t.GetType() == T_Atom && isOperator(opts.Ops, t.String()) {
+		sb.WriteString("(")
+		writeTerm(sb, t, opts, 1200)
+		sb.WriteString(")")
+		return
+	}
+	writeTerm(sb, t, opts, max)
+}
+
+func operandText(t Term, opts WriteOptions, max int) string {
+	var sb strings.Builder
+	writeOperand(&sb, t, opts, max)
+	return sb.String()
+}
+
+func isOperator(ops Operators, name string) bool {
+	_, _, prefix := ops.Operator(name, 1)
+	_, _, infix := ops.Operator(name, 2)
+	return prefix || infix
+}
+
+// writeJoined writes the text after what has been written so far, with a space between them if they would run together
+func writeJoined(sb *strings.Builder, text string) {
+	if sb.Len() > 0 && text != "" {
+		last, _ := utf8.DecodeLastRuneInString(sb.String())
+		first, _ := utf8.DecodeRuneInString(text)
+		if joins(last, first) {
+			sb.WriteString(" ")
+		}
+	}
+	sb.WriteString(text)
+}
+
+// joins reports whether two characters written next to each other would be read as part of the same token
+func joins(a rune, b rune) bool {
+	word := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	symbol := func(r rune) bool { return strings.ContainsRune("+-*/\\^<>=~:.?@#&$", r) }
+	return (word(a) && word(b)) || (symbol(a) && symbol(b)) || (a == ',' && b == ',')
+}
+
+func writeList(sb *strings.Builder, f *Fact, opts WriteOptions) {
+	items, tail := ListToSlice(f)
+	sb.WriteString("[")
+	for i, item := range items {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		writeTerm(sb, item, opts, 999)
+	}
+	if !IsEmptyList(tail) {
+		sb.WriteString("|")
+		writeTerm(sb, tail, opts, 999)
+	}
+	sb.WriteString("]")
+}
+
+// FormatNumber writes a number the way it would be written in a source file, integers dont get a decimal point
+func FormatNumber(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// FormatAtom writes the name of an atom, adding single quotes if they are needed and requested
+func FormatAtom(name string, quoted bool) string {
+	if !quoted || plainAtomPattern.MatchString(name) || symbolAtomPattern.MatchString(name) || name == "[]" || name == "{}" || name == "!" || name == ";" {
+		return name
+	}
+	return quote(name, '\'')
+}
+
+func quote(s string, q rune) string {
+	var sb strings.Builder
+	sb.WriteRune(q)
+	for _, r := range s {
+		switch r {
+		case q:
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case '\\':
+			sb.WriteString("\\\\")
+		case '\n':
+			sb.WriteString("\\n")
+		case '\r':
+			sb.WriteString("\\r")
+		case '\t':
+			sb.WriteString("\\t")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteRune(q)
+	return sb.String()
+}
@@ -0,0 +1,274 @@
+// Package datalog evaluates the rules in an indexer bottom-up.
+//
+// Every fact and rule in the User module has to be Datalog: arguments are atoms, numbers, strings or variables,
+// facts are ground, rule bodies are calls to other predicates in the program or their negation (`\+ p(X)` or `not(p(X))`),
+// and every variable in the head or in a negated goal (besides `_`) also appears in a goal which isnt negated.
+// Every goal has to call a predicate with at least one fact or rule in the program.
+// A program that uses anything else (arithmetic, builtins, library predicates, compound terms, cut...) is refused.
+//
+// The predicates are split into strongly connected components which are evaluated in order, so a negated goal
+// is only ever checked against a relation which is already complete. A program where a predicate depends on
+// the negation of itself isnt stratified and is refused too.
+// Each component is computed semi-naively: after the first round a rule is only fired again with one of its goals
+// restricted to the facts that were new in the previous round.
+package datalog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+// Error is returned when a program cant be evaluated as Datalog, Pos is the clause that caused it
+type Error struct {
+	Pos ast.Position
+	Msg string
+}
+
+func (e *Error) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Relation is a predicate computed from its rules along with all of its facts
+type Relation struct {
+	Signature *ast.Signature
+	Facts     []*ast.Fact
+}
+
+/**
+ * Evaluate computes every predicate defined by rules in the User module of the indexer and replaces the predicate's
+ * clauses with the facts, see indexer.Materializer. The relations are returned in the order they were computed.
+ * builtin reports whether the resolver provides a predicate (i.e. resolver.R.Registered), they cant be called
+ * by the program. The rules are kept, so evaluating the indexer again after adding facts brings them up to date.
+ */
+func Evaluate(i indexer.Indexer, builtin func(name string, arity int) bool) ([]Relation, error) {
+	m, ok := i.(indexer.Materializer)
+	if !ok {
+		return nil, errors.New("datalog: the indexer cant materialize relations")
+	}
+	p, err := load(i, m.Clauses(indexer.User), builtin)
+	if err != nil {
+		return nil, err
+	}
+	components, err := p.stratify()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &evaluation{relations: make(map[string]*relation)}
+	for key, facts := range p.facts {
+		r := e.relation(key)
+		for _, f := range facts {
+			r.add(f.Args)
+		}
+	}
+
+	relations := []Relation{}
+	for _, component := range components {
+		rules := []*rule{}
+		for _, key := range component {
+			rules = append(rules, p.rules[key]...)
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		e.fixpoint(component, rules)
+		for _, key := range component {
+			if len(p.rules[key]) == 0 {
+				continue
+			}
+			sig := p.signatures[key]
+			facts := e.relation(key).facts(sig.Functor)
+			m.Materialize(sig, facts)
+			relations = append(relations, Relation{Signature: sig, Facts: facts})
+		}
+	}
+	return relations, nil
+}
+
+// literal is a goal in the body of a rule
+type literal struct {
+	goal    *ast.Fact
+	key     string
+	negated bool
+}
+
+// rule is a rule with the goals which arent negated first, so the negated ones are checked once their variables are bound
+type rule struct {
+	head *ast.Fact
+	body []literal
+	pos  ast.Position
+}
+
+// program holds the facts and rules of each predicate by signature
+type program struct {
+	facts      map[string][]*ast.Fact
+	rules      map[string][]*rule
+	signatures map[string]*ast.Signature
+	// order holds the predicates in the order they first appear
+	order []string
+	names indexer.VariableNamer
+}
+
+// load checks that the clauses are Datalog and sorts them by predicate
+func load(i indexer.Indexer, clauses []ast.Statement, builtin func(string, int) bool) (*program, error) {
+	p := &program{
+		facts:      make(map[string][]*ast.Fact),
+		rules:      make(map[string][]*rule),
+		signatures: make(map[string]*ast.Signature),
+	}
+	p.names, _ = i.(indexer.VariableNamer)
+
+	for _, c := range clauses {
+		switch s := c.(type) {
+		case *ast.Fact:
+			if err := p.arguments(s, s.Pos); err != nil {
+				return nil, err
+			}
+			if vars := s.ExtractVariables(); len(vars) > 0 {
+				return nil, &Error{s.Pos, fmt.Sprintf("the fact %s has the variable %s, facts have to be ground", s.Signature(), p.name(vars[0]))}
+			}
+			key := p.predicate(s)
+			p.facts[key] = append(p.facts[key], s)
+		case *ast.Rule:
+			r, err := p.rule(s)
+			if err != nil {
+				return nil, err
+			}
+			key := p.predicate(s.Head)
+			p.rules[key] = append(p.rules[key], r)
+		}
+	}
+
+	// every goal has to call a predicate with facts or rules in the program
+	for _, key := range p.order {
+		for _, r := range p.rules[key] {
+			for _, l := range r.body {
+				if len(p.facts[l.key]) > 0 || len(p.rules[l.key]) > 0 {
+					continue
+				}
+				sig := p.signatures[l.key]
+				if builtin != nil && builtin(sig.Functor, sig.Arity) {
+					return nil, &Error{r.pos, fmt.Sprintf("%s is a builtin, which cant be used in Datalog", sig)}
+				}
+				if modules, ok := i.(indexer.Modules); ok {
+					if module, statements := modules.ModuleStatements(indexer.User, sig); module != indexer.User && len(statements) > 0 {
+						return nil, &Error{r.pos, fmt.Sprintf("%s is defined in the %s module, which cant be used in Datalog", sig, module)}
+					}
+				}
+				return nil, &Error{r.pos, fmt.Sprintf("%s isnt defined by any fact or rule in the program", sig)}
+			}
+		}
+	}
+	return p, nil
+}
+
+// controlConstructs are goals which have a meaning of their own in Prolog, but not in Datalog
+var controlConstructs = map[string]bool{"!/0": true, ";/2": true, "->/2": true, "*->/2": true, ",/2": true}
+
+// isNegation reports whether the goal is `\+ Goal` or `not(Goal)`
+func isNegation(goal *ast.Fact) bool {
+	return (goal.Head == "\\+" || goal.Head == "not") && len(goal.Args) == 1
+}
+
+// rule checks that a rule is Datalog and puts its body in the order it is evaluated
+func (p *program) rule(s *ast.Rule) (*rule, error) {
+	if err := p.arguments(s.Head, s.Pos); err != nil {
+		return nil, err
+	}
+
+	r := &rule{head: s.Head, pos: s.Pos}
+	negated := []literal{}
+	for _, g := range *s.Body {
+		goal, ok := g.(*ast.Fact)
+		if !ok {
+			return nil, &Error{s.Pos, fmt.Sprintf("%s uses arithmetic, which isnt supported in Datalog", s.Signature())}
+		}
+		l := literal{goal: goal}
+		if isNegation(goal) {
+			inner, ok := goal.Args[0].(*ast.Fact)
+			if !ok {
+				if goal.Args[0].GetType() != ast.T_Atom {
+					return nil, &Error{s.Pos, fmt.Sprintf("%s expects a goal, found %s", goal.Signature(), goal.Args[0])}
+				}
+				inner = ast.CreateFact(goal.Args[0].String())
+			}
+			l = literal{goal: inner, negated: true}
+		}
+		sig := l.goal.Signature()
+		if controlConstructs[sig.String()] || isNegation(l.goal) {
+			return nil, &Error{s.Pos, fmt.Sprintf("%s isnt supported in Datalog", sig)}
+		}
+		if err := p.arguments(l.goal, s.Pos); err != nil {
+			return nil, err
+		}
+		l.key = p.predicate(l.goal)
+		if l.negated {
+			negated = append(negated, l)
+		} else {
+			r.body = append(r.body, l)
+		}
+	}
+
+	// range restriction, the variables in the head and in negated goals have to be bound by the other goals
+	bound := map[string]bool{}
+	for _, l := range r.body {
+		for _, v := range l.goal.ExtractVariables() {
+			bound[v.String()] = true
+		}
+	}
+	for _, v := range s.Head.ExtractVariables() {
+		if !bound[v.String()] {
+			return nil, &Error{s.Pos, fmt.Sprintf("the variable %s in the head of %s isnt bound by a goal in its body", p.name(v), s.Signature())}
+		}
+	}
+	for _, l := range negated {
+		for _, v := range l.goal.ExtractVariables() {
+			if !bound[v.String()] && p.name(v) != "_" {
+				return nil, &Error{s.Pos, fmt.Sprintf("the variable %s in \\+ %s isnt bound by a goal which isnt negated", p.name(v), l.goal.Signature())}
+			}
+		}
+	}
+	r.body = append(r.body, negated...)
+	return r, nil
+}
+
+// arguments checks that every argument is an atom, number, string or variable
+func (p *program) arguments(f *ast.Fact, pos ast.Position) error {
+	for _, a := range f.Args {
+		switch a.GetType() {
+		case ast.T_Atom, ast.T_Number, ast.T_String, ast.T_Variable:
+		default:
+			return &Error{pos, fmt.Sprintf("%s has the compound argument %s, Datalog only allows atoms, numbers, strings and variables", f.Signature(), ast.WriteTerm(a, ast.WriteOptions{Quoted: true}))}
+		}
+	}
+	return nil
+}
+
+// predicate returns the key of the fact's predicate, remembering its signature
+func (p *program) predicate(f *ast.Fact) string {
+	sig := f.Signature()
+	key := sig.String()
+	if _, ok := p.signatures[key]; !ok {
+		p.signatures[key] = sig
+		p.order = append(p.order, key)
+	}
+	return key
+}
+
+// name returns the name the variable had in the source, `_` if it was anonymous or the name isnt known
+func (p *program) name(v *ast.Variable) string {
+	if p.names == nil {
+		return "_"
+	}
+	name, ok := p.names.VariableName(v.String())
+	if !ok || ast.IsAnonymous(ast.CreateVariable(name)) {
+		return "_"
+	}
+	return name
+}
@@ -0,0 +1,161 @@
+package datalog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/datalog"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func index(t *testing.T, src string) *indexer.Default {
+	statements, err := syntax.Parse([]rune(src), "program.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := indexer.NewDefault()
+	for _, s := range statements {
+		i.IndexStatement(s)
+	}
+	return i
+}
+
+// facts writes the clauses the indexer has for a predicate, sorted so they can be compared
+func facts(i indexer.Indexer, name string, arity int) string {
+	terms := []ast.Term{}
+	for _, s := range i.StatementsForSignature(&ast.Signature{Functor: name, Arity: arity}) {
+		terms = append(terms, s.(*ast.Fact))
+	}
+	return ast.WriteTerm(ast.CreateList(sortTerms(terms)...), ast.WriteOptions{Quoted: true})
+}
+
+func sortTerms(terms []ast.Term) []ast.Term {
+	for i := range terms {
+		for j := i + 1; j < len(terms); j++ {
+			if ast.CompareTerms(terms[j], terms[i]) < 0 {
+				terms[i], terms[j] = terms[j], terms[i]
+			}
+		}
+	}
+	return terms
+}
+
+func TestEvaluate(t *testing.T) {
+	i := index(t, `
+edge(a, b).
+edge(b, c).
+edge(c, a).
+edge(c, d).
+node(a). node(b). node(c). node(d). node(e).
+path(X, Y) :- edge(X, Y).
+path(X, Y) :- path(X, Z), edge(Z, Y).
+even(X, Y) :- edge(X, Z), odd(Z, Y).
+odd(X, Y) :- edge(X, Y).
+odd(X, Y) :- edge(X, Z), even(Z, Y).
+unreachable(Y) :- node(Y), \+ path(a, Y).
+sink(X) :- node(X), \+ edge(X, _).
+isolated(X) :- node(X), not(edge(X, _)), not(edge(_, X)).
+loop(X) :- path(X, X).
+start.
+started(X) :- start, node(X), \+ sink(X).
+`)
+	relations, err := datalog.Evaluate(i, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relations) != 8 {
+		t.Errorf("expected 8 relations, got %d", len(relations))
+	}
+
+	tests := []struct {
+		name     string
+		arity    int
+		expected string
+	}{
+		{"path", 2, "[path(a,a),path(a,b),path(a,c),path(a,d),path(b,a),path(b,b),path(b,c),path(b,d),path(c,a),path(c,b),path(c,c),path(c,d)]"},
+		{"even", 2, "[even(a,a),even(a,b),even(a,c),even(a,d),even(b,a),even(b,b),even(b,c),even(b,d),even(c,a),even(c,b),even(c,c),even(c,d)]"},
+		{"unreachable", 1, "[unreachable(e)]"},
+		{"sink", 1, "[sink(d),sink(e)]"},
+		{"isolated", 1, "[isolated(e)]"},
+		{"loop", 1, "[loop(a),loop(b),loop(c)]"},
+		{"started", 1, "[started(a),started(b),started(c)]"},
+	}
+	for _, tt := range tests {
+		if got := facts(i, tt.name, tt.arity); got != tt.expected {
+			t.Errorf("%s/%d: expected %s, got %s", tt.name, tt.arity, tt.expected, got)
+		}
+	}
+
+	// the rules are kept, so new facts are picked up the next time
+	i.IndexStatement(ast.CreateFact("edge", ast.CreateAtom("d"), ast.CreateAtom("e")))
+	if _, err := datalog.Evaluate(i, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := facts(i, "unreachable", 1); got != "[]" {
+		t.Errorf("unreachable/1: expected [], got %s", got)
+	}
+}
+
+func TestQueryEvaluated(t *testing.T) {
+	i := index(t, `
+edge(a, b).
+edge(b, a).
+path(X, Y) :- path(X, Z), edge(Z, Y).
+path(X, Y) :- edge(X, Y).
+`)
+	if _, err := datalog.Evaluate(i, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// path/2 is left recursive, so the resolver only finds every path because it looks up the derived facts
+	out := make(chan *resolver.Bindings)
+	q := &ast.Query{ast.CreateFact("path", ast.CreateVariable("X"), ast.CreateVariable("Y"))}
+	go resolver.New(i).ResolveStatementList(context.Background(), []ast.Statement{q}, resolver.EmptyBindings(), out)
+	paths := 0
+	for b := range out {
+		if b.IsException() {
+			t.Fatalf("unexpected exception %s", b.Exception)
+		}
+		paths++
+	}
+	if paths != 4 {
+		t.Errorf("expected 4 paths, got %d", paths)
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	builtin := func(name string, arity int) bool {
+		return name == "writeln" && arity == 1
+	}
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"p(X) :- q(X), Y is X + 1.\nq(1).", "program.pl:1:1: p/1 uses arithmetic"},
+		{"p(X) :- q(X), writeln(X).\nq(a).", "writeln/1 is a builtin"},
+		{"p([a]).", "p/1 has the compound argument [a]"},
+		{"p(X).", "the fact p/1 has the variable X"},
+		{"p(X, Y) :- q(X).\nq(a).", "the variable Y in the head of p/2 isnt bound"},
+		{"p(X) :- q(X), \\+ r(Y).\nq(a).\nr(a).", "the variable Y in \\+ r/1 isnt bound"},
+		{"p(X) :- q(X) ; r(X).", ";/2 isnt supported"},
+		{"p(X) :- q(X), !.", "!/0 isnt supported"},
+		{"p(X) :- q(X), \\+ p(X).\nq(a).", "p/1 depends on its own negation"},
+		{"p(X) :- q(X), not(p(X)).\nq(a).", "p/1 depends on its own negation"},
+		{"p(X) :- q(X), not(r(Y)).\nq(a).\nr(a).", "the variable Y in \\+ r/1 isnt bound"},
+		{"p(X) :- q(X), not(3).\nq(a).", "not/1 expects a goal, found 3"},
+		{"p(X) :- q(X), r(X).\nq(a).", "r/1 isnt defined by any fact or rule in the program"},
+		{"p(X) :- q(X), X > 1.\nq(2).", ">/2 isnt defined by any fact or rule in the program"},
+		{"p(X, _) :- q(X).\nq(a).", "the variable _ in the head of p/2 isnt bound"},
+		{"p(X) :- q(X), \\+ r(X).\nr(X) :- q(X), \\+ p(X).\nq(a).", "isnt stratified"},
+	}
+	for _, tt := range tests {
+		_, err := datalog.Evaluate(index(t, tt.src), builtin)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.src, tt.expected, err)
+		}
+	}
+}
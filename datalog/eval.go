@@ -0,0 +1,285 @@
+package datalog
+
+import (
+	"fmt"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * stratify splits the predicates into strongly connected components, ordered so that every predicate a component
+ * depends on is in the same component or an earlier one. It is an error for a rule to negate a goal from its own component.
+ */
+func (p *program) stratify() ([][]string, error) {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	components := [][]string{}
+
+	var connect func(key string)
+	connect = func(key string) {
+		index[key] = len(index)
+		low[key] = index[key]
+		stack = append(stack, key)
+		onStack[key] = true
+		for _, r := range p.rules[key] {
+			for _, l := range r.body {
+				if _, ok := index[l.key]; !ok {
+					connect(l.key)
+					if low[l.key] < low[key] {
+						low[key] = low[l.key]
+					}
+				} else if onStack[l.key] && index[l.key] < low[key] {
+					low[key] = index[l.key]
+				}
+			}
+		}
+		if low[key] != index[key] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+	for _, key := range p.order {
+		if _, ok := index[key]; !ok {
+			connect(key)
+		}
+	}
+
+	for _, component := range components {
+		in := inComponent(component)
+		for _, key := range component {
+			for _, r := range p.rules[key] {
+				for _, l := range r.body {
+					if !l.negated || !in[l.key] {
+						continue
+					}
+					if l.key == key {
+						return nil, &Error{r.pos, fmt.Sprintf("the program isnt stratified, %s depends on its own negation", key)}
+					}
+					return nil, &Error{r.pos, fmt.Sprintf("the program isnt stratified, %s depends on \\+ %s which depends on %s in turn", key, l.key, key)}
+				}
+			}
+		}
+	}
+	return components, nil
+}
+
+func inComponent(component []string) map[string]bool {
+	in := make(map[string]bool, len(component))
+	for _, key := range component {
+		in[key] = true
+	}
+	return in
+}
+
+// relation holds the tuples of a predicate
+type relation struct {
+	tuples [][]ast.Term
+	keys   map[string]bool
+	// indexes maps an argument position to the tuples with each value in that position, they are built when first needed
+	indexes map[int]map[string][]int
+}
+
+func newRelation() *relation {
+	return &relation{keys: make(map[string]bool), indexes: make(map[int]map[string][]int)}
+}
+
+// termKey returns a string which is the same for two terms if and only if they are equal
+func termKey(t ast.Term) string {
+	return ast.WriteTerm(t, ast.WriteOptions{Quoted: true})
+}
+
+func tupleKey(tuple []ast.Term) string {
+	return termKey(ast.CreateFact("", tuple...))
+}
+
+func (r *relation) contains(tuple []ast.Term) bool {
+	return r.keys[tupleKey(tuple)]
+}
+
+// add adds the tuple to the relation, returning false if it was already there
+func (r *relation) add(tuple []ast.Term) bool {
+	key := tupleKey(tuple)
+	if r.keys[key] {
+		return false
+	}
+	r.keys[key] = true
+	for pos, index := range r.indexes {
+		k := termKey(tuple[pos])
+		index[k] = append(index[k], len(r.tuples))
+	}
+	r.tuples = append(r.tuples, tuple)
+	return true
+}
+
+// lookup returns the positions of the tuples with the value in the argument at pos
+func (r *relation) lookup(pos int, value ast.Term) []int {
+	index, ok := r.indexes[pos]
+	if !ok {
+		index = make(map[string][]int)
+		for i, tuple := range r.tuples {
+			k := termKey(tuple[pos])
+			index[k] = append(index[k], i)
+		}
+		r.indexes[pos] = index
+	}
+	return index[termKey(value)]
+}
+
+// facts returns the tuples as facts
+func (r *relation) facts(functor string) []*ast.Fact {
+	facts := make([]*ast.Fact, len(r.tuples))
+	for i, tuple := range r.tuples {
+		facts[i] = ast.CreateFact(functor, tuple...)
+	}
+	return facts
+}
+
+// evaluation holds the relations computed so far by predicate
+type evaluation struct {
+	relations map[string]*relation
+}
+
+func (e *evaluation) relation(key string) *relation {
+	r, ok := e.relations[key]
+	if !ok {
+		r = newRelation()
+		e.relations[key] = r
+	}
+	return r
+}
+
+/**
+ * fixpoint computes the predicates in a component. The first round fires every rule against everything known so far,
+ * after that a rule is fired once for each of its goals in the component, with that goal only matching the tuples
+ * that were new in the previous round. It stops once a round finds nothing new.
+ */
+func (e *evaluation) fixpoint(component []string, rules []*rule) {
+	in := inComponent(component)
+	delta := e.round(rules, func(r *rule, fire func(int)) {
+		fire(-1)
+	}, nil)
+	for len(delta) > 0 {
+		previous := delta
+		delta = e.round(rules, func(r *rule, fire func(int)) {
+			for i, l := range r.body {
+				if !l.negated && in[l.key] && previous[l.key] != nil {
+					fire(i)
+				}
+			}
+		}, previous)
+	}
+}
+
+// round fires the rules as chosen by each and adds what they find to the relations, returning the tuples which were new
+func (e *evaluation) round(rules []*rule, each func(r *rule, fire func(int)), delta map[string]*relation) map[string]*relation {
+	found := map[string]*relation{}
+	for _, r := range rules {
+		key := r.head.Signature().String()
+		each(r, func(restricted int) {
+			e.fire(r, restricted, delta, func(tuple []ast.Term) {
+				if e.relation(key).contains(tuple) {
+					return
+				}
+				if found[key] == nil {
+					found[key] = newRelation()
+				}
+				found[key].add(tuple)
+			})
+		})
+	}
+	for key, r := range found {
+		for _, tuple := range r.tuples {
+			e.relation(key).add(tuple)
+		}
+	}
+	return found
+}
+
+// fire finds every way to satisfy the body of the rule, the goal at restricted only matches the tuples in delta
+func (e *evaluation) fire(r *rule, restricted int, delta map[string]*relation, emit func([]ast.Term)) {
+	bindings := map[string]ast.Term{}
+	value := func(t ast.Term) ast.Term {
+		if t.GetType() == ast.T_Variable {
+			return bindings[t.String()]
+		}
+		return t
+	}
+	instantiate := func(args []ast.Term) []ast.Term {
+		tuple := make([]ast.Term, len(args))
+		for i, a := range args {
+			tuple[i] = value(a)
+		}
+		return tuple
+	}
+
+	var solve func(i int)
+	solve = func(i int) {
+		if i == len(r.body) {
+			emit(instantiate(r.head.Args))
+			return
+		}
+		l := r.body[i]
+		rel := e.relation(l.key)
+		if i == restricted {
+			rel = delta[l.key]
+		}
+		// the first argument which is already bound narrows down the tuples to try
+		candidates, indexed, matched := []int(nil), false, false
+		for pos, a := range l.goal.Args {
+			if v := value(a); v != nil {
+				candidates, indexed = rel.lookup(pos, v), true
+				break
+			}
+		}
+		match := func(tuple []ast.Term) {
+			bound := []string{}
+			ok := true
+			for pos, a := range l.goal.Args {
+				v := value(a)
+				if v == nil {
+					bindings[a.String()] = tuple[pos]
+					bound = append(bound, a.String())
+					continue
+				}
+				if termKey(v) != termKey(tuple[pos]) {
+					ok = false
+					break
+				}
+			}
+			if ok && l.negated {
+				matched = true
+			} else if ok {
+				solve(i + 1)
+			}
+			for _, name := range bound {
+				delete(bindings, name)
+			}
+		}
+		if indexed {
+			for _, t := range candidates {
+				match(rel.tuples[t])
+			}
+		} else {
+			for _, tuple := range rel.tuples {
+				match(tuple)
+			}
+		}
+		// a negated goal only lets the rule go on if nothing matched it
+		if l.negated && !matched {
+			solve(i + 1)
+		}
+	}
+	solve(0)
+}
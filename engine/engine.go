@@ -0,0 +1,239 @@
+// Package engine is the entry point for embedding gopl in a Go program.
+//
+// It wires together the parser, indexer and resolver so that programs can be consulted
+// and queried with a few calls:
+//
+//	e, err := engine.New()
+//	err = e.ConsultString(`parent(tom, bob). parent(bob, ann).`)
+//	sols, err := e.Query(ctx, "parent(tom, X)")
+//	defer sols.Close()
+//	for sols.Next() {
+//		fmt.Println(sols.Bindings()["X"])
+//	}
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/datalog"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/library"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+// Engine holds a database of clauses and the resolver used to query it
+type Engine struct {
+	i indexer.Indexer
+	r *resolver.R
+
+	// unit is the test unit being consulted, see begin_tests and Tests
+	unit  string
+	tests []Test
+
+	// loaded maps the absolute path of each file loaded by use_module to the module it defines
+	loaded map[string]string
+
+	// datalog evaluates the program bottom-up after each consult, see SetDatalog
+	datalog bool
+}
+
+// New creates an engine with the standard library loaded, the options are passed on to the resolver
+func New(opts ...resolver.Option) (*Engine, error) {
+	i := indexer.NewDefault()
+	if err := library.Load(i); err != nil {
+		return nil, err
+	}
+	return &Engine{
+		i:      i,
+		r:      resolver.New(i, opts...),
+		loaded: make(map[string]string),
+	}, nil
+}
+
+// Resolver returns the underlying resolver, i.e. to change where output is written with SetOutput
+func (e *Engine) Resolver() *resolver.R {
+	return e.r
+}
+
+// Indexer returns the database of clauses, i.e. to list the clauses in a coverage report
+func (e *Engine) Indexer() indexer.Indexer {
+	return e.i
+}
+
+/**
+ * ConsultString adds all of the clauses in src to the database.
+ * Queries (`?- goal.`) are run as directives once everything before them has been added,
+ * a directive which fails or raises an exception stops the consult with an error.
+ * Tests between `?- begin_tests(Unit).` and `?- end_tests(Unit).` are collected rather than run, see Tests.
+ * Each fact and rule goes through term_expansion/2 and goal_expansion/2 first, see resolver.Expand.
+ * A source starting with `:- module(Name, Exports).` is loaded into that module, see moduleDirective.
+ */
+func (e *Engine) ConsultString(src string) error {
+	return e.consult(src, "")
+}
+
+// consult runs ConsultString recording the file the clauses came from in their positions
+func (e *Engine) consult(src string, filename string) error {
+	module, err := e.load(src, filename)
+	if err != nil {
+		return err
+	}
+	// consulting a module file makes its exports callable from the user module
+	if err := e.importModule(indexer.User, module, nil); err != nil {
+		return err
+	}
+	if e.datalog {
+		_, err = e.Datalog()
+	}
+	return err
+}
+
+/**
+ * SetDatalog turns bottom-up evaluation on or off. When it is on, every consult ends by evaluating the program
+ * with Datalog, so queries to the predicates it defines only look up facts. Directives in the source still run
+ * top-down while it is being consulted. A program which isnt Datalog fails to consult.
+ */
+func (e *Engine) SetDatalog(on bool) {
+	e.datalog = on
+}
+
+// Datalog evaluates the rules consulted so far bottom-up and replaces them with the facts they derive, see datalog.Evaluate
+func (e *Engine) Datalog() ([]datalog.Relation, error) {
+	return datalog.Evaluate(e.i, e.r.Registered)
+}
+
+// load adds the clauses in src to the database, returning the module they went into
+func (e *Engine) load(src string, filename string) (string, error) {
+	module := indexer.User
+	if strings.TrimSpace(src) == "" {
+		return module, nil
+	}
+	statements, err := e.r.Ops().Parse([]rune(src), filename)
+	if err != nil {
+		return module, err
+	}
+
+	for _, s := range statements {
+		if s.GetType() != ast.T_Query {
+			clauses, err := e.r.Expand(context.Background(), s)
+			if err != nil {
+				return module, err
+			}
+			for _, c := range clauses {
+				e.index(module, e.testClause(module, c))
+			}
+			continue
+		}
+		if ok, err := e.testDirective(s.(*ast.Query)); ok {
+			if err != nil {
+				return module, err
+			}
+			continue
+		}
+		if ok, err := e.moduleDirective(s.(*ast.Query), &module, filename); ok {
+			if err != nil {
+				return module, err
+			}
+			continue
+		}
+		if err := e.directive(module, s.(*ast.Query)); err != nil {
+			return module, err
+		}
+	}
+	if e.unit != "" {
+		unit := e.unit
+		e.unit = ""
+		return module, fmt.Errorf("begin_tests(%s) without end_tests(%s)", unit, unit)
+	}
+	return module, nil
+}
+
+// ConsultFile adds all of the clauses in the file to the database, see ConsultString
+func (e *Engine) ConsultFile(filename string) error {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := e.consult(string(src), filename); err != nil {
+		// syntax and datalog errors already start with the file
+		var syntaxErrors syntax.Errors
+		var datalogError *datalog.Error
+		if errors.As(err, &syntaxErrors) || errors.As(err, &datalogError) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// directive runs a query from a consulted source in the module it is in, only the first solution is used
+func (e *Engine) directive(module string, q *ast.Query) error {
+	goal := q
+	if module != indexer.User {
+		goal = &ast.Query{ast.CreateFact(":", ast.CreateAtom(module), ast.GoalsTerm(*q))}
+	}
+	sols := e.query(context.Background(), goal)
+	defer sols.Close()
+	if sols.Next() {
+		return nil
+	}
+	if err := sols.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("directive failed: %s", q)
+}
+
+// Assert adds a single clause (a fact or rule, the final `.` is optional) to the database
+func (e *Engine) Assert(clause string) error {
+	clause = strings.TrimSpace(clause)
+	if !strings.HasSuffix(clause, ".") {
+		clause = clause + "."
+	}
+	statements, err := e.r.Ops().Parse([]rune(clause), "")
+	if err != nil {
+		return err
+	}
+	if len(statements) != 1 || statements[0].GetType() == ast.T_Query {
+		return fmt.Errorf("expected a single fact or rule, got %q", clause)
+	}
+	e.i.IndexStatement(statements[0])
+	return nil
+}
+
+/**
+ * AssertFacts converts each item of a slice (usually a slice of structs) with ToTerm and adds it to the database as a fact.
+ * Nothing is added if any of the items cant be converted.
+ */
+func (e *Engine) AssertFacts(items interface{}) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("AssertFacts expects a slice, got %T", items)
+	}
+
+	facts := make([]*ast.Fact, v.Len())
+	for i := range facts {
+		t, err := toTerm(v.Index(i))
+		if err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+		switch t.GetType() {
+		case ast.T_Atom:
+			facts[i] = ast.CreateFact(t.String())
+		case ast.T_Fact:
+			facts[i] = t.(*ast.Fact)
+		default:
+			return fmt.Errorf("[%d]: %s is not a fact", i, ast.WriteTerm(t, ast.WriteOptions{Quoted: true}))
+		}
+	}
+	for _, f := range facts {
+		e.i.IndexStatement(f)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package engine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/engine"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+const family = `
+parent(tom, bob).
+parent(tom, liz).
+parent(bob, ann).
+grandparent(X, Z) :- parent(X, Y), parent(Y, Z).
+`
+
+func newEngine(t *testing.T, src string) *engine.Engine {
+	e, err := engine.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ConsultString(src); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestQuery(t *testing.T) {
+	e := newEngine(t, family)
+
+	sols, err := e.Query(context.Background(), "parent(tom, X)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+
+	found := []string{}
+	for sols.Next() {
+		var x string
+		if err := sols.Scan(&x); err != nil {
+			t.Fatal(err)
+		}
+		found = append(found, x)
+	}
+	if err := sols.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0] != "bob" || found[1] != "liz" {
+		t.Errorf("unexpected solutions %v", found)
+	}
+}
+
+func TestQueryBindings(t *testing.T) {
+	e := newEngine(t, family)
+
+	sols, err := e.Query(context.Background(), "grandparent(G, C), length([a, b], N).")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+
+	if !sols.Next() {
+		t.Fatalf("expected a solution, err: %v", sols.Err())
+	}
+	b := sols.Bindings()
+	if len(b) != 3 || b["G"].String() != "tom" || b["C"].String() != "ann" {
+		t.Errorf("unexpected bindings %v", b)
+	}
+
+	var g, c ast.Term
+	var n int
+	if err := sols.Scan(&g, &c, &n); err != nil {
+		t.Fatal(err)
+	}
+	if g.String() != "tom" || c.String() != "ann" || n != 2 {
+		t.Errorf("unexpected scan results %s %s %d", g, c, n)
+	}
+	if sols.Next() {
+		t.Errorf("expected only one solution")
+	}
+}
+
+func TestQueryProofs(t *testing.T) {
+	e, err := engine.New(resolver.WithExplain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ConsultString(family); err != nil {
+		t.Fatal(err)
+	}
+
+	sols, err := e.Query(context.Background(), "grandparent(tom, Who)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() {
+		t.Fatalf("expected a solution: %v", sols.Err())
+	}
+	var text bytes.Buffer
+	if err := resolver.WriteProofText(&text, sols.Proofs()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "grandparent(tom,ann)  [grandparent(X,Z) :- parent(X,Y),parent(Y,Z) | X = tom, Y = bob, Z = ann]\n" +
+		"  parent(tom,bob)  [parent(tom,bob)]\n" +
+		"  parent(bob,ann)  [parent(bob,ann)]\n"
+	if text.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text.String())
+	}
+}
+
+func TestAssertAndConsultFile(t *testing.T) {
+	e := newEngine(t, "")
+	if err := e.Assert("likes(mary, wine)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Assert("?- likes(X, Y)."); err == nil {
+		t.Errorf("expected asserting a query to fail")
+	}
+
+	dir, err := ioutil.TempDir("", "gopl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "likes.pl")
+	src := "likes(john, X) :- likes(mary, X).\n?- likes(john, wine).\n"
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ConsultFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	sols, err := e.Query(context.Background(), "likes(john, W)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() || sols.Bindings()["W"].String() != "wine" {
+		t.Errorf("expected john to like wine")
+	}
+}
+
+func TestConsultErrors(t *testing.T) {
+	e := newEngine(t, "")
+	if err := e.ConsultString("foo(."); err == nil || !strings.HasPrefix(err.Error(), "1:5: expected ") {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+	if err := e.ConsultString("?- fail."); err == nil {
+		t.Errorf("expected a failed directive to return an error")
+	}
+	if err := e.ConsultFile("/does/not/exist.pl"); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestQueryException(t *testing.T) {
+	e := newEngine(t, "")
+	sols, err := e.Query(context.Background(), "throw(oops)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if sols.Next() {
+		t.Fatalf("expected no solutions")
+	}
+	var ex *engine.Exception
+	if !errors.As(sols.Err(), &ex) || ex.Term.String() != "oops" {
+		t.Errorf("expected the exception oops, got %v", sols.Err())
+	}
+}
+
+func TestQueryOutput(t *testing.T) {
+	e := newEngine(t, "")
+	var buf bytes.Buffer
+	e.Resolver().SetOutput(&buf)
+
+	sols, err := e.Query(context.Background(), `format("~w-~w", [a, b])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() || buf.String() != "a-b" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestQueryCancel(t *testing.T) {
+	e := newEngine(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	sols, err := e.Query(ctx, "member(X, [1, 2, 3])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+
+	if !sols.Next() {
+		t.Fatalf("expected a solution")
+	}
+	cancel()
+	if sols.Next() {
+		t.Errorf("expected no more solutions after cancelling")
+	}
+	if !errors.Is(sols.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", sols.Err())
+	}
+}
+
+func TestCloseStopsGoroutines(t *testing.T) {
+	e := newEngine(t, "nat(z).\nnat(s(X)) :- nat(X).\n")
+	baseline := runtime.NumGoroutine()
+
+	for _, goal := range []string{"length(L, N)", "nat(X)", "nat(X), length(L, N)"} {
+		sols, err := e.Query(context.Background(), goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n < 3; n++ {
+			if !sols.Next() {
+				t.Fatalf("%s: expected a solution", goal)
+			}
+		}
+		sols.Close()
+		if sols.Next() {
+			t.Errorf("%s: expected no solutions after Close", goal)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("leaked %d goroutines", runtime.NumGoroutine()-baseline)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAnonymousVariables(t *testing.T) {
+	e := newEngine(t, "any(_, _).\np(1, a).\np(2, b).\n")
+
+	for _, goal := range []string{"any(1, 2)", "aggregate_all(count, p(_, _), 2)", "p(_, X)"} {
+		sols, err := e.Query(context.Background(), goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sols.Next() {
+			t.Errorf("%s: expected a solution, got %v", goal, sols.Err())
+		} else if vars := sols.Bindings(); len(vars) > 1 {
+			t.Errorf("%s: expected `_` to be left out of the bindings, got %v", goal, vars)
+		}
+		sols.Close()
+	}
+
+	// a variable the program names is kept apart from the `_` next to it and reported like any other
+	sols, err := e.Query(context.Background(), "X = f(_, _G1), X = f(a, b)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() {
+		t.Fatalf("expected a solution, got %v", sols.Err())
+	}
+	if vars := sols.Bindings(); len(vars) != 2 || vars["_G1"] == nil || vars["_G1"].String() != "b" {
+		t.Errorf("expected X and _G1 to be bound, got %v", vars)
+	}
+}
@@ -0,0 +1,416 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * ToTerm and FromTerm convert between Go values and terms:
+ *   bool                 <-> the atoms true and false
+ *   ints, uints, floats  <-> numbers, an integer beyond 2^53 cant be converted since numbers are float64
+ *   string               <-> strings (atoms are accepted when decoding)
+ *   Atom                 <-> atoms
+ *   slices and arrays    <-> lists
+ *   maps                 <-> lists of Key-Value pairs, sorted in the standard order of terms
+ *   structs              <-> compound terms with one argument for each field
+ *   ast.Term             <-> itself
+ *
+ * A struct's functor is its type name in snake_case (EmployeeRecord becomes employee_record), a blank field
+ * tagged with a name overrides it, i.e. `_ struct{} gopl:"employee"`. Unexported fields and fields tagged
+ * `gopl:"-"` are skipped. When decoding, a struct can also be filled from a list of Name-Value pairs where
+ * the Name is the field's `gopl:"name"` tag or its name in snake_case.
+ */
+
+// Atom is a string which converts to an atom rather than a string
+type Atom string
+
+// maxExact is the largest magnitude an integer can have and still convert to a float64 exactly
+const maxExact = 1 << 53
+
+var termType = reflect.TypeOf((*ast.Term)(nil)).Elem()
+var atomType = reflect.TypeOf(Atom(""))
+
+// ToTerm converts a Go value into a term
+func ToTerm(v interface{}) (ast.Term, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot convert nil to a term")
+	}
+	return toTerm(reflect.ValueOf(v))
+}
+
+func toTerm(v reflect.Value) (ast.Term, error) {
+	if v.Type().Implements(termType) {
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot convert nil to a term")
+		}
+		return v.Interface().(ast.Term), nil
+	}
+	if v.Type() == atomType {
+		return ast.CreateAtom(v.String()), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return ast.CreateAtom("true"), nil
+		}
+		return ast.CreateAtom("false"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); i > maxExact || i < -maxExact {
+			return nil, fmt.Errorf("cannot convert %d to a number without losing precision", i)
+		}
+		return ast.CreateNumericLiteral(float64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u > maxExact {
+			return nil, fmt.Errorf("cannot convert %d to a number without losing precision", u)
+		}
+		return ast.CreateNumericLiteral(float64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return ast.CreateNumericLiteral(v.Float()), nil
+	case reflect.String:
+		return ast.CreateStringLiteral(v.String()), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]ast.Term, v.Len())
+		for i := range items {
+			item, err := toTerm(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			items[i] = item
+		}
+		return ast.CreateList(items...), nil
+	case reflect.Map:
+		return mapToTerm(v)
+	case reflect.Struct:
+		return structToTerm(v)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot convert nil to a term")
+		}
+		return toTerm(v.Elem())
+	}
+	return nil, fmt.Errorf("cannot convert %s to a term", v.Type())
+}
+
+func mapToTerm(v reflect.Value) (ast.Term, error) {
+	pairs := make([]*ast.Fact, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := toTerm(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := toTerm(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("[%s]: %w", key, err)
+		}
+		pairs = append(pairs, ast.CreateFact("-", key, value))
+	}
+
+	// maps have no order so sort the pairs to keep the result stable
+	sort.Slice(pairs, func(i, j int) bool {
+		return ast.CompareTerms(pairs[i].Args[0], pairs[j].Args[0]) < 0
+	})
+	items := make([]ast.Term, len(pairs))
+	for i, p := range pairs {
+		items[i] = p
+	}
+	return ast.CreateList(items...), nil
+}
+
+// structField is a field which is converted to one of the arguments of a compound term
+type structField struct {
+	index int
+	name  string
+}
+
+// structInfo finds the functor and the fields to convert for a struct type
+func structInfo(t reflect.Type) (string, []structField) {
+	functor := snakeCase(t.Name())
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("gopl")
+		if f.Name == "_" {
+			if tag != "" {
+				functor = tag
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = snakeCase(f.Name)
+		}
+		fields = append(fields, structField{i, tag})
+	}
+	return functor, fields
+}
+
+func structToTerm(v reflect.Value) (ast.Term, error) {
+	functor, fields := structInfo(v.Type())
+	if functor == "" {
+		return nil, fmt.Errorf("cannot convert anonymous struct %s to a term without a functor", v.Type())
+	}
+	args := make([]ast.Term, len(fields))
+	for i, f := range fields {
+		arg, err := toTerm(v.Field(f.index))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		args[i] = arg
+	}
+	if len(args) == 0 {
+		return ast.CreateAtom(functor), nil
+	}
+	return ast.CreateFact(functor, args...), nil
+}
+
+// snakeCase converts a Go name like EmployeeID to employee_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// start a new word at a lower to upper change or at the last upper case letter of an acronym
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+/**
+ * FromTerm stores the term in the value pointed to by dst, see ToTerm for how each type is converted.
+ * Decoding into an empty interface produces float64, string, Atom, []interface{} or, for anything else, the term itself.
+ */
+func FromTerm(t ast.Term, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("FromTerm needs a non nil pointer, got %T", dst)
+	}
+	return fromTerm(t, v.Elem())
+}
+
+func fromTerm(t ast.Term, v reflect.Value) error {
+	if t == nil {
+		return fmt.Errorf("cannot convert nil to %s", v.Type())
+	}
+	if v.Type() == termType {
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.Type() == atomType {
+		if t.GetType() != ast.T_Atom {
+			return mismatch(t, v)
+		}
+		v.SetString(t.String())
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if t.GetType() != ast.T_Atom || (t.String() != "true" && t.String() != "false") {
+			return mismatch(t, v)
+		}
+		v.SetBool(t.String() == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := integer(t)
+		if !ok || n < math.MinInt64 || n >= 1<<63 || v.OverflowInt(int64(n)) {
+			return mismatch(t, v)
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := integer(t)
+		if !ok || n < 0 || n >= 1<<64 || v.OverflowUint(uint64(n)) {
+			return mismatch(t, v)
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := t.(*ast.NumericLiteral)
+		if !ok || v.OverflowFloat(n.Value()) {
+			return mismatch(t, v)
+		}
+		v.SetFloat(n.Value())
+	case reflect.String:
+		if t.GetType() != ast.T_String && t.GetType() != ast.T_Atom {
+			return mismatch(t, v)
+		}
+		v.SetString(t.String())
+	case reflect.Slice:
+		items, ok := properList(t)
+		if !ok {
+			return mismatch(t, v)
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := fromTerm(item, s.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		items, ok := properList(t)
+		if !ok || len(items) != v.Len() {
+			return mismatch(t, v)
+		}
+		for i, item := range items {
+			if err := fromTerm(item, v.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		return mapFromTerm(t, v)
+	case reflect.Struct:
+		return structFromTerm(t, v)
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := fromTerm(t, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return mismatch(t, v)
+		}
+		v.Set(reflect.ValueOf(naturalValue(t)))
+	default:
+		return mismatch(t, v)
+	}
+	return nil
+}
+
+func mismatch(t ast.Term, v reflect.Value) error {
+	return fmt.Errorf("cannot convert %s to %s", ast.WriteTerm(t, ast.WriteOptions{Quoted: true}), v.Type())
+}
+
+// integer returns the value of a number with no fractional part
+func integer(t ast.Term) (float64, bool) {
+	n, ok := t.(*ast.NumericLiteral)
+	if !ok || n.Value() != math.Trunc(n.Value()) {
+		return 0, false
+	}
+	return n.Value(), true
+}
+
+func properList(t ast.Term) ([]ast.Term, bool) {
+	items, tail := ast.ListToSlice(t)
+	return items, ast.IsEmptyList(tail)
+}
+
+// pairs returns the keys and values from a list of Key-Value pairs
+func pairs(t ast.Term) ([]ast.Term, []ast.Term, bool) {
+	items, ok := properList(t)
+	if !ok {
+		return nil, nil, false
+	}
+	keys := make([]ast.Term, len(items))
+	values := make([]ast.Term, len(items))
+	for i, item := range items {
+		p, ok := item.(*ast.Fact)
+		if !ok || p.Head != "-" || len(p.Args) != 2 {
+			return nil, nil, false
+		}
+		keys[i], values[i] = p.Args[0], p.Args[1]
+	}
+	return keys, values, true
+}
+
+func mapFromTerm(t ast.Term, v reflect.Value) error {
+	keys, values, ok := pairs(t)
+	if !ok {
+		return mismatch(t, v)
+	}
+	m := reflect.MakeMapWithSize(v.Type(), len(keys))
+	for i := range keys {
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := fromTerm(keys[i], key); err != nil {
+			return err
+		}
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := fromTerm(values[i], value); err != nil {
+			return fmt.Errorf("[%s]: %w", keys[i], err)
+		}
+		m.SetMapIndex(key, value)
+	}
+	v.Set(m)
+	return nil
+}
+
+func structFromTerm(t ast.Term, v reflect.Value) error {
+	functor, fields := structInfo(v.Type())
+
+	// a list of Name-Value pairs sets the fields by name
+	if keys, values, ok := pairs(t); ok {
+		byName := make(map[string]int)
+		for _, f := range fields {
+			byName[f.name] = f.index
+		}
+		for i, k := range keys {
+			index, ok := byName[k.String()]
+			if !ok || (k.GetType() != ast.T_Atom && k.GetType() != ast.T_String) {
+				return fmt.Errorf("%s has no field %s", v.Type(), ast.WriteTerm(k, ast.WriteOptions{Quoted: true}))
+			}
+			if err := fromTerm(values[i], v.Field(index)); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		return nil
+	}
+
+	var args []ast.Term
+	switch t.GetType() {
+	case ast.T_Atom:
+		if t.String() != functor {
+			return mismatch(t, v)
+		}
+	case ast.T_Fact:
+		f := t.(*ast.Fact)
+		if f.Head != functor {
+			return mismatch(t, v)
+		}
+		args = f.Args
+	default:
+		return mismatch(t, v)
+	}
+	if len(args) != len(fields) {
+		return fmt.Errorf("%s has %d fields but %s has %d arguments", v.Type(), len(fields), functor, len(args))
+	}
+	for i, f := range fields {
+		if err := fromTerm(args[i], v.Field(f.index)); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// naturalValue is the Go value used when decoding into an empty interface
+func naturalValue(t ast.Term) interface{} {
+	switch t.GetType() {
+	case ast.T_Number:
+		return t.(*ast.NumericLiteral).Value()
+	case ast.T_String:
+		return t.String()
+	case ast.T_Atom:
+		return Atom(t.String())
+	}
+	if items, ok := properList(t); ok {
+		ret := make([]interface{}, len(items))
+		for i, item := range items {
+			ret[i] = naturalValue(item)
+		}
+		return ret
+	}
+	return t
+}
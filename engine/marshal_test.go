@@ -0,0 +1,202 @@
+package engine_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/engine"
+)
+
+type Employee struct {
+	Name     string
+	Dept     engine.Atom `gopl:"department"`
+	Salary   int
+	Manager  bool
+	internal int
+	Notes    string `gopl:"-"`
+}
+
+type Point struct {
+	_ struct{} `gopl:"pt"`
+	X float64
+	Y float64
+}
+
+func writeTerm(t ast.Term) string {
+	return ast.WriteTerm(t, ast.WriteOptions{Quoted: true})
+}
+
+func TestToTerm(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{42, "42"},
+		{uint8(7), "7"},
+		{2.5, "2.5"},
+		{"hello world", `"hello world"`},
+		{engine.Atom("hello world"), "'hello world'"},
+		{true, "true"},
+		{[]int{1, 2, 3}, "[1,2,3]"},
+		{[]string{}, "[]"},
+		{[2]bool{true, false}, "[true,false]"},
+		{map[string]int{"b": 2, "a": 1}, `[-("a",1),-("b",2)]`},
+		{map[engine.Atom][]int{"x": {1}}, "[-(x,[1])]"},
+		{Employee{Name: "ann", Dept: "sales", Salary: 100, Notes: "skipped"}, `employee("ann",sales,100,false)`},
+		{&Point{X: 1, Y: 2}, "pt(1,2)"},
+		{[]Point{{X: 1}}, "[pt(1,0)]"},
+		{ast.CreateVariable("X"), "X"},
+		{int64(1) << 53, "9.007199254740992e+15"},
+		{-(int64(1) << 53), "-9.007199254740992e+15"},
+	}
+	for _, c := range cases {
+		term, err := engine.ToTerm(c.value)
+		if err != nil {
+			t.Errorf("%#v: unexpected error %s", c.value, err)
+			continue
+		}
+		if got := writeTerm(term); got != c.expected {
+			t.Errorf("%#v: expected %s, got %s", c.value, c.expected, got)
+		}
+	}
+
+	lossy := []interface{}{int64(1)<<53 + 1, -(int64(1)<<53 + 1), uint64(math.MaxUint64), []int64{math.MaxInt64}}
+	for _, v := range append(lossy, nil, (*Point)(nil), make(chan int), []interface{}{func() {}}) {
+		if _, err := engine.ToTerm(v); err == nil {
+			t.Errorf("%#v: expected an error", v)
+		}
+	}
+}
+
+func TestFromTerm(t *testing.T) {
+	a := ast.CreateAtom
+	n := ast.CreateNumericLiteral
+	s := ast.CreateStringLiteral
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	var i int
+	var u uint8
+	var i64 int64
+	var u64 uint64
+	var fl float64
+	var str string
+	var atom engine.Atom
+	var b bool
+	var ints []int
+	var m map[string]float64
+	var e Employee
+	var p *Point
+	var any interface{}
+	var term ast.Term
+
+	checks := []struct {
+		term     ast.Term
+		dst      interface{}
+		expected interface{}
+	}{
+		{n(42), &i, 42},
+		{n(7), &u, uint8(7)},
+		{n(2.5), &fl, 2.5},
+		{s("hi"), &str, "hi"},
+		{a("hi"), &str, "hi"},
+		{a("hi"), &atom, engine.Atom("hi")},
+		{a("true"), &b, true},
+		{l(n(1), n(2)), &ints, []int{1, 2}},
+		{l(f("-", s("a"), n(1)), f("-", a("b"), n(2))), &m, map[string]float64{"a": 1, "b": 2}},
+		{f("employee", s("ann"), a("sales"), n(100), a("true")), &e, Employee{Name: "ann", Dept: "sales", Salary: 100, Manager: true}},
+		{l(f("-", a("name"), s("bob")), f("-", a("department"), a("it"))), &e, Employee{Name: "bob", Dept: "it", Salary: 100, Manager: true}},
+		{f("pt", n(1), n(2)), &p, &Point{X: 1, Y: 2}},
+		{l(n(1), a("x"), s("y"), l()), &any, []interface{}{1.0, engine.Atom("x"), "y", []interface{}{}}},
+		{f("g", a("x")), &term, f("g", a("x"))},
+		{n(-(1 << 63)), &i64, int64(math.MinInt64)},
+		{n(1 << 63), &u64, uint64(1 << 63)},
+	}
+	for _, c := range checks {
+		if err := engine.FromTerm(c.term, c.dst); err != nil {
+			t.Errorf("%s: unexpected error %s", writeTerm(c.term), err)
+			continue
+		}
+		if got := reflect.ValueOf(c.dst).Elem().Interface(); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", writeTerm(c.term), c.expected, got)
+		}
+	}
+
+	failures := []struct {
+		term ast.Term
+		dst  interface{}
+	}{
+		{n(2.5), &i},
+		{n(300), &u},
+		{n(-1), &u},
+		{a("yes"), &b},
+		{s("x"), &atom},
+		{l(n(1), a("x")), &ints},
+		{f("person", s("ann")), &e},
+		{f("employee", s("ann")), &e},
+		{l(f("-", a("age"), n(3))), &e},
+		{n(1), p},
+		// 2^63 and 2^64 are the first floats which dont fit
+		{n(1 << 63), &i64},
+		{n(1 << 64), &u64},
+	}
+	for _, c := range failures {
+		if err := engine.FromTerm(c.term, c.dst); err == nil {
+			t.Errorf("%s into %T: expected an error", writeTerm(c.term), c.dst)
+		}
+	}
+}
+
+func TestAssertFacts(t *testing.T) {
+	e := newEngine(t, "")
+	staff := []Employee{
+		{Name: "ann", Dept: "sales", Salary: 100},
+		{Name: "bob", Dept: "it", Salary: 120, Manager: true},
+	}
+	if err := e.AssertFacts(staff); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AssertFacts([]int{1}); err == nil {
+		t.Errorf("expected an error asserting numbers")
+	}
+	if err := e.AssertFacts(Point{}); err == nil {
+		t.Errorf("expected an error asserting something that isnt a slice")
+	}
+
+	sols, err := e.Query(context.Background(), "employee(Name, Dept, Salary, true)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() {
+		t.Fatalf("expected a solution: %v", sols.Err())
+	}
+	var name string
+	var dept engine.Atom
+	var salary uint
+	if err := sols.Scan(&name, &dept, &salary); err != nil {
+		t.Fatal(err)
+	}
+	if name != "bob" || dept != "it" || salary != 120 {
+		t.Errorf("unexpected solution %s %s %d", name, dept, salary)
+	}
+
+	var all []Employee
+	sols, err = e.Query(context.Background(), "findall(employee(N, D, S, M), employee(N, D, S, M), L)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() {
+		t.Fatalf("expected a solution: %v", sols.Err())
+	}
+	if err := engine.FromTerm(sols.Bindings()["L"], &all); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(all, staff) {
+		t.Errorf("expected %v, got %v", staff, all)
+	}
+}
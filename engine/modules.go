@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/library"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+/**
+ * moduleDirective handles module/2, use_module/1 and use_module/2, returning false if the query is any other directive.
+ *   :- module(Name, Exports).       - the clauses after it go into the module Name, Exports is a list of Name/Arity
+ *   :- use_module(File).            - loads File unless it already has been and imports everything its module exports
+ *   :- use_module(File, Imports).   - only imports the predicates in the list Imports
+ * module is the module the source is being loaded into and is updated by module/2.
+ * File is relative to the file being consulted and `.pl` is added when there is no file without it.
+ * The bundled library is always loaded so `use_module(library(lists))` and the like do nothing.
+ */
+func (e *Engine) moduleDirective(q *ast.Query, module *string, filename string) (bool, error) {
+	if len(*q) != 1 {
+		return false, nil
+	}
+	f, ok := (*q)[0].(*ast.Fact)
+	if !ok {
+		return false, nil
+	}
+	switch {
+	case f.Head == "module" && len(f.Args) == 2:
+		if *module != indexer.User {
+			return true, fmt.Errorf("module/2 used twice in the same source, already in module %s", *module)
+		}
+		if f.Args[0].GetType() != ast.T_Atom {
+			return true, fmt.Errorf("module/2 expects a module name, got %s", ast.WriteTerm(f.Args[0], ast.WriteOptions{Quoted: true}))
+		}
+		exports, err := indicators(f.Args[1])
+		if err != nil {
+			return true, fmt.Errorf("module/2: %w", err)
+		}
+		if err := e.modules().DefineModule(f.Args[0].String(), filename, exports); err != nil {
+			return true, err
+		}
+		*module = f.Args[0].String()
+		return true, nil
+	case f.Head == "use_module" && (len(f.Args) == 1 || len(f.Args) == 2):
+		var imports []*ast.Signature
+		if len(f.Args) == 2 {
+			var err error
+			if imports, err = indicators(f.Args[1]); err != nil {
+				return true, fmt.Errorf("use_module/2: %w", err)
+			}
+		}
+		return true, e.useModule(*module, f.Args[0], imports, filename)
+	}
+	return false, nil
+}
+
+// useModule loads the file named by spec and imports its exports into the module, all of them if imports is nil
+func (e *Engine) useModule(into string, spec ast.Term, imports []*ast.Signature, from string) error {
+	if lib, ok := spec.(*ast.Fact); ok && lib.Head == "library" && len(lib.Args) == 1 {
+		for _, name := range library.Files() {
+			if name == lib.Args[0].String()+".pl" {
+				return nil
+			}
+		}
+		return fmt.Errorf("use_module: there is no library %s", ast.WriteTerm(lib.Args[0], ast.WriteOptions{Quoted: true}))
+	}
+	if spec.GetType() != ast.T_Atom && spec.GetType() != ast.T_String {
+		return fmt.Errorf("use_module expects a file name, got %s", ast.WriteTerm(spec, ast.WriteOptions{Quoted: true}))
+	}
+
+	path := spec.String()
+	if !filepath.IsAbs(path) && from != "" {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) && filepath.Ext(path) == "" {
+		path += ".pl"
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	module, ok := e.loaded[path]
+	if !ok {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// the file is marked as loaded first so that modules which use each other dont load forever
+		e.loaded[path] = indexer.User
+		unit := e.unit
+		e.unit = ""
+		module, err = e.load(string(src), path)
+		e.unit = unit
+		if err != nil {
+			var syntaxErrors syntax.Errors
+			if errors.As(err, &syntaxErrors) {
+				return err
+			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		e.loaded[path] = module
+	}
+	return e.importModule(into, module, imports)
+}
+
+// importModule imports the predicates a module exports into another module, all of them if imports is nil
+func (e *Engine) importModule(into string, module string, imports []*ast.Signature) error {
+	if module == indexer.User {
+		return nil
+	}
+	if imports == nil {
+		imports, _ = e.modules().Exports(module)
+	}
+	for _, s := range imports {
+		if err := e.modules().Import(into, module, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// index adds a clause to the module
+func (e *Engine) index(module string, s ast.Statement) {
+	if module == indexer.User {
+		e.i.IndexStatement(s)
+		return
+	}
+	e.modules().IndexModuleStatement(module, s)
+}
+
+// modules returns the engine's indexer, which always keeps modules apart
+func (e *Engine) modules() indexer.Modules {
+	return e.i.(indexer.Modules)
+}
+
+// indicators reads a list of predicate indicators, i.e. `[foo/1, bar/2]`
+func indicators(t ast.Term) ([]*ast.Signature, error) {
+	if !ast.IsProperList(t) {
+		return nil, fmt.Errorf("expected a list of predicate indicators, got %s", ast.WriteTerm(t, ast.WriteOptions{Quoted: true}))
+	}
+	items, _ := ast.ListToSlice(t)
+	signatures := []*ast.Signature{}
+	for _, item := range items {
+		pi, ok := item.(*ast.Fact)
+		if !ok || pi.Head != "/" || len(pi.Args) != 2 || pi.Args[0].GetType() != ast.T_Atom {
+			return nil, fmt.Errorf("expected a predicate indicator, got %s", ast.WriteTerm(item, ast.WriteOptions{Quoted: true}))
+		}
+		arity, ok := pi.Args[1].(*ast.NumericLiteral)
+		if !ok || arity.Value() != float64(int(arity.Value())) || arity.Value() < 0 {
+			return nil, fmt.Errorf("expected a predicate indicator, got %s", ast.WriteTerm(item, ast.WriteOptions{Quoted: true}))
+		}
+		signatures = append(signatures, &ast.Signature{Functor: pi.Args[0].String(), Arity: int(arity.Value())})
+	}
+	return signatures, nil
+}
@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+func TestModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gopl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"greetings.pl": `:- module(greetings, [greet/1, twice/2]).
+:- meta_predicate twice(0, ?).
+helper(hello).
+greet(X) :- helper(X).
+twice(G, ok) :- call(G), call(G).
+`,
+		"french.pl": `:- module(french, [greet_fr/1, all_helpers/1]).
+:- use_module(library(lists)).
+helper(bonjour).
+greet_fr(X) :- helper(X).
+all_helpers(L) :- maplist(helper, L).
+`,
+		"main.pl": `:- use_module(greetings).
+:- use_module('french.pl', [greet_fr/1]).
+helper(user_helper).
+`,
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := newEngine(t, "")
+	if err := e.ConsultFile(filepath.Join(dir, "main.pl")); err != nil {
+		t.Fatal(err)
+	}
+
+	for goal, expected := range map[string]string{
+		// each module has its own helper/1
+		"helper(X)":               "user_helper",
+		"greet(X)":                "hello",
+		"greet_fr(X)":             "bonjour",
+		"greetings:helper(X)":     "hello",
+		"M = french, M:helper(X)": "bonjour",
+		// the goal passed to a meta predicate runs in the caller's module
+		"twice(helper(X), ok)":            "user_helper",
+		"french:all_helpers([X])":         "bonjour",
+		"context_module(X)":               "user",
+		"greetings:context_module(X)":     "greetings",
+		"call(greetings:helper, X)":       "hello",
+		"findall(Y, french:helper(Y), X)": "[bonjour]",
+	} {
+		sols, err := e.Query(context.Background(), goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sols.Next() {
+			t.Errorf("%s: expected a solution, got %v", goal, sols.Err())
+		} else if got := ast.WriteTerm(sols.Bindings()["X"], ast.WriteOptions{}); got != expected {
+			t.Errorf("%s: expected X = %s, got %s", goal, expected, got)
+		}
+		sols.Close()
+	}
+
+	// all_helpers wasnt imported
+	sols, err := e.Query(context.Background(), "all_helpers(L)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if sols.Next() {
+		t.Errorf("expected all_helpers/1 not to be imported")
+	}
+
+	for src, expected := range map[string]string{
+		":- use_module(greetings, [helper/1]).": "module greetings does not export helper/1",
+		":- use_module(library(nope)).":         "use_module: there is no library nope",
+		":- module(user, []).":                  "the user module cant be redefined",
+		":- module(m, [foo]).":                  "module/2: expected a predicate indicator, got foo",
+	} {
+		if err := e.ConsultFile(writeFile(t, dir, "bad.pl", src)); err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", src, expected, err)
+		}
+	}
+}
+
+// writeFile writes src to a file in dir, returning its path
+func writeFile(t *testing.T, dir string, name string, src string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// Exception is returned by Solutions.Err when the query raised an exception which wasnt caught
+type Exception struct {
+	Term ast.Term
+}
+
+func (e *Exception) Error() string {
+	return "uncaught exception: " + ast.WriteTerm(e.Term, ast.WriteOptions{Quoted: true})
+}
+
+/**
+ * Solutions iterates over the solutions to a query.
+ * Call Next to advance to each solution and Close once finished, Close can be called before all
+ * of the solutions have been read.
+ */
+type Solutions struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closed  bool
+	vars    []string
+	out     chan *resolver.Bindings
+	current map[string]ast.Term
+	proofs  []*resolver.Proof
+	err     error
+
+	closeOnce sync.Once
+}
+
+/**
+ * Query starts resolving the goal (i.e. `member(X, [1, 2])`, the final `.` is optional).
+ * Cancelling ctx has the same effect as closing the returned Solutions.
+ */
+func (e *Engine) Query(ctx context.Context, goal string) (*Solutions, error) {
+	goal = strings.TrimSuffix(strings.TrimSpace(goal), ".")
+	q, err := e.r.Ops().ParseQuery(goal)
+	if err != nil {
+		return nil, err
+	}
+	return e.query(ctx, q), nil
+}
+
+/**
+ * QueryTerm is like Query for a goal which has already been built, i.e. from the terms in a solution.
+ * The goal is run with call/1 so it can be a conjunction.
+ */
+func (e *Engine) QueryTerm(ctx context.Context, goal ast.Term) *Solutions {
+	return e.query(ctx, &ast.Query{ast.CreateFact("call", goal)})
+}
+
+func (e *Engine) query(ctx context.Context, q *ast.Query) *Solutions {
+	ctx, cancel := context.WithCancel(ctx)
+	sols := &Solutions{
+		ctx:    ctx,
+		cancel: cancel,
+		vars:   queryVariables(q),
+		out:    make(chan *resolver.Bindings),
+	}
+	go e.r.ResolveStatementList(ctx, []ast.Statement{q}, resolver.EmptyBindings(), sols.out)
+	return sols
+}
+
+// queryVariables returns the names of the variables in the query in the order they first appear
+func queryVariables(q *ast.Query) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, s := range *q {
+		var vars []*ast.Variable
+		switch t := s.(type) {
+		case *ast.Fact:
+			vars = t.ExtractVariables()
+		case *ast.MathAssignment:
+			vars = t.ExtractVariables()
+		}
+		for _, v := range vars {
+			if !seen[v.String()] && !ast.IsAnonymous(v) {
+				seen[v.String()] = true
+				names = append(names, v.String())
+			}
+		}
+	}
+	return names
+}
+
+// Next advances to the next solution, returning false when there are no more or an error occurred (see Err)
+func (s *Solutions) Next() bool {
+	if s.err != nil || s.closed {
+		return false
+	}
+
+	select {
+	case <-s.ctx.Done():
+		s.err = s.ctx.Err()
+		s.Close()
+		return false
+	case b, ok := <-s.out:
+		if !ok {
+			s.current = nil
+			s.proofs = nil
+			return false
+		}
+		if b.IsException() {
+			s.err = &Exception{Term: b.Exception}
+			s.Close()
+			return false
+		}
+
+		s.current = make(map[string]ast.Term)
+		for _, name := range s.vars {
+			s.current[name] = b.Ground(ast.CreateVariable(name))
+		}
+		s.proofs = b.Proofs()
+		return true
+	}
+}
+
+// Vars returns the names of the variables in the query, in the order they appear
+func (s *Solutions) Vars() []string {
+	return s.vars
+}
+
+// Bindings returns the current solution as a map from the query's variable names to their values
+func (s *Solutions) Bindings() map[string]ast.Term {
+	return s.current
+}
+
+// Proofs returns the proof tree of the current solution, it is only recorded when the engine was created with resolver.WithExplain
+func (s *Solutions) Proofs() []*resolver.Proof {
+	return s.proofs
+}
+
+/**
+ * Scan copies the values of the query's variables (in the order they appear in the query) into dest.
+ * Each destination can be a *ast.Term, *string, *float64, *int or *bool (for the atoms true and false),
+ * any other pointer is filled in with FromTerm.
+ */
+func (s *Solutions) Scan(dest ...interface{}) error {
+	if s.current == nil {
+		return fmt.Errorf("Scan called without a solution")
+	}
+	if len(dest) > len(s.vars) {
+		return fmt.Errorf("expected at most %d destinations, got %d", len(s.vars), len(dest))
+	}
+	for i, d := range dest {
+		if err := scanTerm(s.current[s.vars[i]], d); err != nil {
+			return fmt.Errorf("%s: %w", s.vars[i], err)
+		}
+	}
+	return nil
+}
+
+func scanTerm(t ast.Term, dest interface{}) error {
+	switch d := dest.(type) {
+	case *ast.Term:
+		*d = t
+		return nil
+	case *string:
+		switch t.GetType() {
+		case ast.T_Atom, ast.T_String:
+			*d = t.String()
+		default:
+			*d = ast.WriteTerm(t, ast.WriteOptions{})
+		}
+		return nil
+	case *float64:
+		if n, ok := t.(*ast.NumericLiteral); ok {
+			*d = n.Value()
+			return nil
+		}
+	case *int:
+		if n, ok := t.(*ast.NumericLiteral); ok && n.Value() == float64(int(n.Value())) {
+			*d = int(n.Value())
+			return nil
+		}
+	case *bool:
+		if t.GetType() == ast.T_Atom && (t.String() == "true" || t.String() == "false") {
+			*d = t.String() == "true"
+			return nil
+		}
+	default:
+		return FromTerm(t, dest)
+	}
+	return fmt.Errorf("cannot convert %s to %T", t, dest)
+}
+
+// Err returns the error which stopped the iteration, if any
+func (s *Solutions) Err() error {
+	return s.err
+}
+
+// Close stops the query, cancelling the search for any solutions which havent been read yet
+func (s *Solutions) Close() error {
+	s.closeOnce.Do(func() {
+		s.closed = true
+		s.current = nil
+		s.proofs = nil
+		s.cancel()
+	})
+	return nil
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+/**
+ * Test is a `test(Name)` or `test(Name, Options)` clause consulted between `?- begin_tests(Unit).` and
+ * `?- end_tests(Unit).`. The clause is stored as `'$test'(Unit, Name, Options)` so tests in different units
+ * can share names and they dont clash with any test/1,2 predicates the program defines, see Goal.
+ */
+type Test struct {
+	Unit string
+	Name string
+	// Options are the options as written in the source, either a list or a single option
+	Options ast.Term
+	Pos     ast.Position
+
+	name ast.Term
+	// module is the module the test was consulted into
+	module string
+}
+
+// Goal returns the goal which runs the test's body, options is unified with the test's options once it succeeds
+func (t Test) Goal(options ast.Term) ast.Term {
+	goal := ast.CreateFact("$test", ast.CreateAtom(t.Unit), t.name, options)
+	if t.module != "" && t.module != indexer.User {
+		return ast.CreateFact(":", ast.CreateAtom(t.module), goal)
+	}
+	return goal
+}
+
+// Tests returns the tests consulted so far in the order they were defined
+func (e *Engine) Tests() []Test {
+	return e.tests
+}
+
+/**
+ * testDirective handles begin_tests/1 and end_tests/1, returning false if the query is any other directive.
+ * Units cant be nested and end_tests has to name the unit that is open.
+ */
+func (e *Engine) testDirective(q *ast.Query) (bool, error) {
+	if len(*q) != 1 {
+		return false, nil
+	}
+	f, ok := (*q)[0].(*ast.Fact)
+	if !ok || len(f.Args) != 1 || (f.Head != "begin_tests" && f.Head != "end_tests") {
+		return false, nil
+	}
+	if f.Args[0].GetType() != ast.T_Atom {
+		return true, fmt.Errorf("%s expects a unit name, got %s", f.Head, ast.WriteTerm(f.Args[0], ast.WriteOptions{Quoted: true}))
+	}
+
+	unit := f.Args[0].String()
+	if f.Head == "begin_tests" {
+		if e.unit != "" {
+			return true, fmt.Errorf("begin_tests(%s) inside unit %s", unit, e.unit)
+		}
+		e.unit = unit
+		return true, nil
+	}
+	if e.unit != unit {
+		return true, fmt.Errorf("end_tests(%s) without begin_tests(%s)", unit, unit)
+	}
+	e.unit = ""
+	return true, nil
+}
+
+// testClause rewrites a test/1,2 clause from inside a unit, anything else is returned as it is
+func (e *Engine) testClause(module string, s ast.Statement) ast.Statement {
+	var head *ast.Fact
+	var rule *ast.Rule
+	switch c := s.(type) {
+	case *ast.Fact:
+		head = c
+	case *ast.Rule:
+		head, rule = c.Head, c
+	}
+	if e.unit == "" || head == nil || head.Head != "test" || (len(head.Args) != 1 && len(head.Args) != 2) {
+		return s
+	}
+
+	var options ast.Term = ast.CreateList()
+	if len(head.Args) == 2 {
+		options = head.Args[1]
+	}
+	t := Test{
+		Unit:    e.unit,
+		Name:    ast.WriteTerm(head.Args[0], ast.WriteOptions{}),
+		Options: options,
+		name:    head.Args[0],
+		module:  module,
+	}
+
+	goal := ast.CreateFact("$test", ast.CreateAtom(e.unit), head.Args[0], options)
+	if rule == nil {
+		goal.Pos = head.Pos
+		t.Pos = head.Pos
+		e.tests = append(e.tests, t)
+		return goal
+	}
+	t.Pos = rule.Pos
+	e.tests = append(e.tests, t)
+	return &ast.Rule{Head: goal, Body: rule.Body, Pos: rule.Pos}
+}
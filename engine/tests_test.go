@@ -0,0 +1,48 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTestUnits(t *testing.T) {
+	e := newEngine(t, `
+test(outside).
+?- begin_tests(a).
+test(one) :- true().
+test(two, [fail]).
+?- end_tests(a).
+?- begin_tests(b).
+test(one).
+?- end_tests(b).
+`)
+	tests := e.Tests()
+	if len(tests) != 3 {
+		t.Fatalf("expected 3 tests, got %v", tests)
+	}
+	for i, name := range []string{"a:one", "a:two", "b:one"} {
+		if got := tests[i].Unit + ":" + tests[i].Name; got != name {
+			t.Errorf("expected %s, got %s", name, got)
+		}
+	}
+	// test/1 outside of a unit is an ordinary predicate
+	sols, err := e.Query(context.Background(), "test(X)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sols.Close()
+	if !sols.Next() || sols.Next() {
+		t.Errorf("expected only test(outside) to be defined")
+	}
+
+	for _, src := range []string{
+		"?- begin_tests(a).",
+		"?- end_tests(a).",
+		"?- begin_tests(a).\n?- begin_tests(b).",
+		"?- begin_tests(\"a\").",
+	} {
+		if err := newEngine(t, "").ConsultString(src); err == nil {
+			t.Errorf("%q: expected an error", src)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/kkoch986/gopl
 
-go 1.15
+go 1.16
 
 require (
 	github.com/c-bata/go-prompt v0.2.5
@@ -2,47 +2,226 @@ package indexer
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/kkoch986/gopl/ast"
 )
 
 type Default struct {
+	// bySig holds the clauses of each predicate keyed by the module and signature, see key
 	bySig   map[string][]ast.Statement
+	modules map[string]*module
+	all     []ast.Statement
+	// allIn holds the module each clause in all was indexed in
+	allIn   []string
 	nextVar int
+	// names maps the renamed variables back to the names used in the source
+	names map[string]string
 }
 
 func NewDefault() *Default {
 	return &Default{
 		bySig:   make(map[string][]ast.Statement),
+		modules: make(map[string]*module),
 		nextVar: 0,
+		names:   make(map[string]string),
 	}
 }
 
-// TODO: prevent duplicates of the same facts from being indexed
+// module holds what is known about a module besides its clauses
+type module struct {
+	file    string
+	exports []*ast.Signature
+	// imports maps the signatures imported into the module to the module they come from
+	imports map[string]string
+	// meta holds the meta_predicate/1 specs of the module's predicates by signature
+	meta map[string]*ast.Fact
+}
+
+// key is the key of a predicate in bySig
+func key(module string, s *ast.Signature) string {
+	return module + ":" + s.String()
+}
+
+// IndexStatement indexes a clause in the User module
 func (d *Default) IndexStatement(s ast.Statement) {
+	d.IndexModuleStatement(User, s)
+}
+
+// TODO: prevent duplicates of the same facts from being indexed
+func (d *Default) IndexModuleStatement(m string, s ast.Statement) {
 	switch s.GetType() {
 	case ast.T_Fact:
-		d.indexFact(s.(*ast.Fact))
+		if f := s.(*ast.Fact); ast.IsDCG(f) {
+			d.indexDCG(m, f)
+			return
+		}
+		d.indexFact(m, s.(*ast.Fact))
 	case ast.T_Rule:
-		d.indexRule(s.(*ast.Rule))
+		d.indexRule(m, s.(*ast.Rule))
 	}
 }
 
 // TODO: if indexing is happening in go routines, we need a mutex on nextVar
-func (d *Default) indexFact(f *ast.Fact) {
+func (d *Default) indexFact(m string, f *ast.Fact) {
 	mappings := make(map[string]string)
 	af, used := f.Anonymize(d.nextVar, "_h", &mappings)
 	d.nextVar += used
-	d.bySig[f.Signature().String()] = append(d.bySig[f.Signature().String()], af)
+	d.rememberNames(mappings)
+	k := key(m, f.Signature())
+	d.bySig[k] = append(d.bySig[k], af)
+	d.all = append(d.all, af)
+	d.allIn = append(d.allIn, m)
 }
 
-func (d *Default) indexRule(r *ast.Rule) {
-	ar, _, used := r.Anonymize(d.nextVar, "_h")
+func (d *Default) indexRule(m string, r *ast.Rule) {
+	ar, mappings, used := r.Anonymize(d.nextVar, "_h")
 	d.nextVar += used
-	fmt.Println(ar)
-	d.bySig[r.Signature().String()] = append(d.bySig[r.Signature().String()], ar)
+	d.rememberNames(mappings)
+	log.Printf("[DEBUG][IndexRule] %s", ar)
+	k := key(m, r.Signature())
+	d.bySig[k] = append(d.bySig[k], ar)
+	d.all = append(d.all, ar)
+	d.allIn = append(d.allIn, m)
+}
+
+// indexDCG translates a grammar rule into the rule it stands for and indexes that instead
+func (d *Default) indexDCG(m string, f *ast.Fact) {
+	r, err := ast.TranslateDCG(f)
+	if err != nil {
+		log.Printf("[ERROR][IndexDCG] %s", err)
+		return
+	}
+	d.indexRule(m, r)
 }
 
+// StatementsForSignature returns the clauses a goal called in the User module resolves to
 func (d *Default) StatementsForSignature(s *ast.Signature) []ast.Statement {
-	return d.bySig[s.String()]
+	_, statements := d.ModuleStatements(User, s)
+	return statements
+}
+
+// ModuleStatements returns the clauses a goal called in the module resolves to along with the module that defines them
+func (d *Default) ModuleStatements(m string, s *ast.Signature) (string, []ast.Statement) {
+	if statements := d.bySig[key(m, s)]; len(statements) > 0 {
+		return m, statements
+	}
+	if mod := d.modules[m]; mod != nil {
+		if from, ok := mod.imports[s.String()]; ok {
+			return from, d.bySig[key(from, s)]
+		}
+	}
+	switch m {
+	case System:
+		return System, nil
+	case User:
+		return System, d.bySig[key(System, s)]
+	}
+	return d.ModuleStatements(User, s)
+}
+
+// DefineModule declares a module, it is an error to define User or System or a module which is loaded from another file
+func (d *Default) DefineModule(name string, file string, exports []*ast.Signature) error {
+	if name == User || name == System {
+		return fmt.Errorf("the %s module cant be redefined", name)
+	}
+	if mod, ok := d.modules[name]; ok && mod.exports != nil && mod.file != file {
+		return fmt.Errorf("module %s is already loaded from %s", name, mod.file)
+	}
+	mod := d.module(name)
+	mod.file = file
+	mod.exports = exports
+	return nil
+}
+
+// Exports returns the predicates a module exports
+func (d *Default) Exports(m string) ([]*ast.Signature, bool) {
+	mod, ok := d.modules[m]
+	if !ok || mod.exports == nil {
+		return nil, false
+	}
+	return mod.exports, true
+}
+
+// Import makes a predicate exported by one module callable from another, a predicate can only be imported from one module
+func (d *Default) Import(into string, from string, s *ast.Signature) error {
+	exports, ok := d.Exports(from)
+	if !ok {
+		return fmt.Errorf("module %s does not exist", from)
+	}
+	exported := false
+	for _, e := range exports {
+		exported = exported || e.String() == s.String()
+	}
+	if !exported {
+		return fmt.Errorf("module %s does not export %s", from, s)
+	}
+	mod := d.module(into)
+	if other, ok := mod.imports[s.String()]; ok && other != from {
+		return fmt.Errorf("%s is already imported into %s from %s", s, into, other)
+	}
+	mod.imports[s.String()] = from
+	return nil
+}
+
+// SetMetaPredicate records which arguments of a predicate defined in the module are goals
+func (d *Default) SetMetaPredicate(m string, spec *ast.Fact) {
+	d.module(m).meta[spec.Signature().String()] = spec
+}
+
+// MetaPredicate returns the meta_predicate/1 spec of a predicate defined in the module
+func (d *Default) MetaPredicate(m string, s *ast.Signature) (*ast.Fact, bool) {
+	mod, ok := d.modules[m]
+	if !ok {
+		return nil, false
+	}
+	spec, ok := mod.meta[s.String()]
+	return spec, ok
+}
+
+// module returns the module with the name, creating it if it hasnt been seen yet
+func (d *Default) module(name string) *module {
+	mod, ok := d.modules[name]
+	if !ok {
+		mod = &module{imports: make(map[string]string), meta: make(map[string]*ast.Fact)}
+		d.modules[name] = mod
+	}
+	return mod
+}
+
+// Statements returns every indexed clause in the order they were indexed
+func (d *Default) Statements() []ast.Statement {
+	return d.all
+}
+
+// Clauses returns every clause indexed in the module in the order they were indexed
+func (d *Default) Clauses(m string) []ast.Statement {
+	statements := []ast.Statement{}
+	for i, s := range d.all {
+		if d.allIn[i] == m {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+// Materialize replaces the clauses of a predicate in the User module with the facts
+func (d *Default) Materialize(s *ast.Signature, facts []*ast.Fact) {
+	statements := make([]ast.Statement, len(facts))
+	for i, f := range facts {
+		statements[i] = f
+	}
+	d.bySig[key(User, s)] = statements
+}
+
+func (d *Default) rememberNames(mappings map[string]string) {
+	for original, renamed := range mappings {
+		d.names[renamed] = original
+	}
+}
+
+// VariableName returns the name a variable had in the source before it was indexed
+func (d *Default) VariableName(indexed string) (string, bool) {
+	name, ok := d.names[indexed]
+	return name, ok
 }
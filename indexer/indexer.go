@@ -8,3 +8,53 @@ type Indexer interface {
 	IndexStatement(ast.Statement)
 	StatementsForSignature(*ast.Signature) []ast.Statement
 }
+
+// VariableNamer is implemented by indexers which can map the variable names in indexed clauses back to the source
+type VariableNamer interface {
+	VariableName(indexed string) (string, bool)
+}
+
+// Lister is implemented by indexers which can list every clause they hold, in the order they were indexed
+type Lister interface {
+	Statements() []ast.Statement
+}
+
+/**
+ * Materializer is implemented by indexers which can replace the clauses of a predicate with the facts computed from
+ * them, see datalog.Evaluate. Goals in User find the facts from then on but the clauses are still listed.
+ */
+type Materializer interface {
+	// Clauses returns every clause indexed in the module in the order they were indexed
+	Clauses(module string) []ast.Statement
+	// Materialize replaces the clauses a goal in User finds for the predicate with the facts
+	Materialize(s *ast.Signature, facts []*ast.Fact)
+}
+
+const (
+	// User is the module clauses go into unless the source they come from declares a module
+	User = "user"
+	// System is the module holding the bundled library, every module can call its predicates
+	System = "system"
+)
+
+/**
+ * Modules is implemented by indexers which keep the predicates of each module apart.
+ * A goal called in a module is looked up in the module itself, then in the predicates it imports,
+ * then in User (unless it is User) and finally in System.
+ */
+type Modules interface {
+	// IndexModuleStatement indexes a clause in the module, IndexStatement indexes into User
+	IndexModuleStatement(module string, s ast.Statement)
+	// ModuleStatements returns the clauses a goal called in the module resolves to along with the module that defines them
+	ModuleStatements(module string, s *ast.Signature) (string, []ast.Statement)
+	// DefineModule declares a module, the file it is loaded from and the predicates it exports
+	DefineModule(name string, file string, exports []*ast.Signature) error
+	// Exports returns the predicates a module exports, ok is false if the module hasnt been defined
+	Exports(module string) (exports []*ast.Signature, ok bool)
+	// Import makes a predicate exported by one module callable from another without qualifying it
+	Import(into string, from string, s *ast.Signature) error
+	// SetMetaPredicate records which arguments of a predicate defined in the module are goals, see meta_predicate/1
+	SetMetaPredicate(module string, spec *ast.Fact)
+	// MetaPredicate returns the meta_predicate/1 spec of a predicate defined in the module
+	MetaPredicate(module string, s *ast.Signature) (*ast.Fact, bool)
+}
@@ -1,8 +1,11 @@
-// Package lexer is generated by GoGLL. Do not edit.
+/**
+ * Package lexer splits source text into the tokens read by the syntax package.
+ * It handles the lexical syntax of prolog: comments, quotes with escapes, symbol atoms, numbers and character codes.
+ */
 package lexer
 
 import (
-	// "fmt"
+	"fmt"
 	"io/ioutil"
 	"strings"
 	"unicode"
@@ -10,10 +13,6 @@ import (
 	"github.com/kkoch986/gopl/token"
 )
 
-type state int
-
-const nullState state = -1
-
 // Lexer contains both the input slice of runes and the slice of tokens
 // parsed from the input
 type Lexer struct {
@@ -32,17 +31,19 @@ code blocks as whitespace. All text inside code blocks are treated as input text
 
 If the input file is a normal text file NewFile treats all text in the inputfile
 as input text.
+
+An error is returned if the file cant be read.
 */
-func NewFile(fname string) *Lexer {
+func NewFile(fname string) (*Lexer, error) {
 	buf, err := ioutil.ReadFile(fname)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	input := []rune(string(buf))
 	if strings.HasSuffix(fname, ".md") {
 		loadMd(input)
 	}
-	return New(input)
+	return New(input), nil
 }
 
 func loadMd(input []rune) {
@@ -55,6 +56,11 @@ func loadMd(input []rune) {
 				input[i+j] = ' '
 			}
 			i += 3
+			// the info string after an opening fence, i.e. the prolog in ```prolog, isnt source either
+			for !text && i < len(input) && input[i] != '\n' {
+				input[i] = ' '
+				i++
+			}
 		}
 		if i < len(input) {
 			if text {
@@ -79,56 +85,353 @@ func New(input []rune) *Lexer {
 		I:      input,
 		Tokens: make([]*token.Token, 0, 2048),
 	}
-	lext := 0
+	lext := lex.skipLayout(0)
 	for lext < len(lex.I) {
-		for lext < len(lex.I) && unicode.IsSpace(lex.I[lext]) {
-			lext++
-		}
-		if lext < len(lex.I) {
-			tok := lex.scan(lext)
-			lext = tok.Rext()
-			if !tok.Suppress() {
-				lex.addToken(tok)
-			}
-		}
+		tok := lex.scan(lext)
+		lex.addToken(tok)
+		lext = lex.skipLayout(tok.Rext())
 	}
 	lex.add(token.EOF, len(input), len(input))
+	lex.operatorAtoms()
 	return lex
 }
 
+// symbolChars are the characters symbol atoms like `=..` and `\+` are made from
+const symbolChars = "+-*/\\^<>=~:.?@#&$"
+
+// punctuation maps the symbol atoms which are tokens of the grammar in their own right to their type
+var punctuation = map[string]token.Type{
+	"*":  token.Star,
+	"+":  token.Plus,
+	"-":  token.Minus,
+	"/":  token.Slash,
+	":-": token.Neck,
+	"?-": token.QueryPrefix,
+	"=":  token.InfixOperator,
+}
+
+// skipLayout returns the index of the next token at or after i, skipping whitespace and comments
+func (l *Lexer) skipLayout(i int) int {
+	for i < len(l.I) {
+		switch {
+		case unicode.IsSpace(l.I[i]):
+			i++
+		case l.I[i] == '%':
+			for i < len(l.I) && l.I[i] != '\n' {
+				i++
+			}
+		case l.at(i, "/*"):
+			j := i + 2
+			for j < len(l.I) && !l.at(j, "*/") {
+				j++
+			}
+			if j >= len(l.I) {
+				// an unterminated comment is left for scan to report
+				return i
+			}
+			i = j + 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
 func (l *Lexer) scan(i int) *token.Token {
-	// fmt.Printf("lexer.scan\n")
-	s, typ, rext := state(0), token.Error, i
-	for s != nullState {
-		// fmt.Printf("S%d '%c' @ %d\n", s, l.I[rext], rext)
-		if rext >= len(l.I) {
-			typ = accept[s]
-			s = nullState
+	r := l.I[i]
+	switch {
+	case l.at(i, "()"):
+		return l.token(token.EmptyParens, i, i+2)
+	case r == '(':
+		return l.token(token.OpenParen, i, i+1)
+	case r == ')':
+		return l.token(token.CloseParen, i, i+1)
+	case r == ',':
+		return l.token(token.Comma, i, i+1)
+	case r == '|':
+		return l.token(token.Bar, i, i+1)
+	case l.at(i, "[]"):
+		return l.token(token.EmptyList, i, i+2)
+	case r == '[':
+		return l.token(token.OpenList, i, i+1)
+	case r == ']':
+		return l.token(token.CloseList, i, i+1)
+	case l.at(i, "{}"):
+		return l.token(token.Atom, i, i+2)
+	case r == '{' || r == '}':
+		// there are no token types for curly brackets, the reader tells them apart from quoted atoms by their literal
+		return l.token(token.Atom, i, i+1)
+	case r == '!' || r == ';':
+		// the solo characters are atoms on their own
+		return l.token(token.Atom, i, i+1)
+	case r == '\'':
+		return l.quoted(i, token.Atom)
+	case r == '"' || r == '`':
+		return l.quoted(i, token.String)
+	case r == '_' || unicode.IsUpper(r):
+		return l.token(token.Var, i, l.word(i))
+	case unicode.IsLower(r):
+		end := l.word(i)
+		if string(l.I[i:end]) == "is" {
+			return l.token(token.Is, i, end)
+		}
+		return l.token(token.Atom, i, end)
+	case isDigit(r):
+		return l.token(token.Number, i, l.number(i))
+	case r == '-' && i+1 < len(l.I) && isDigit(l.I[i+1]) && !l.afterOperand():
+		// a `-` straight after an operand is subtraction, anywhere else it is the sign of the number
+		return l.token(token.Number, i, l.number(i+1))
+	case l.at(i, "/*"):
+		return l.token(token.Error, i, len(l.I))
+	case strings.ContainsRune(symbolChars, r):
+		return l.symbol(i)
+	}
+	return l.token(token.Error, i, i+1)
+}
+
+// word returns the end of the letters, digits and underscores starting at i
+func (l *Lexer) word(i int) int {
+	for i < len(l.I) && (l.I[i] == '_' || unicode.IsLetter(l.I[i]) || unicode.IsNumber(l.I[i])) {
+		i++
+	}
+	return i
+}
+
+/**
+ * number returns the end of the number starting at i. Numbers are integers, `0'c` character codes,
+ * `0x`, `0o` and `0b` integers or floats like `1.5`, `1.5e10` and `1.0E-3`.
+ * A `.` is only part of a number when there is a digit after it, so `X = 2.` ends the clause.
+ */
+func (l *Lexer) number(i int) int {
+	if l.at(i, "0'") {
+		if n := l.charCode(i + 2); n > 0 {
+			return i + 2 + n
+		}
+	}
+	for _, base := range []struct {
+		prefix string
+		digits string
+	}{{"0x", "0123456789abcdefABCDEF"}, {"0o", "01234567"}, {"0b", "01"}} {
+		if l.at(i, base.prefix) && i+2 < len(l.I) && strings.ContainsRune(base.digits, l.I[i+2]) {
+			j := i + 2
+			for j < len(l.I) && strings.ContainsRune(base.digits, l.I[j]) {
+				j++
+			}
+			return j
+		}
+	}
+
+	j := l.digits(i)
+	if j+1 < len(l.I) && l.I[j] == '.' && isDigit(l.I[j+1]) {
+		j = l.digits(j + 1)
+	}
+	if j < len(l.I) && (l.I[j] == 'e' || l.I[j] == 'E') {
+		k := j + 1
+		if k < len(l.I) && (l.I[k] == '+' || l.I[k] == '-') {
+			k++
+		}
+		if k < len(l.I) && isDigit(l.I[k]) {
+			j = l.digits(k)
+		}
+	}
+	return j
+}
+
+func (l *Lexer) digits(i int) int {
+	for i < len(l.I) && isDigit(l.I[i]) {
+		i++
+	}
+	return i
+}
+
+// charCode returns the length of the character after `0'`, 0 if there isnt one
+func (l *Lexer) charCode(i int) int {
+	switch {
+	case i >= len(l.I) || l.I[i] == '\n':
+		return 0
+	case l.I[i] == '\\':
+		return escapeLength(l.I, i)
+	case l.at(i, "''"):
+		return 2
+	}
+	return 1
+}
+
+/**
+ * quoted scans a quoted atom or string starting at i. The quote is written twice or escaped to include it,
+ * an unterminated quote or an unknown escape is an error which runs to the end of the line.
+ */
+func (l *Lexer) quoted(i int, typ token.Type) *token.Token {
+	q := l.I[i]
+	j := i + 1
+	for j < len(l.I) {
+		switch l.I[j] {
+		case q:
+			if j+1 < len(l.I) && l.I[j+1] == q {
+				j += 2
+				continue
+			}
+			return l.token(typ, i, j+1)
+		case '\\':
+			n := escapeLength(l.I, j)
+			if n == 0 {
+				return l.token(token.Error, i, l.endOfLine(j))
+			}
+			j += n
+		default:
+			j++
+		}
+	}
+	return l.token(token.Error, i, l.endOfLine(i))
+}
+
+// ErrorMessage explains why the text of an Error token couldnt be made into a token
+func ErrorMessage(t *token.Token) string {
+	l := &Lexer{I: t.GetInput()}
+	i := t.Lext()
+	if i >= len(l.I) {
+		return "unexpected end of input"
+	}
+	r := l.I[i]
+	switch {
+	case l.at(i, "/*"):
+		return "unterminated block comment"
+	case r == '\'' || r == '"' || r == '`':
+		kind := map[rune]string{'\'': "quoted atom", '"': "string", '`': "back quoted string"}[r]
+		for j := i + 1; j < len(l.I); j++ {
+			if l.I[j] == r {
+				if !l.at(j+1, string(r)) {
+					break
+				}
+				j++
+				continue
+			} else if l.I[j] != '\\' {
+				continue
+			}
+			if escapeLength(l.I, j) == 0 {
+				return fmt.Sprintf("unknown escape sequence in %s", kind)
+			}
+			j += escapeLength(l.I, j) - 1
+		}
+		return "unterminated " + kind
+	}
+	return fmt.Sprintf("unexpected character %q", r)
+}
+
+/**
+ * escapeLength returns the length of the escape sequence starting with the `\` at i, 0 if it isnt valid.
+ * The escapes are the ISO ones: `\n`, `\t` and the other control characters, `\\` and the quotes,
+ * `\xHH\` and `\OOO\` character codes and a `\` at the end of a line which continues on the next one.
+ */
+func escapeLength(input []rune, i int) int {
+	if i+1 >= len(input) {
+		return 0
+	}
+	r := input[i+1]
+	switch {
+	case strings.ContainsRune("abfnrtvse0\\'\"`\n", r):
+		return 2
+	case r == 'x' || isOctal(r):
+		j := i + 2
+		if r == 'x' {
+			for j < len(input) && unicode.Is(unicode.ASCII_Hex_Digit, input[j]) {
+				j++
+			}
 		} else {
-			typ = accept[s]
-			s = nextState[s](l.I[rext])
-			if s != nullState || typ == token.Error {
-				rext++
+			for j < len(input) && isOctal(input[j]) {
+				j++
 			}
 		}
+		if j > i+2 && j < len(input) && input[j] == '\\' {
+			return j + 1 - i
+		}
 	}
-	return token.New(typ, i, rext, l.I)
+	return 0
 }
 
-func escape(r rune) string {
-	switch r {
-	case '"':
-		return "\""
-	case '\\':
-		return "\\\\"
-	case '\r':
-		return "\\r"
-	case '\n':
-		return "\\n"
-	case '\t':
-		return "\\t"
+// symbol scans a run of symbol characters, the runs which are tokens of the grammar like `:-` keep their own type
+func (l *Lexer) symbol(i int) *token.Token {
+	// a `.` only ends the clause when it is followed by whitespace, a comment or the end of the input, anywhere else it is an atom
+	if l.I[i] == '.' && (i+1 == len(l.I) || unicode.IsSpace(l.I[i+1]) || l.I[i+1] == '%') {
+		return l.token(token.End, i, i+1)
+	}
+	j := i
+	for j < len(l.I) && strings.ContainsRune(symbolChars, l.I[j]) && (j == i || !l.at(j, "/*")) {
+		j++
+	}
+	if typ, ok := punctuation[string(l.I[i:j])]; ok {
+		return l.token(typ, i, j)
+	}
+	return l.token(token.Atom, i, j)
+}
+
+// afterOperand reports whether the last token could be the left hand side of an operator
+func (l *Lexer) afterOperand() bool {
+	if len(l.Tokens) == 0 {
+		return false
+	}
+	last := l.Tokens[len(l.Tokens)-1]
+	switch last.Type() {
+	case token.Atom:
+		return last.LiteralString() != "{"
+	case token.EmptyParens, token.CloseParen, token.EmptyList, token.CloseList, token.Number, token.String, token.Var:
+		return true
+	}
+	return false
+}
+
+/**
+ * operatorAtoms turns an operator which is an argument on its own, like the `+` in `foo(+, [-])`, into an atom.
+ * Otherwise they are the tokens of the operators in the grammar which would always need quotes to be used as atoms.
+ */
+func (l *Lexer) operatorAtoms() {
+	for i := 1; i < len(l.Tokens)-1; i++ {
+		switch l.Tokens[i].Type() {
+		case token.Star, token.Plus, token.Minus, token.Slash, token.InfixOperator:
+		default:
+			continue
+		}
+		switch l.Tokens[i-1].Type() {
+		case token.OpenParen, token.Comma, token.OpenList, token.Bar:
+		default:
+			continue
+		}
+		switch l.Tokens[i+1].Type() {
+		case token.CloseParen, token.Comma, token.CloseList, token.Bar:
+			t := l.Tokens[i]
+			l.Tokens[i] = l.token(token.Atom, t.Lext(), t.Rext())
+		}
 	}
-	return string(r)
+}
+
+// at reports whether the input at i starts with s
+func (l *Lexer) at(i int, s string) bool {
+	for _, r := range s {
+		if i >= len(l.I) || l.I[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func (l *Lexer) endOfLine(i int) int {
+	for i < len(l.I) && l.I[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+func (l *Lexer) token(t token.Type, lext, rext int) *token.Token {
+	return token.New(t, lext, rext, l.I)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isOctal(r rune) bool {
+	return r >= '0' && r <= '7'
 }
 
 // GetLineColumn returns the line and column of rune[i] in the input
@@ -166,313 +469,3 @@ func (l *Lexer) add(t token.Type, lext, rext int) {
 func (l *Lexer) addToken(tok *token.Token) {
 	l.Tokens = append(l.Tokens, tok)
 }
-
-func any(r rune, set []rune) bool {
-	for _, r1 := range set {
-		if r == r1 {
-			return true
-		}
-	}
-	return false
-}
-
-func not(r rune, set []rune) bool {
-	for _, r1 := range set {
-		if r == r1 {
-			return false
-		}
-	}
-	return true
-}
-
-var accept = []token.Type{
-	token.Error,
-	token.Error,
-	token.T_0,
-	token.T_2,
-	token.T_3,
-	token.T_4,
-	token.T_5,
-	token.T_6,
-	token.T_7,
-	token.T_8,
-	token.Error,
-	token.T_15,
-	token.Error,
-	token.T_11,
-	token.T_13,
-	token.T_19,
-	token.T_14,
-	token.T_20,
-	token.T_17,
-	token.T_14,
-	token.T_18,
-	token.Error,
-	token.T_1,
-	token.T_9,
-	token.T_10,
-	token.T_12,
-	token.T_16,
-	token.T_17,
-}
-
-var nextState = []func(r rune) state{
-	// Set0
-	func(r rune) state {
-		switch {
-		case r == '"':
-			return 1
-		case r == '(':
-			return 2
-		case r == ')':
-			return 3
-		case r == '*':
-			return 4
-		case r == '+':
-			return 5
-		case r == ',':
-			return 6
-		case r == '-':
-			return 7
-		case r == '.':
-			return 8
-		case r == '/':
-			return 9
-		case r == ':':
-			return 10
-		case r == '=':
-			return 11
-		case r == '?':
-			return 12
-		case r == '[':
-			return 13
-		case r == ']':
-			return 14
-		case r == '_':
-			return 15
-		case r == 'i':
-			return 16
-		case r == '|':
-			return 17
-		case unicode.IsNumber(r):
-			return 18
-		case unicode.IsUpper(r):
-			return 15
-		case unicode.IsLower(r):
-			return 19
-		}
-		return nullState
-	},
-	// Set1
-	func(r rune) state {
-		switch {
-		case r == '"':
-			return 20
-		case r == '\\':
-			return 21
-		case not(r, []rune{'"', '\\'}):
-			return 1
-		}
-		return nullState
-	},
-	// Set2
-	func(r rune) state {
-		switch {
-		case r == ')':
-			return 22
-		}
-		return nullState
-	},
-	// Set3
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set4
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set5
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set6
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set7
-	func(r rune) state {
-		switch {
-		case unicode.IsNumber(r):
-			return 18
-		}
-		return nullState
-	},
-	// Set8
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set9
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set10
-	func(r rune) state {
-		switch {
-		case r == '-':
-			return 23
-		}
-		return nullState
-	},
-	// Set11
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set12
-	func(r rune) state {
-		switch {
-		case r == '-':
-			return 24
-		}
-		return nullState
-	},
-	// Set13
-	func(r rune) state {
-		switch {
-		case r == ']':
-			return 25
-		}
-		return nullState
-	},
-	// Set14
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set15
-	func(r rune) state {
-		switch {
-		case r == '_':
-			return 15
-		case unicode.IsLetter(r):
-			return 15
-		case unicode.IsNumber(r):
-			return 15
-		}
-		return nullState
-	},
-	// Set16
-	func(r rune) state {
-		switch {
-		case r == '_':
-			return 19
-		case r == 's':
-			return 26
-		case unicode.IsLetter(r):
-			return 19
-		case unicode.IsNumber(r):
-			return 19
-		}
-		return nullState
-	},
-	// Set17
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set18
-	func(r rune) state {
-		switch {
-		case r == '.':
-			return 27
-		case unicode.IsNumber(r):
-			return 18
-		}
-		return nullState
-	},
-	// Set19
-	func(r rune) state {
-		switch {
-		case r == '_':
-			return 19
-		case unicode.IsLetter(r):
-			return 19
-		case unicode.IsNumber(r):
-			return 19
-		}
-		return nullState
-	},
-	// Set20
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set21
-	func(r rune) state {
-		switch {
-		case any(r, []rune{'"', '\\', 'n', 'r', 't'}):
-			return 1
-		}
-		return nullState
-	},
-	// Set22
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set23
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set24
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set25
-	func(r rune) state {
-		switch {
-		}
-		return nullState
-	},
-	// Set26
-	func(r rune) state {
-		switch {
-		case r == '_':
-			return 19
-		case unicode.IsLetter(r):
-			return 19
-		case unicode.IsNumber(r):
-			return 19
-		}
-		return nullState
-	},
-	// Set27
-	func(r rune) state {
-		switch {
-		case unicode.IsNumber(r):
-			return 27
-		}
-		return nullState
-	},
-}
@@ -0,0 +1,168 @@
+package lexer_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/lexer"
+	"github.com/kkoch986/gopl/token"
+)
+
+// tokens returns the tokens of src as `Type:literal`, leaving out the EOF
+func tokens(src string) string {
+	l := lexer.New([]rune(src))
+	out := []string{}
+	for _, t := range l.Tokens[:len(l.Tokens)-1] {
+		out = append(out, t.Type().String()+":"+t.LiteralString())
+	}
+	return strings.Join(out, " ")
+}
+
+func TestLexer(t *testing.T) {
+	for _, c := range []struct {
+		label, src, expected string
+	}{
+		{"line comments", "a. % the rest\n% a whole line\nb.", "Atom:a End:. Atom:b End:."},
+		{"block comments", "/* before */ a /* in\nthe */(b). /**/", "Atom:a OpenParen:( Atom:b CloseParen:) End:."},
+		{"comment after the end", "a.% done", "Atom:a End:."},
+		{"quoted atoms", `'Hello World' 'it''s' 'a\nb' '\x41\\101\'`, `Atom:'Hello World' Atom:'it''s' Atom:'a\nb' Atom:'\x41\\101\'`},
+		{"continued quote", "'a\\\nb'", "Atom:'a\\\nb'"},
+		{"symbol atoms", `=.. \+ @>= --> :- ?- = *`, `Atom:=.. Atom:\+ Atom:@>= Atom:--> Neck::- QueryPrefix:?- InfixOperator:= Star:*`},
+		{"solo atoms", "! ; [] {} {a}", "Atom:! Atom:; EmptyList:[] Atom:{} Atom:{ Atom:a Atom:}"},
+		{"operators as arguments", "f(+, [-|*], /)", "Atom:f OpenParen:( Atom:+ Comma:, OpenList:[ Atom:- Bar:| Atom:* CloseList:] Comma:, Atom:/ CloseParen:)"},
+		{"variables", "X _ _foo Foo_1", "Var:X Var:_ Var:_foo Var:Foo_1"},
+		{"is", "X is Y, isnt", "Var:X Is:is Var:Y Comma:, Atom:isnt"},
+		{"character codes", `0'a 0'' 0'\n 0' `, `Number:0'a Number:0'' Number:0'\n Number:0' `},
+		{"based integers", "0x1F 0o17 0b101 0b2", "Number:0x1F Number:0o17 Number:0b101 Number:0 Atom:b2"},
+		{"floats", "1.5 1.5e3 1.0E-2 2e+1 3e", "Number:1.5 Number:1.5e3 Number:1.0E-2 Number:2e+1 Number:3 Atom:e"},
+		{"end token", "X = 2. Y = a.b", "Var:X InfixOperator:= Number:2 End:. Var:Y InfixOperator:= Atom:a Atom:. Atom:b"},
+		{"negative numbers", "X is Y-1, Z = -1, f(-2)", "Var:X Is:is Var:Y Minus:- Number:1 Comma:, Var:Z InfixOperator:= Number:-1 Comma:, Atom:f OpenParen:( Number:-2 CloseParen:)"},
+		{"strings", "\"say \"\"hi\"\"\" `codes`", "String:\"say \"\"hi\"\"\" String:`codes`"},
+		{"empty parens", "a() :- b ()", "Atom:a EmptyParens:() Neck::- Atom:b EmptyParens:()"},
+		{"errors", "a(\"\\q\"). x\n'open\nb. /* never closed", "Atom:a OpenParen:( Error:\"\\q\"). x Error:'open Atom:b End:. Error:/* never closed"},
+	} {
+		if actual := tokens(c.src); actual != c.expected {
+			t.Errorf("%s: expected\n  %s\ngot\n  %s", c.label, c.expected, actual)
+		}
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	for src, expected := range map[string]string{
+		"/* open": "unterminated block comment",
+		"'open":   "unterminated quoted atom",
+		`"a\qb"`:  "unknown escape sequence in string",
+		"`open":   "unterminated back quoted string",
+		"\u00a7":  "unexpected character '\u00a7'",
+	} {
+		l := lexer.New([]rune(src))
+		if tok := l.Tokens[0]; tok.Type() != token.Error {
+			t.Errorf("%s: expected an error token, got %s", src, tok)
+		} else if actual := lexer.ErrorMessage(tok); actual != expected {
+			t.Errorf("%s: expected %q, got %q", src, expected, actual)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for lit, expected := range map[string]string{
+		"atom":                   "atom",
+		"'Hello World'":          "Hello World",
+		"'it''s'":                "it's",
+		`"say ""hi"""`:           `say "hi"`,
+		`'\a\b\f\n\r\t\v\s\e\0'`: "\a\b\f\n\r\t\v \x1b\x00",
+		"'\\\\\\'\\\"\\`'":       "\\'\"`",
+		`'\x41\\101\'`:           "AA",
+		"'a\\\nb'":               "ab",
+		"`codes`":                "codes",
+	} {
+		if actual := lexer.Unquote(lit); actual != expected {
+			t.Errorf("%s: expected %q, got %q", lit, expected, actual)
+		}
+	}
+}
+
+func TestNumberValue(t *testing.T) {
+	for lit, expected := range map[string]float64{
+		"12":     12,
+		"-3":     -3,
+		"1.5":    1.5,
+		"1.5e3":  1500,
+		"1.0E-2": 0.01,
+		"0'a":    97,
+		"0''":    39,
+		"0'\\n":  10,
+		"0x1F":   31,
+		"-0x10":  -16,
+		"0o17":   15,
+		"0b101":  5,
+	} {
+		if actual := lexer.NumberValue(lit); actual != expected {
+			t.Errorf("%s: expected %v, got %v", lit, expected, actual)
+		}
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	dir := t.TempDir()
+	md := filepath.Join(dir, "notes.md")
+	src := "# Notes\n\nfoo(bar).\n\n```prolog\nbaz(qux).\n```\n"
+	if err := os.WriteFile(md, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := lexer.NewFile(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the code block is left, the rest of the markdown is blanked out keeping the lines where they were
+	actual := []string{}
+	for i, tok := range l.Tokens[:len(l.Tokens)-1] {
+		line, col := l.GetLineColumnOfToken(i)
+		actual = append(actual, fmt.Sprintf("%s@%d:%d", tok.LiteralString(), line, col))
+	}
+	if strings.Join(actual, " ") != "baz@6:1 (@6:4 qux@6:5 )@6:8 .@6:9" {
+		t.Errorf("expected only the code block to be read, got %v", actual)
+	}
+
+	if _, err := lexer.NewFile(filepath.Join(dir, "missing.pl")); !os.IsNotExist(err) {
+		t.Errorf("expected a missing file to be an error, got %v", err)
+	}
+}
+
+/**
+ * FuzzLexer runs arbitrary source through the lexer and the literal helpers the reader uses on its tokens.
+ * None of it should panic and the tokens should cover the input in order, ending with a single EOF.
+ */
+func FuzzLexer(f *testing.F) {
+	for _, seed := range []string{
+		"parent(tom, bob).",
+		"% comment\n/* block */ a('q''s\\n', `bq`, 0'c, 0x1F, 1.5e-3, =.., [+]).",
+		"a('\\x41\\', \"\\q\"). /* open",
+		"X is Y-1, Z = -1, 0'\\n, 0'', 0b2.",
+		"'unterminated\n\"also",
+		"```prolog\na.\n```",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		l := lexer.New([]rune(src))
+		last := 0
+		for i, tok := range l.Tokens {
+			if tok.Lext() < last || tok.Rext() < tok.Lext() || tok.Rext() > len(l.I) {
+				t.Fatalf("token %d (%s) at %d-%d is out of order", i, tok, tok.Lext(), tok.Rext())
+			}
+			if (tok.Type() == token.EOF) != (i == len(l.Tokens)-1) {
+				t.Fatalf("expected a single EOF at the end, got %s at %d", tok, i)
+			}
+			last = tok.Rext()
+			lit := tok.LiteralString()
+			_ = lexer.Unquote(lit)
+			_ = lexer.NumberValue(lit)
+			_ = lexer.ErrorMessage(tok)
+			_, _ = l.GetLineColumnOfToken(i)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+ * Unquote returns the text of a quoted atom or string with its escapes and doubled quotes replaced.
+ * Text which isnt quoted, i.e. a plain or symbol atom, is returned as it is.
+ */
+func Unquote(lit string) string {
+	if len(lit) < 2 || !strings.ContainsRune("'\"`", rune(lit[0])) {
+		return lit
+	}
+	q := lit[0]
+	s := lit[1 : len(lit)-1]
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == q && i+1 < len(s) && s[i+1] == q {
+			sb.WriteByte(q)
+			i++
+			continue
+		}
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		r, n := unescape(s[i+1:])
+		if n > 0 {
+			sb.WriteString(r)
+		}
+		i += n
+	}
+	return sb.String()
+}
+
+var escapes = map[byte]string{
+	'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+	's': " ", 'e': "\x1b", '0': "\x00", '\\': "\\", '\'': "'", '"': "\"", '`': "`",
+	// a `\` at the end of a line continues the text on the next line
+	'\n': "",
+}
+
+// unescape returns the text of the escape sequence at the start of s, which follows a `\`, and how much of s it used
+func unescape(s string) (string, int) {
+	if s[0] == 'x' || (s[0] >= '0' && s[0] <= '7') {
+		base, start := 8, 0
+		if s[0] == 'x' {
+			base, start = 16, 1
+		}
+		if end := strings.IndexByte(s, '\\'); end > start {
+			if code, err := strconv.ParseInt(s[start:end], base, 32); err == nil {
+				return string(rune(code)), end + 1
+			}
+		}
+	}
+	if r, ok := escapes[s[0]]; ok {
+		return r, 1
+	}
+	// unknown escapes are kept as they are, the lexer doesnt allow them in source
+	return "\\", 0
+}
+
+/**
+ * NumberValue returns the value of a number as it is written in source, which can be a `0'c` character code,
+ * a `0x`, `0o` or `0b` integer or a float with an exponent.
+ */
+func NumberValue(lit string) float64 {
+	sign, s := 1.0, lit
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+	if strings.HasPrefix(s, "0'") {
+		r, _ := utf8.DecodeRuneInString(Unquote("'" + s[2:] + "'"))
+		return sign * float64(r)
+	}
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(s, prefix) {
+			n, _ := strconv.ParseInt(s[2:], base, 64)
+			return sign * float64(n)
+		}
+	}
+	n, _ := strconv.ParseFloat(s, 64)
+	return sign * n
+}
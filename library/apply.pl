@@ -0,0 +1,23 @@
+:- meta_predicate maplist(1, ?), maplist(2, ?, ?), maplist(3, ?, ?, ?), maplist(4, ?, ?, ?, ?).
+:- meta_predicate foldl(3, ?, ?, ?), foldl(4, ?, ?, ?, ?), foldl(5, ?, ?, ?, ?, ?).
+
+maplist(G, []).
+maplist(G, [X|Xs]) :- call(G, X), maplist(G, Xs).
+
+maplist(G, [], []).
+maplist(G, [X|Xs], [Y|Ys]) :- call(G, X, Y), maplist(G, Xs, Ys).
+
+maplist(G, [], [], []).
+maplist(G, [X|Xs], [Y|Ys], [Z|Zs]) :- call(G, X, Y, Z), maplist(G, Xs, Ys, Zs).
+
+maplist(G, [], [], [], []).
+maplist(G, [X|Xs], [Y|Ys], [Z|Zs], [W|Ws]) :- call(G, X, Y, Z, W), maplist(G, Xs, Ys, Zs, Ws).
+
+foldl(G, [], V, V).
+foldl(G, [X|Xs], V0, V) :- call(G, X, V0, V1), foldl(G, Xs, V1, V).
+
+foldl(G, [], [], V, V).
+foldl(G, [X|Xs], [Y|Ys], V0, V) :- call(G, X, Y, V0, V1), foldl(G, Xs, Ys, V1, V).
+
+foldl(G, [], [], [], V, V).
+foldl(G, [X|Xs], [Y|Ys], [Z|Zs], V0, V) :- call(G, X, Y, Z, V0, V1), foldl(G, Xs, Ys, Zs, V1, V).
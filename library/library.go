@@ -0,0 +1,97 @@
+// Package library contains the standard library which is bundled into the gopl binary.
+//
+// The library is split into a few files:
+//   - lists.pl: append/3, member/2, reverse/2, last/2
+//   - apply.pl: maplist/2..5, foldl/4..6
+//   - pairs.pl: pairs_keys_values/3, pairs_keys/2, pairs_values/2
+//
+// Predicates which need to be fast (length/2, sort/2 etc..) are implemented natively in the resolver.
+package library
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+//go:embed *.pl
+var sources embed.FS
+
+// Files returns the names of all of the bundled library files
+func Files() []string {
+	entries, err := sources.ReadDir(".")
+	if err != nil {
+		// this can only happen if the embed directive is broken
+		panic(err)
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the contents of one of the bundled library files
+func Source(name string) (string, error) {
+	b, err := sources.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+/**
+ * Load parses all of the bundled library files and indexes their statements.
+ * When the indexer has modules they go into the System module, along with their meta_predicate/1 declarations.
+ */
+func Load(i indexer.Indexer) error {
+	modules, _ := i.(indexer.Modules)
+	for _, name := range Files() {
+		src, err := Source(name)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG][Library] Loading %s", name)
+		// the library isnt given a file so its clauses arent part of coverage reports
+		statements, err := syntax.Parse([]rune(src), "")
+		if err != nil {
+			return fmt.Errorf("unable to parse library file %s:\n%s", name, err)
+		}
+
+		for _, s := range statements {
+			if modules == nil {
+				i.IndexStatement(s)
+				continue
+			}
+			if q, ok := s.(*ast.Query); ok {
+				metaPredicates(modules, *q)
+				continue
+			}
+			modules.IndexModuleStatement(indexer.System, s)
+		}
+	}
+	return nil
+}
+
+// metaPredicates records the specs in the meta_predicate/1 directives of a library file
+func metaPredicates(modules indexer.Modules, q ast.Query) {
+	for _, goal := range q {
+		f, ok := goal.(*ast.Fact)
+		if !ok || f.Head != "meta_predicate" || len(f.Args) != 1 {
+			continue
+		}
+		for _, spec := range ast.Conjuncts(f.Args[0]) {
+			if sf, ok := spec.(*ast.Fact); ok {
+				modules.SetMetaPredicate(indexer.System, sf)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package library_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/library"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+type libraryTestCase struct {
+	Query string
+	// Expected maps a variable name to the string form of its grounded value, one entry per solution
+	Expected []map[string]string
+}
+
+func parse(t *testing.T, src string) []ast.Statement {
+	statements, err := syntax.Parse([]rune(src), "")
+	if err != nil {
+		t.Fatalf("unable to parse %s: %s", src, err)
+	}
+	return statements
+}
+
+func runLibraryTestCase(t *testing.T, program string, c libraryTestCase) {
+	i := indexer.NewDefault()
+	if err := library.Load(i); err != nil {
+		t.Fatal(err)
+	}
+	if program != "" {
+		for _, s := range parse(t, program) {
+			i.IndexStatement(s)
+		}
+	}
+
+	r := resolver.New(i)
+	out := make(chan *resolver.Bindings)
+	go r.ResolveStatementList(context.Background(), parse(t, "?- "+c.Query), resolver.EmptyBindings(), out)
+
+	results := []*resolver.Bindings{}
+	for b := range out {
+		results = append(results, b)
+	}
+
+	if len(results) != len(c.Expected) {
+		t.Fatalf("%s: expected %d solutions, got %d (%v)", c.Query, len(c.Expected), len(results), results)
+	}
+	for n, expected := range c.Expected {
+		for k, v := range expected {
+			got := results[n].Ground(ast.CreateVariable(k)).String()
+			if got != v {
+				t.Errorf("%s: solution %d expected %s = %s, got %s", c.Query, n, k, v, got)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	i := indexer.NewDefault()
+	if err := library.Load(i); err != nil {
+		t.Fatal(err)
+	}
+	for _, sig := range []*ast.Signature{{Functor: "append", Arity: 3}, {Functor: "maplist", Arity: 3}, {Functor: "pairs_keys_values", Arity: 3}} {
+		if len(i.StatementsForSignature(sig)) == 0 {
+			t.Errorf("%s was not loaded", sig)
+		}
+	}
+}
+
+func TestLists(t *testing.T) {
+	cases := []libraryTestCase{
+		{"append(X, Y, [1, 2]).", []map[string]string{
+			{"X": "L[]", "Y": "L[1.000000,2.000000]"},
+			{"X": "L[1.000000]", "Y": "L[2.000000]"},
+			{"X": "L[1.000000,2.000000]", "Y": "L[]"},
+		}},
+		{"append([a], [b, c], Z).", []map[string]string{{"Z": "L[a,b,c]"}}},
+		{"member(X, [a, b]).", []map[string]string{{"X": "a"}, {"X": "b"}}},
+		{"memberchk(X, [a, b]).", []map[string]string{{"X": "a"}}},
+		{"reverse([a, b, c], R).", []map[string]string{{"R": "L[c,b,a]"}}},
+		{"last([a, b, c], X).", []map[string]string{{"X": "c"}}},
+		{"length([a, b, c], N).", []map[string]string{{"N": "3.000000"}}},
+		{"length(L, 2).", []map[string]string{{}}},
+		{"nth0(1, [a, b, c], X).", []map[string]string{{"X": "b"}}},
+		{"nth1(1, [a, b, c], X).", []map[string]string{{"X": "a"}}},
+		{"nth1(I, [a, b, a], a).", []map[string]string{{"I": "1.000000"}, {"I": "3.000000"}}},
+		{"msort([c, a, b, a], X).", []map[string]string{{"X": "L[a,a,b,c]"}}},
+		{"sort([c, a, b, a], X).", []map[string]string{{"X": "L[a,b,c]"}}},
+		{"sort(0, \"@>=\", [1, 3, 2, 3], X).", []map[string]string{{"X": "L[3.000000,3.000000,2.000000,1.000000]"}}},
+		{"sort(1, \"@<\", [f(2, a), f(1, b), f(2, c)], X).", []map[string]string{{"X": "L[f(1.000000,b),f(2.000000,a)]"}}},
+		{"keysort([b-1, a-2, b-0], X).", []map[string]string{{"X": "L[-(a,2.000000),-(b,1.000000),-(b,0.000000)]"}}},
+		{"sum_list([1, 2, 3], X).", []map[string]string{{"X": "6.000000"}}},
+		{"max_list([1, 5, 3], X).", []map[string]string{{"X": "5.000000"}}},
+		{"numlist(1, 3, X).", []map[string]string{{"X": "L[1.000000,2.000000,3.000000]"}}},
+	}
+	for _, c := range cases {
+		runLibraryTestCase(t, "", c)
+	}
+}
+
+func TestApply(t *testing.T) {
+	program := `
+double(X, Y) :- Y is 2 * X.
+add(X, A0, A) :- A is A0 + X.
+small(1).
+small(2).
+desc(O, A, B) :- compare(O, B, A).
+`
+	cases := []libraryTestCase{
+		{"maplist(small, [1, 2]).", []map[string]string{{}}},
+		{"maplist(small, [1, 3]).", []map[string]string{}},
+		{"maplist(double, [1, 2, 3], L).", []map[string]string{{"L": "L[2.000000,4.000000,6.000000]"}}},
+		{"foldl(add, [1, 2, 3], 0, S).", []map[string]string{{"S": "6.000000"}}},
+		{"include(small, [1, 2, 3, 1], L).", []map[string]string{{"L": "L[1.000000,2.000000,1.000000]"}}},
+		{"exclude(small, [1, 2, 3, 1], L).", []map[string]string{{"L": "L[3.000000]"}}},
+		{"partition(small, [3, 1, 4], I, E).", []map[string]string{{"I": "L[1.000000]", "E": "L[3.000000,4.000000]"}}},
+		{"predsort(desc, [1, 3, 2, 3], L).", []map[string]string{{"L": "L[3.000000,2.000000,1.000000]"}}},
+		{"pairs_keys_values(P, [a, b], [1, 2]).", []map[string]string{{"P": "L[-(a,1.000000),-(b,2.000000)]"}}},
+		{"pairs_values([a-1, b-2], V).", []map[string]string{{"V": "L[1.000000,2.000000]"}}},
+	}
+	for _, c := range cases {
+		runLibraryTestCase(t, program, c)
+	}
+}
@@ -0,0 +1,12 @@
+append([], L, L).
+append([H|T], L, [H|R]) :- append(T, L, R).
+
+member(X, [X|T]).
+member(X, [H|T]) :- member(X, T).
+
+reverse(L, R) :- reverse_acc(L, [], R).
+reverse_acc([], A, A).
+reverse_acc([H|T], A, R) :- reverse_acc(T, [H|A], R).
+
+last([X], X).
+last([H|T], X) :- last(T, X).
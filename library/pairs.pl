@@ -0,0 +1,8 @@
+pairs_keys_values([], [], []).
+pairs_keys_values([K-V|T], [K|Ks], [V|Vs]) :- pairs_keys_values(T, Ks, Vs).
+
+pairs_keys([], []).
+pairs_keys([K-V|T], [K|Ks]) :- pairs_keys(T, Ks).
+
+pairs_values([], []).
+pairs_values([K-V|T], [V|Vs]) :- pairs_values(T, Vs).
@@ -0,0 +1,130 @@
+package plunit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+type expectation int
+
+const (
+	expectTrue expectation = iota
+	expectFail
+	expectThrows
+	expectAll
+)
+
+// options are the parsed options of a test, see the package documentation for what each one does
+type options struct {
+	expect     expectation
+	expectSet  bool
+	conditions []ast.Term
+	throws     ast.Term
+	// all is the Template = List or Template == List option
+	all     *ast.Fact
+	nondet  bool
+	setup   ast.Term
+	cleanup ast.Term
+	timeout time.Duration
+	blocked ast.Term
+	// ops are the operators the options are written with in errors
+	ops ast.Operators
+}
+
+// parseOptions reads the options of a test, which can either be a list or a single option
+func parseOptions(term ast.Term, ops ast.Operators) (*options, error) {
+	opts := &options{ops: ops}
+	for _, option := range optionList(term) {
+		if err := opts.add(option); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// optionList returns the items of a list, anything else is treated as a list of one option
+func optionList(term ast.Term) []ast.Term {
+	list, ok := term.(*ast.Fact)
+	if !ok || list.Head != "|" {
+		return []ast.Term{term}
+	}
+	ret := []ast.Term{}
+	for len(list.Args) == 2 {
+		ret = append(ret, list.Args[0])
+		next, ok := list.Args[1].(*ast.Fact)
+		if !ok || next.Head != "|" {
+			break
+		}
+		list = next
+	}
+	return ret
+}
+
+func (o *options) add(option ast.Term) error {
+	switch option.GetType() {
+	case ast.T_Atom:
+		switch option.String() {
+		case "true":
+			return o.setExpect(expectTrue)
+		case "fail", "false":
+			return o.setExpect(expectFail)
+		case "nondet":
+			o.nondet = true
+			return nil
+		}
+	case ast.T_Fact:
+		f := option.(*ast.Fact)
+		if len(f.Args) == 0 {
+			return o.add(ast.CreateAtom(f.Head))
+		}
+		switch f.Signature().String() {
+		case "true/1":
+			o.conditions = append(o.conditions, f.Args[0])
+			return o.setExpect(expectTrue)
+		case "=/2":
+			o.conditions = append(o.conditions, f)
+			return o.setExpect(expectTrue)
+		case "throws/1":
+			o.throws = f.Args[0]
+			return o.setExpect(expectThrows)
+		case "error/1":
+			o.throws = ast.CreateFact("error", f.Args[0], ast.CreateVariable("_"))
+			return o.setExpect(expectThrows)
+		case "all/1":
+			all, ok := f.Args[0].(*ast.Fact)
+			if !ok || (all.Head != "=" && all.Head != "==") || len(all.Args) != 2 {
+				return fmt.Errorf("all expects Template = List or Template == List, got %s", writeTerm(f.Args[0], o.ops))
+			}
+			o.all = all
+			return o.setExpect(expectAll)
+		case "setup/1":
+			o.setup = f.Args[0]
+			return nil
+		case "cleanup/1":
+			o.cleanup = f.Args[0]
+			return nil
+		case "blocked/1":
+			o.blocked = f.Args[0]
+			return nil
+		case "timeout/1":
+			seconds, ok := f.Args[0].(*ast.NumericLiteral)
+			if !ok || seconds.Value() <= 0 {
+				return fmt.Errorf("timeout expects a positive number of seconds, got %s", writeTerm(f.Args[0], o.ops))
+			}
+			o.timeout = time.Duration(seconds.Value() * float64(time.Second))
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown test option %s", writeTerm(option, o.ops))
+}
+
+// setExpect records what the test should do, a test can only have one kind of expectation
+func (o *options) setExpect(e expectation) error {
+	if o.expectSet && o.expect != e {
+		return fmt.Errorf("a test can only have one of true, fail, throws or all")
+	}
+	o.expect, o.expectSet = e, true
+	return nil
+}
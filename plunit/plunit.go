@@ -0,0 +1,216 @@
+// Package plunit runs the tests declared between begin_tests/1 and end_tests/1, in the style of SWI-Prolog's plunit.
+//
+//	?- begin_tests(lists).
+//	test(reverse, [true(R = [3,2,1])]) :- reverse([1,2,3], R).
+//	test(member, [nondet]) :- member(2, [1,2,3]).
+//	test(empty, [fail]) :- member(_, []).
+//	?- end_tests(lists).
+//
+// The options a test can have are:
+//   - true(Cond) or Left = Right: Cond must hold after the test succeeds, this is the default expectation
+//   - fail or false: the test must fail
+//   - throws(Ball): the test must raise an exception which unifies with Ball, error(E) is short for throws(error(E, _))
+//   - all(Template = List) or all(Template == List): List must unify with (or be identical to) Template from every solution of the test
+//   - nondet: the test may have more than one solution, otherwise a test with another solution fails
+//   - setup(Goal) and cleanup(Goal): run once before and after the test, setup doesnt share variables with the test
+//   - timeout(Seconds): overrides the runner's time limit for this test
+//   - blocked(Reason): skips the test
+package plunit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/engine"
+)
+
+// Status is the outcome of a test
+type Status int
+
+const (
+	Passed Status = iota
+	// Failed is a test which didnt do what its options expected
+	Failed
+	// Error is a test which couldnt be run properly, i.e. it timed out, its setup failed or it raised an unexpected exception
+	Error
+	// Skipped is a blocked test
+	Skipped
+)
+
+func (s Status) String() string {
+	return [...]string{"passed", "failed", "error", "skipped"}[s]
+}
+
+// Result is what happened when a test was run, Message explains why it didnt pass
+type Result struct {
+	Test     engine.Test
+	Status   Status
+	Message  string
+	Duration time.Duration
+}
+
+// Runner runs the tests consulted into an engine
+type Runner struct {
+	// Timeout is the longest each test can run for unless it has a timeout option, 0 means there is no limit
+	Timeout time.Duration
+}
+
+// Run runs every test in the engine in the order they were consulted
+func (r *Runner) Run(ctx context.Context, e *engine.Engine) []Result {
+	results := []Result{}
+	for _, t := range e.Tests() {
+		results = append(results, r.RunTest(ctx, e, t))
+	}
+	return results
+}
+
+// RunTest runs a single test
+func (r *Runner) RunTest(ctx context.Context, e *engine.Engine, t engine.Test) Result {
+	start := time.Now()
+	result := r.run(ctx, e, t)
+	result.Test = t
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (r *Runner) run(ctx context.Context, e *engine.Engine, t engine.Test) Result {
+	ops := e.Resolver().Ops()
+	opts, err := parseOptions(t.Options, ops)
+	if err != nil {
+		return Result{Status: Error, Message: err.Error()}
+	}
+	if opts.blocked != nil {
+		return Result{Status: Skipped, Message: "blocked: " + writeTerm(opts.blocked, ops)}
+	}
+
+	timeout := r.Timeout
+	if opts.timeout > 0 {
+		timeout = opts.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if opts.setup != nil {
+		if err := once(ctx, e, opts.setup); err != nil {
+			return errorResult("setup", err, timeout)
+		}
+	}
+	if opts.cleanup != nil {
+		// cleanup runs even when the test timed out
+		defer func() {
+			_ = once(context.Background(), e, opts.cleanup)
+		}()
+	}
+
+	sols := e.QueryTerm(ctx, t.Goal(ast.CreateVariable("Options")))
+	defer sols.Close()
+	switch opts.expect {
+	case expectFail:
+		if sols.Next() {
+			return Result{Status: Failed, Message: "test succeeded but should have failed"}
+		}
+		if err := sols.Err(); err != nil {
+			return errorResult("test", err, timeout)
+		}
+		return Result{Status: Passed}
+
+	case expectThrows:
+		if sols.Next() {
+			return Result{Status: Failed, Message: "test succeeded but should have raised " + writeTerm(opts.throws, ops)}
+		}
+		var ex *engine.Exception
+		if err := sols.Err(); !errors.As(err, &ex) {
+			if err != nil {
+				return errorResult("test", err, timeout)
+			}
+			return Result{Status: Failed, Message: "test failed but should have raised " + writeTerm(opts.throws, ops)}
+		}
+		if once(ctx, e, ast.CreateFact("=", ex.Term, opts.throws)) != nil {
+			return Result{Status: Failed, Message: fmt.Sprintf("test raised %s but should have raised %s", writeTerm(ex.Term, ops), writeTerm(opts.throws, ops))}
+		}
+		return Result{Status: Passed}
+
+	case expectAll:
+		found := []ast.Term{}
+		for sols.Next() {
+			solved, err := parseOptions(sols.Bindings()["Options"], ops)
+			if err != nil {
+				return Result{Status: Error, Message: err.Error()}
+			}
+			found = append(found, solved.all.Args[0])
+		}
+		if err := sols.Err(); err != nil {
+			return errorResult("test", err, timeout)
+		}
+		// the solutions are compared to the list the way the option does, by unifying them or with ==
+		got := ast.CreateList(found...)
+		if once(ctx, e, ast.CreateFact(opts.all.Head, got, opts.all.Args[1])) != nil {
+			return Result{Status: Failed, Message: fmt.Sprintf("test found %s but should have found %s", writeTerm(got, ops), writeTerm(opts.all.Args[1], ops))}
+		}
+		return Result{Status: Passed}
+	}
+
+	if !sols.Next() {
+		if err := sols.Err(); err != nil {
+			return errorResult("test", err, timeout)
+		}
+		return Result{Status: Failed, Message: "test failed"}
+	}
+	// the conditions are checked using the values from the solution
+	solved, err := parseOptions(sols.Bindings()["Options"], ops)
+	if err != nil {
+		return Result{Status: Error, Message: err.Error()}
+	}
+	for _, cond := range solved.conditions {
+		if err := once(ctx, e, cond); err != nil {
+			var ex *engine.Exception
+			if errors.As(err, &ex) || errors.Is(err, context.DeadlineExceeded) {
+				return errorResult("condition "+writeTerm(cond, ops), err, timeout)
+			}
+			return Result{Status: Failed, Message: "condition failed: " + writeTerm(cond, ops)}
+		}
+	}
+	if !opts.nondet {
+		if sols.Next() {
+			return Result{Status: Failed, Message: "test succeeded with more than one solution, add the nondet option if that is expected"}
+		}
+		if err := sols.Err(); err != nil {
+			return errorResult("test", err, timeout)
+		}
+	}
+	return Result{Status: Passed}
+}
+
+// errorResult reports an error that stopped part of the test from running
+func errorResult(part string, err error, timeout time.Duration) Result {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Result{Status: Error, Message: fmt.Sprintf("%s timed out after %s", part, timeout)}
+	}
+	return Result{Status: Error, Message: fmt.Sprintf("%s: %s", part, err)}
+}
+
+var errGoalFailed = errors.New("goal failed")
+
+// once runs the goal until its first solution, returning errGoalFailed if there isnt one
+func once(ctx context.Context, e *engine.Engine, goal ast.Term) error {
+	sols := e.QueryTerm(ctx, goal)
+	defer sols.Close()
+	if sols.Next() {
+		return nil
+	}
+	if err := sols.Err(); err != nil {
+		return err
+	}
+	return errGoalFailed
+}
+
+// writeTerm writes a term for a message with the operators of the engine, so a condition reads the way it does in the source
+func writeTerm(t ast.Term, ops ast.Operators) string {
+	return ast.WriteTerm(t, ast.WriteOptions{Quoted: true, Ops: ops})
+}
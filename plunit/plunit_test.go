@@ -0,0 +1,143 @@
+package plunit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kkoch986/gopl/engine"
+	"github.com/kkoch986/gopl/plunit"
+)
+
+const tests = `
+loop() :- loop().
+?- begin_tests(lists).
+test(reverse, [true(R = [3,2,1])]) :- reverse([1,2,3], R).
+test(wrong, [true(R = [1,2,3])]) :- reverse([1,2,3], R).
+test(shorthand, R = [b]) :- reverse([b], R).
+test(nondet, [nondet]) :- member(2, [1,2,2]).
+test(det) :- member(2, [1,2,2]).
+test(fails, [fail]) :- member(X, []).
+test(all, [all(X = [1,2,3])]) :- member(X, [1,2,3]).
+test(throws, [throws(oops)]) :- throw(oops).
+test(error, [error(instantiation_error)]) :- put_char(C).
+test(unexpected) :- throw(oops).
+test(blocked, [blocked(slow)]) :- loop().
+test(loop, [timeout(0.1)]) :- loop().
+test(setup, [setup(assert(seen(yes))), cleanup(assert(cleaned(yes))), true(X = yes)]) :- seen(X).
+test(bad_option, [sometimes]).
+test(identical, [true(X == 3)]) :- X = 3.
+test(not_identical, [true(X == 4)]) :- X = 3.
+test(all_identical, [all(X == [1,2])]) :- member(X, [1,2]).
+test(all_not_identical, [all(X == [1,2])]) :- member(X, [1,2,3]).
+test(checks, [true(integer(X)), true(X > 1), true(X \== 4), true(X @< a)]) :- X = 3.
+?- end_tests(lists).
+`
+
+func runTests(t *testing.T) []plunit.Result {
+	e, err := engine.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ConsultString(tests); err != nil {
+		t.Fatal(err)
+	}
+	runner := &plunit.Runner{Timeout: 5 * time.Second}
+	return runner.Run(context.Background(), e)
+}
+
+func TestRunner(t *testing.T) {
+	expected := []struct {
+		name    string
+		status  plunit.Status
+		message string
+	}{
+		{"reverse", plunit.Passed, ""},
+		{"wrong", plunit.Failed, "condition failed: [3,2,1]=[1,2,3]"},
+		{"shorthand", plunit.Passed, ""},
+		{"nondet", plunit.Passed, ""},
+		{"det", plunit.Failed, "test succeeded with more than one solution, add the nondet option if that is expected"},
+		{"fails", plunit.Passed, ""},
+		{"all", plunit.Passed, ""},
+		{"throws", plunit.Passed, ""},
+		{"error", plunit.Passed, ""},
+		{"unexpected", plunit.Error, "test: uncaught exception: oops"},
+		{"blocked", plunit.Skipped, "blocked: slow"},
+		{"loop", plunit.Error, "test timed out after 100ms"},
+		{"setup", plunit.Passed, ""},
+		{"bad_option", plunit.Error, "unknown test option sometimes"},
+		{"identical", plunit.Passed, ""},
+		{"not_identical", plunit.Failed, "condition failed: 3==4"},
+		{"all_identical", plunit.Passed, ""},
+		{"all_not_identical", plunit.Failed, "test found [1,2,3] but should have found [1,2]"},
+		{"checks", plunit.Passed, ""},
+	}
+
+	results := runTests(t)
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.Test.Unit != "lists" || r.Test.Name != e.name {
+			t.Errorf("%d: expected lists:%s, got %s:%s", i, e.name, r.Test.Unit, r.Test.Name)
+		}
+		if r.Status != e.status || r.Message != e.message {
+			t.Errorf("%s: expected %s %q, got %s %q", e.name, e.status, e.message, r.Status, r.Message)
+		}
+		if r.Test.Pos.Line != i+4 {
+			t.Errorf("%s: expected it on line %d, got %s", e.name, i+4, r.Test.Pos)
+		}
+	}
+
+	var text bytes.Buffer
+	if err := plunit.WriteText(&text, results, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "--- FAIL: lists:wrong") || strings.Contains(text.String(), "lists:reverse") {
+		t.Errorf("unexpected text report\n%s", text.String())
+	}
+	if !strings.Contains(text.String(), "FAIL: 11 passed, 4 failed, 3 errors, 1 skipped") {
+		t.Errorf("unexpected summary\n%s", text.String())
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	var out bytes.Buffer
+	if err := plunit.WriteJUnit(&out, runTests(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Tests  int `xml:"tests,attr"`
+		Suites []struct {
+			Name     string `xml:"name,attr"`
+			Failures int    `xml:"failures,attr"`
+			Errors   int    `xml:"errors,attr"`
+			Skipped  int    `xml:"skipped,attr"`
+			Cases    []struct {
+				Name    string `xml:"name,attr"`
+				Line    int    `xml:"line,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Tests != 19 || len(doc.Suites) != 1 {
+		t.Fatalf("unexpected document\n%s", out.String())
+	}
+	suite := doc.Suites[0]
+	if suite.Name != "lists" || suite.Failures != 4 || suite.Errors != 3 || suite.Skipped != 1 || len(suite.Cases) != 19 {
+		t.Errorf("unexpected suite\n%s", out.String())
+	}
+	if c := suite.Cases[1]; c.Name != "wrong" || c.Line != 5 || c.Failure == nil || c.Failure.Message != "condition failed: [3,2,1]=[1,2,3]" {
+		t.Errorf("unexpected test case %+v", c)
+	}
+}
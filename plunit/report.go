@@ -0,0 +1,177 @@
+package plunit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Summary counts the results with each status
+type Summary struct {
+	Passed  int
+	Failed  int
+	Errors  int
+	Skipped int
+	Time    time.Duration
+}
+
+// Summarize totals up the results
+func Summarize(results []Result) Summary {
+	s := Summary{}
+	for _, r := range results {
+		switch r.Status {
+		case Passed:
+			s.Passed++
+		case Failed:
+			s.Failed++
+		case Error:
+			s.Errors++
+		case Skipped:
+			s.Skipped++
+		}
+		s.Time += r.Duration
+	}
+	return s
+}
+
+// OK is true when none of the tests failed or had errors
+func (s Summary) OK() bool {
+	return s.Failed == 0 && s.Errors == 0
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d passed, %d failed, %d errors, %d skipped (%.3fs)", s.Passed, s.Failed, s.Errors, s.Skipped, s.Time.Seconds())
+}
+
+/**
+ * WriteText writes a line for every test that didnt pass along with the reason, and a summary at the end.
+ * When verbose is set the tests which passed are listed too.
+ *
+ *	--- FAIL: lists:reverse (0.001s)
+ *	    tests.pl:3: condition failed: [1,2,3]=[3,2,1]
+ *	FAIL: 1 passed, 1 failed, 0 errors, 0 skipped (0.002s)
+ */
+func WriteText(w io.Writer, results []Result, verbose bool) error {
+	for _, r := range results {
+		if r.Status == Passed && !verbose {
+			continue
+		}
+		label := map[Status]string{Passed: "PASS", Failed: "FAIL", Error: "ERROR", Skipped: "SKIP"}[r.Status]
+		if _, err := fmt.Fprintf(w, "--- %s: %s:%s (%.3fs)\n", label, r.Test.Unit, r.Test.Name, r.Duration.Seconds()); err != nil {
+			return err
+		}
+		if r.Message != "" {
+			if _, err := fmt.Fprintf(w, "    %s: %s\n", r.Test.Pos, r.Message); err != nil {
+				return err
+			}
+		}
+	}
+	summary := Summarize(results)
+	status := "PASS"
+	if !summary.OK() {
+		status = "FAIL"
+	}
+	_, err := fmt.Fprintf(w, "%s: %s\n", status, summary)
+	return err
+}
+
+type junitSuites struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Errors   int           `xml:"errors,attr"`
+	Skipped  int           `xml:"skipped,attr"`
+	Time     string        `xml:"time,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+
+	results []Result
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit writes the results as JUnit XML with a testsuite for each unit
+func WriteJUnit(w io.Writer, results []Result) error {
+	suites := []*junitSuite{}
+	byUnit := make(map[string]*junitSuite)
+	for _, r := range results {
+		suite, ok := byUnit[r.Test.Unit]
+		if !ok {
+			suite = &junitSuite{Name: r.Test.Unit}
+			byUnit[r.Test.Unit] = suite
+			suites = append(suites, suite)
+		}
+		suite.results = append(suite.results, r)
+
+		c := junitCase{
+			Name:      r.Test.Name,
+			Classname: r.Test.Unit,
+			Time:      seconds(r.Duration),
+			File:      r.Test.Pos.File,
+			Line:      r.Test.Pos.Line,
+		}
+		switch r.Status {
+		case Failed:
+			c.Failure = &junitMessage{r.Message}
+		case Error:
+			c.Error = &junitMessage{r.Message}
+		case Skipped:
+			c.Skipped = &junitMessage{r.Message}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	summary := Summarize(results)
+	doc := junitSuites{
+		Tests:    len(results),
+		Failures: summary.Failed,
+		Errors:   summary.Errors,
+		Skipped:  summary.Skipped,
+		Time:     seconds(summary.Time),
+		Suites:   suites,
+	}
+	for _, suite := range suites {
+		s := Summarize(suite.results)
+		suite.Tests = len(suite.results)
+		suite.Failures, suite.Errors, suite.Skipped = s.Failed, s.Errors, s.Skipped
+		suite.Time = seconds(s.Time)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -2,6 +2,7 @@ package raw
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 
 	"github.com/kkoch986/gopl/ast"
@@ -13,26 +14,34 @@ type rawStatement struct {
 
 func (rs *rawStatement) UnmarshalJSON(b []byte) error {
 	statement, err := ast.UnmarshalJSONTerm(b)
-	// TODO: check that the terms type is rule|fact|query
 	if err != nil {
 		return err
 	}
+	switch statement.GetType() {
+	case ast.T_Rule, ast.T_Fact, ast.T_Query:
+	default:
+		return fmt.Errorf("expected a rule, fact or query, got a %s", statement.GetType())
+	}
 	rs.S = statement
 	return nil
 }
 
-func Deserialize(r io.Reader, out chan<- ast.Statement) {
+/**
+ * Deserialize reads the statements written by Serialize and sends each of them to out, closing it when it is done.
+ * It stops at the first statement it cant read and returns an error with the offset of it in the input.
+ */
+func Deserialize(r io.Reader, out chan<- ast.Statement) error {
+	defer close(out)
 	decoder := json.NewDecoder(r)
 	for {
+		offset := decoder.InputOffset()
 		var s rawStatement
 		err := decoder.Decode(&s)
 		if err == io.EOF {
-			break
+			return nil
 		} else if err != nil {
-			// TODO: handle other kinds of errors
-			panic(err)
+			return fmt.Errorf("raw statement at offset %d: %w", offset, err)
 		}
 		out <- s.S
 	}
-	close(out)
 }
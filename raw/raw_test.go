@@ -0,0 +1,109 @@
+package raw_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/raw"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func TestPositions(t *testing.T) {
+	src := "parent(tom, bob).\nparent(bob, ann).\ngrandparent(X, Z) :-\n  parent(X, Y),\n  parent(Y, Z).\n"
+	statements, err := syntax.Parse([]rune(src), "family.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := raw.Serialize(statements, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := make(chan ast.Statement)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- raw.Deserialize(&buf, loaded)
+	}()
+	var rule *ast.Rule
+	count := 0
+	for s := range loaded {
+		count++
+		if r, ok := s.(*ast.Rule); ok {
+			rule = r
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || rule == nil {
+		t.Fatalf("expected the 3 clauses to be read back, got %d", count)
+	}
+
+	expected := []struct {
+		term ast.Term
+		pos  string
+	}{
+		{rule, "3:1"},
+		{rule.Head, "3:1"},
+		{rule.Head.Args[1], "3:16"},
+		{rule.Body, "4:3"},
+		{(*rule.Body)[1], "5:3"},
+		{(*rule.Body)[1].(*ast.Fact).Args[0], "5:10"},
+	}
+	for _, c := range expected {
+		if pos := ast.PositionOf(c.term); pos.String() != "family.pl:"+c.pos {
+			t.Errorf("%s: expected it at %s, got %s", c.term, c.pos, pos)
+		}
+	}
+	if end := ast.PositionOf(rule.Body); end.EndLine != 5 || end.EndColumn != 14 {
+		t.Errorf("expected the body to end at 5:14, got %+v", end)
+	}
+}
+
+func TestDeserializeErrors(t *testing.T) {
+	for src, expected := range map[string]string{
+		`{"t":"fact","f":"a","a":[]} {"t":"rule"`: "offset 27",
+		`{"t":"atom","v":"a"}`:                    "expected a rule, fact or query",
+		`{"t":"nope"}`:                            "Unknown raw statement type",
+	} {
+		loaded := make(chan ast.Statement)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- raw.Deserialize(strings.NewReader(src), loaded)
+		}()
+		for range loaded {
+		}
+		if err := <-errc; err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected an error with %q, got %v", src, expected, err)
+		}
+	}
+}
+
+// FuzzDeserialize runs arbitrary input through Deserialize and indexes whatever it reads, neither of which should panic
+func FuzzDeserialize(f *testing.F) {
+	statements, err := syntax.Parse([]rune("a(X) :- b(X, [1|T], \"s\"), Y is X + 1.\n?- a(1)."), "seed.pl")
+	if err != nil {
+		f.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := raw.Serialize(statements, &buf); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.String())
+	f.Add(`{"t":"rule","h":{"t":"atom"}}`)
+	f.Add(`{"t":"query","b":[{"t":"query","b":[]}]}`)
+	f.Fuzz(func(t *testing.T, src string) {
+		loaded := make(chan ast.Statement)
+		go func() {
+			_ = raw.Deserialize(strings.NewReader(src), loaded)
+		}()
+		idx := indexer.NewDefault()
+		for s := range loaded {
+			_ = s.String()
+			idx.IndexStatement(s)
+		}
+	})
+}
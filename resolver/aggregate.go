@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"log"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * aggregateAll implements aggregate_all/3.
+ *   aggregate_all(count, Goal, Count)       - the number of solutions of Goal
+ *   aggregate_all(sum(Expr), Goal, Sum)     - the sum of Expr over all solutions (0 if there are none)
+ *   aggregate_all(max(Expr), Goal, Max)     - the maximum value of Expr, fails if there are no solutions
+ *   aggregate_all(min(Expr), Goal, Min)     - the minimum value of Expr, fails if there are no solutions
+ *   aggregate_all(max(Expr, W), Goal, max(Max, Witness)) - the maximum along with the witness for that solution
+ *   aggregate_all(min(Expr, W), Goal, min(Min, Witness)) - the minimum along with the witness for that solution
+ *   aggregate_all(bag(T), Goal, List)       - the same as findall/3
+ *   aggregate_all(set(T), Goal, List)       - the same as findall/3 but sorted with duplicates removed
+ */
+func (r *R) aggregateAll(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	result, ex := r.aggregate(ctx, c.Dereference(args[0]), args[1], c)
+	if ex != nil {
+		send(ctx, out, ex)
+	} else if result != nil {
+		unifyAndSend(ctx, args[2], result, c, out)
+	}
+}
+
+/**
+ * aggregate computes the result of the aggregation, returning nil if the aggregation should fail.
+ * If the goal raises an exception it is returned as the second value.
+ */
+func (r *R) aggregate(ctx context.Context, spec ast.Term, goal ast.Term, c *Bindings) (ast.Term, *Bindings) {
+	if spec.GetType() == ast.T_Atom && spec.String() == "count" {
+		solutions, ex := r.findSolutions(ctx, spec, goal, c)
+		return ast.CreateNumericLiteral(float64(len(solutions))), ex
+	}
+
+	if spec.GetType() != ast.T_Fact {
+		log.Printf("[DEBUG][AggregateAll] Unknown aggregation: %s", spec)
+		return nil, nil
+	}
+
+	f := spec.(*ast.Fact)
+	switch f.Signature().String() {
+	case "count/1":
+		solutions, ex := r.findSolutions(ctx, f.Args[0], goal, c)
+		return ast.CreateNumericLiteral(float64(len(solutions))), ex
+	case "bag/1":
+		solutions, ex := r.findSolutions(ctx, f.Args[0], goal, c)
+		return ast.CreateList(solutions...), ex
+	case "set/1":
+		solutions, ex := r.findSolutions(ctx, f.Args[0], goal, c)
+		return ast.CreateList(sortTerms(solutions, true)...), ex
+	case "sum/1":
+		solutions, ex := r.findSolutions(ctx, f.Args[0], goal, c)
+		if ex != nil {
+			return nil, ex
+		}
+		sum := 0.0
+		for _, v := range solutions {
+			n, err := evalArithmetic(v, EmptyBindings())
+			if err != nil {
+				log.Printf("[DEBUG][AggregateAll] Unable to evaluate %s: %s", v, err)
+				return nil, nil
+			}
+			sum = sum + n
+		}
+		return ast.CreateNumericLiteral(sum), nil
+	case "max/1", "min/1":
+		best, _, ex := r.extreme(ctx, f.Head == "max", f.Args[0], ast.CreateAtom("none"), goal, c)
+		if best == nil {
+			return nil, ex
+		}
+		return best, nil
+	case "max/2", "min/2":
+		best, witness, ex := r.extreme(ctx, f.Head == "max", f.Args[0], f.Args[1], goal, c)
+		if best == nil {
+			return nil, ex
+		}
+		return ast.CreateFact(f.Head, best, witness), nil
+	}
+
+	log.Printf("[DEBUG][AggregateAll] Unknown aggregation: %s", spec)
+	return nil, nil
+}
+
+// extreme finds the largest (or smallest) value of expr over all solutions along with the matching witness
+func (r *R) extreme(ctx context.Context, max bool, expr ast.Term, witness ast.Term, goal ast.Term, c *Bindings) (ast.Term, ast.Term, *Bindings) {
+	var best *ast.NumericLiteral
+	var bestWitness ast.Term
+	solutions, ex := r.findSolutions(ctx, ast.CreateFact("-", expr, witness), goal, c)
+	if ex != nil {
+		return nil, nil, ex
+	}
+	for _, s := range solutions {
+		pair := s.(*ast.Fact)
+		n, err := evalArithmetic(pair.Args[0], EmptyBindings())
+		if err != nil {
+			log.Printf("[DEBUG][AggregateAll] Unable to evaluate %s: %s", pair.Args[0], err)
+			return nil, nil, nil
+		}
+		if best == nil || (max && n > best.Value()) || (!max && n < best.Value()) {
+			best = ast.CreateNumericLiteral(n)
+			bestWitness = pair.Args[1]
+		}
+	}
+	if best == nil {
+		return nil, nil, nil
+	}
+	return best, bestWitness, nil
+}
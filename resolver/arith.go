@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"errors"
+	"math"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+var (
+	ErrNotEvaluable = errors.New("Term is not an evaluable arithmetic expression")
+)
+
+/**
+ * evalArithmetic evaluates a term as an arithmetic expression.
+ * This is used by builtins which accept expressions as arguments (i.e. aggregate_all(sum(X), ...)).
+ * Numbers evaluate to themselves, bound variables are dereferenced and compound terms
+ * using the usual operators (+, -, *, /, min, max, abs) are evaluated recursively.
+ */
+func evalArithmetic(t ast.Term, c *Bindings) (float64, error) {
+	t = c.Dereference(t)
+	switch t.GetType() {
+	case ast.T_Number:
+		return t.(*ast.NumericLiteral).Value(), nil
+	case ast.T_Variable:
+		return 0, ErrUnboundVariable
+	case ast.T_Fact:
+		f := t.(*ast.Fact)
+		args := make([]float64, len(f.Args))
+		for i, a := range f.Args {
+			v, err := evalArithmetic(a, c)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = v
+		}
+
+		switch f.Signature().String() {
+		case "+/2":
+			return args[0] + args[1], nil
+		case "-/2":
+			return args[0] - args[1], nil
+		case "*/2":
+			return args[0] * args[1], nil
+		case "//2":
+			return args[0] / args[1], nil
+		case "-/1":
+			return -args[0], nil
+		case "abs/1":
+			return math.Abs(args[0]), nil
+		case "min/2":
+			return math.Min(args[0], args[1]), nil
+		case "max/2":
+			return math.Max(args[0], args[1]), nil
+		}
+	}
+	return 0, ErrNotEvaluable
+}
@@ -9,42 +9,41 @@ import (
 )
 
 /**
- * Assert/1 will take the given fact and insert it into the current indexed universe.
+ * assert/1 will take the given fact and insert it into the current indexed universe.
+ * Given a string it will load all of the statements from that compiled file instead.
  */
-type Assert struct {
-	idx indexer.Indexer
-}
-
-func (w *Assert) Resolve(fact *ast.Fact, c *Bindings, out chan<- *Bindings, m chan<- bool) {
-	if fact.Signature().String() != "assert/1" {
-		m <- false
-		return
-	}
-	defer close(out)
-	defer close(m)
-
-	// TODO: ground any input terms i.e. variables etc..
-
-	if fact.Args[0].GetType() == ast.T_String {
-		w.indexFile(fact.Args[0].String())
-	} else {
-		w.idx.IndexStatement(fact.Args[0])
+func assert(idx indexer.Indexer) Predicate {
+	return func(args []ast.Term, c *Bindings) (*Bindings, bool, error) {
+		// TODO: ground any input terms i.e. variables etc..
+
+		if args[0].GetType() == ast.T_String {
+			err := indexFile(idx, args[0].String())
+			if os.IsNotExist(err) {
+				return nil, false, &Exception{existenceError("source_sink", args[0]).Exception}
+			} else if err != nil {
+				return nil, false, err
+			}
+		} else {
+			idx.IndexStatement(args[0])
+		}
+		return c, true, nil
 	}
-
-	out <- c
-	m <- true
 }
 
-func (w *Assert) indexFile(filename string) {
+func indexFile(idx indexer.Indexer, filename string) error {
 	f, err := os.Open(filename)
 	if err != nil {
-		// TODO: better error handling
-		panic(err)
+		return err
 	}
+	defer f.Close()
 
 	statements := make(chan ast.Statement)
-	go raw.Deserialize(f, statements)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- raw.Deserialize(f, statements)
+	}()
 	for s := range statements {
-		w.idx.IndexStatement(s)
+		idx.IndexStatement(s)
 	}
+	return <-errc
 }
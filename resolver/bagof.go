@@ -0,0 +1,149 @@
+package resolver
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * bagof implements bagof/3 and setof/3.
+ *
+ * bagof(Template, Goal, Bag) is similar to findall/3 except:
+ *   - it fails if there are no solutions instead of returning an empty list
+ *   - variables in Goal which dont appear in Template are "free" and the solutions will be grouped
+ *     by the values of those free variables, yielding one Bag per group.
+ *   - variables can be excluded from the grouping by existentially quantifying them with `^/2`
+ *     i.e. `bagof(X, Y^p(X, Y), L)` collects all of the X's regardless of Y.
+ *
+ * setof/3 does the same but each Bag is sorted in the standard order of terms with duplicates removed.
+ */
+type bagofGroup struct {
+	witness   ast.Term
+	templates []ast.Term
+}
+
+// bagof creates bagof/3 or setof/3 depending on isSet
+func (r *R) bagof(isSet bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		r.collect(ctx, isSet, args, c, out)
+	}
+}
+
+func (r *R) collect(ctx context.Context, isSet bool, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	template := c.Ground(args[0])
+	goal, existential := stripExistentials(c.Ground(args[1]))
+
+	// the witness is made up of all of the free variables in the goal
+	excluded := make(map[string]bool)
+	for _, v := range termVariables(template) {
+		excluded[v.String()] = true
+	}
+	for _, v := range existential {
+		excluded[v.String()] = true
+	}
+	free := []ast.Term{}
+	for _, v := range termVariables(goal) {
+		if !excluded[v.String()] {
+			excluded[v.String()] = true
+			free = append(free, v)
+		}
+	}
+	witness := ast.CreateFact("w", free...)
+
+	// collect all of the Witness-Template pairs and group them by the witness
+	pairs, ex := r.findSolutions(ctx, ast.CreateFact("-", witness, template), goal, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	groups := []*bagofGroup{}
+	byKey := make(map[string]*bagofGroup)
+	for _, p := range pairs {
+		pair := p.(*ast.Fact)
+		key := variantKey(pair.Args[0])
+		g := byKey[key]
+		if g == nil {
+			g = &bagofGroup{witness: pair.Args[0]}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.templates = append(g.templates, pair.Args[1])
+	}
+
+	if isSet {
+		sort.SliceStable(groups, func(i, j int) bool {
+			return ast.CompareTerms(groups[i].witness, groups[j].witness) < 0
+		})
+	}
+
+	for _, g := range groups {
+		items := g.templates
+		if isSet {
+			items = sortTerms(items, true)
+		}
+
+		b := unifyTerms(witness, g.witness, c)
+		if b == nil {
+			continue
+		}
+		b = unifyTerms(args[2], ast.CreateList(items...), b)
+		if b != nil && !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+// stripExistentials removes any `V^` prefixes from a goal and returns the goal along with the quantified variables
+func stripExistentials(goal ast.Term) (ast.Term, []*ast.Variable) {
+	vars := []*ast.Variable{}
+	for goal.GetType() == ast.T_Fact {
+		f := goal.(*ast.Fact)
+		if f.Head != "^" || len(f.Args) != 2 {
+			break
+		}
+		vars = append(vars, termVariables(f.Args[0])...)
+		goal = f.Args[1]
+	}
+	return goal, vars
+}
+
+// termVariables returns the variables in any term in order of appearance
+func termVariables(t ast.Term) []*ast.Variable {
+	switch t.GetType() {
+	case ast.T_Variable:
+		return []*ast.Variable{t.(*ast.Variable)}
+	case ast.T_Fact:
+		return t.(*ast.Fact).ExtractVariables()
+	}
+	return []*ast.Variable{}
+}
+
+// variantKey returns a string which is the same for any two terms that are variants of each other
+func variantKey(t ast.Term) string {
+	mappings := make(map[string]string)
+	f, _ := ast.CreateFact("", t).Anonymize(0, "_V", &mappings)
+	return f.Args[0].String()
+}
+
+// sortTerms sorts a copy of the terms in the standard order, optionally removing duplicates
+func sortTerms(terms []ast.Term, dedupe bool) []ast.Term {
+	sorted := make([]ast.Term, len(terms))
+	copy(sorted, terms)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ast.CompareTerms(sorted[i], sorted[j]) < 0
+	})
+	if !dedupe {
+		return sorted
+	}
+
+	ret := []ast.Term{}
+	for i, t := range sorted {
+		if i > 0 && ast.CompareTerms(sorted[i-1], t) == 0 {
+			continue
+		}
+		ret = append(ret, t)
+	}
+	return ret
+}
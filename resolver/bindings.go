@@ -11,6 +11,12 @@ import (
 
 type Bindings struct {
 	B map[string]ast.Term
+
+	// Exception is set when the bindings are carrying a thrown term rather than a solution, see Throw
+	Exception ast.Term
+
+	// proofs are the proofs of the goals solved so far, only recorded WithExplain
+	proofs *proofList
 }
 
 func EmptyBindings() *Bindings {
@@ -20,7 +26,7 @@ func EmptyBindings() *Bindings {
 }
 
 func CreateBindings(m map[string]ast.Term) *Bindings {
-	return &Bindings{m}
+	return &Bindings{B: m}
 }
 
 func (b *Bindings) Empty() bool {
@@ -58,7 +64,7 @@ func (b *Bindings) Clone() *Bindings {
 	for i, v := range b.B {
 		newMap[i] = v
 	}
-	return &Bindings{newMap}
+	return &Bindings{B: newMap, proofs: b.proofs}
 }
 
 func (b *Bindings) Bind(k string, v ast.Term) bool {
@@ -127,7 +133,8 @@ func (b *Bindings) Ground(t ast.Term) ast.Term {
 		if d.GetType() == ast.T_Variable {
 			return t
 		}
-		return d
+		// the bound value may itself contain variables, so keep grounding
+		return b.Ground(d)
 	case ast.T_Atom:
 		fallthrough
 	case ast.T_String:
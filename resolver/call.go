@@ -0,0 +1,248 @@
+package resolver
+
+import (
+	"context"
+	"log"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * ResolveTerm treats the given term as a goal and resolves it.
+ * This is how builtins that accept goals as arguments (findall/3, bagof/3 etc..) run them.
+ *   - atoms are called as facts with no args
+ *   - `,/2` is treated as a conjunction
+ *   - `^/2` calls its second argument (the existential variables only matter to bagof/setof)
+ * Anything else that isnt callable (numbers, strings, unbound variables) will simply fail.
+ */
+func (r *R) ResolveTerm(ctx context.Context, t ast.Term, c *Bindings, out chan<- *Bindings) {
+	q := termToQuery(t, c)
+	if q == nil {
+		log.Printf("[DEBUG][ResolveTerm] %s is not callable", t)
+		close(out)
+		return
+	}
+	r.ResolveQuery(ctx, q, c, out)
+}
+
+// termToQuery converts a callable term into a Query, returning nil if it isnt callable
+func termToQuery(t ast.Term, c *Bindings) *ast.Query {
+	t = c.Dereference(t)
+	switch t.GetType() {
+	case ast.T_Atom:
+		return &ast.Query{ast.CreateFact(t.String())}
+	case ast.T_Fact:
+		f := t.(*ast.Fact)
+		if f.Head == "," && len(f.Args) == 2 {
+			lhs := termToQuery(f.Args[0], c)
+			rhs := termToQuery(f.Args[1], c)
+			if lhs == nil || rhs == nil {
+				return nil
+			}
+			q := append(*lhs, *rhs...)
+			return &q
+		}
+		if f.Head == "^" && len(f.Args) == 2 {
+			return termToQuery(f.Args[1], c)
+		}
+		return &ast.Query{f}
+	}
+	return nil
+}
+
+/**
+ * findSolutions resolves the goal and collects a copy of the template for every solution.
+ * Each copy is fully grounded and any variables left in it are renamed so that
+ * the results dont share variables with each other or with the goal.
+ * If the goal raises an exception, the exception is returned instead.
+ */
+func (r *R) findSolutions(ctx context.Context, template ast.Term, goal ast.Term, c *Bindings) ([]ast.Term, *Bindings) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := []ast.Term{}
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(ctx, goal, c, solutions)
+	for s := range solutions {
+		if s.IsException() {
+			return nil, s
+		}
+		results = append(results, r.renameTerm(s.Ground(template)))
+	}
+	return results, nil
+}
+
+/**
+ * solveOnce resolves the goal and returns the bindings for the first solution or nil if there are none.
+ * The bindings may be carrying an exception, callers need to check IsException.
+ * The search for any other solutions is cancelled before returning.
+ */
+func (r *R) solveOnce(ctx context.Context, goal ast.Term, c *Bindings) *Bindings {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(ctx, goal, c, solutions)
+	b, ok := <-solutions
+	if !ok {
+		return nil
+	}
+	return b
+}
+
+// addArgs appends extra arguments to a callable term, returning nil if it isnt callable
+func addArgs(goal ast.Term, extra []ast.Term) ast.Term {
+	switch goal.GetType() {
+	case ast.T_Atom:
+		return ast.CreateFact(goal.String(), extra...)
+	case ast.T_Fact:
+		f := goal.(*ast.Fact)
+		if f.Head == ":" && len(f.Args) == 2 {
+			// the arguments go on the goal that is qualified rather than on the module
+			inner := addArgs(f.Args[1], extra)
+			if inner == nil {
+				return nil
+			}
+			return ast.CreateFact(":", f.Args[0], inner)
+		}
+		args := make([]ast.Term, 0, len(f.Args)+len(extra))
+		args = append(args, f.Args...)
+		args = append(args, extra...)
+		return ast.CreateFact(f.Head, args...)
+	}
+	return nil
+}
+
+/**
+ * newCall provides call/1 through call/8.
+ * call(Goal, A1, ..., An) adds the extra arguments to the end of Goal and then resolves it.
+ * It also provides the predicates which only look for the first solution of a goal:
+ *   \+ Goal and not(Goal) succeed without binding anything if Goal has no solutions
+ *   once(Goal) is the first solution of Goal and ignore(Goal) is the same but succeeds when there isnt one
+ *   forall(Cond, Action) succeeds if Action has a solution for every solution of Cond
+ * The control constructs the resolver doesnt run raise an existence_error rather than failing like an unknown predicate.
+ */
+func newCall(r *R) nativePredicates {
+	preds := nativePredicates{
+		"\\+/1":    r.not,
+		"not/1":    r.not,
+		"once/1":   r.once(false),
+		"ignore/1": r.once(true),
+		"forall/2": r.forall,
+	}
+	for arity := 1; arity <= 8; arity++ {
+		preds[signature("call", arity)] = r.call
+	}
+	for _, u := range unsupportedConstructs {
+		preds[signature(u.name, u.arity)] = unsupportedConstruct(u.name, u.arity)
+	}
+	return preds
+}
+
+// unsupportedConstructs are the control constructs without a resolution strategy, i.e. disjunction, if-then-else and cut
+var unsupportedConstructs = []struct {
+	name  string
+	arity int
+}{{";", 2}, {"->", 2}, {"*->", 2}, {"!", 0}}
+
+// unsupportedConstruct raises `existence_error(procedure, Name/Arity)` for a control construct the resolver cant run
+func unsupportedConstruct(name string, arity int) nativePredicate {
+	indicator := ast.CreateFact("/", ast.CreateAtom(name), ast.CreateNumericLiteral(float64(arity)))
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		send(ctx, out, existenceError("procedure", indicator))
+	}
+}
+
+func (r *R) call(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	goal := c.Dereference(args[0])
+	if isQualified(goal) {
+		// the goal inside Module:Goal may be a bound variable too
+		goal = c.Ground(goal)
+	}
+	goal = addArgs(goal, args[1:])
+	if goal == nil {
+		return
+	}
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(ctx, goal, c, solutions)
+	for b := range solutions {
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+// goalArg returns the goal passed to a builtin, or the exception to raise if it isnt callable
+func goalArg(t ast.Term, c *Bindings) (ast.Term, *Bindings) {
+	goal := c.Dereference(t)
+	if isQualified(goal) {
+		goal = c.Ground(goal)
+	}
+	switch goal.GetType() {
+	case ast.T_Variable:
+		return nil, instantiationError()
+	case ast.T_Atom, ast.T_Fact:
+		return goal, nil
+	}
+	return nil, typeError("callable", goal)
+}
+
+func (r *R) not(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	goal, ex := goalArg(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if b := r.solveOnce(ctx, goal, c); b == nil {
+		send(ctx, out, c)
+	} else if b.IsException() {
+		send(ctx, out, b)
+	}
+}
+
+// once provides once/1, and ignore/1 when it succeeds without a solution too
+func (r *R) once(ignore bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		goal, ex := goalArg(args[0], c)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		if b := r.solveOnce(ctx, goal, c); b != nil {
+			send(ctx, out, b)
+		} else if ignore {
+			send(ctx, out, c)
+		}
+	}
+}
+
+func (r *R) forall(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	cond, ex := goalArg(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(ctx, cond, c, solutions)
+	for b := range solutions {
+		if b.IsException() {
+			send(ctx, out, b)
+			return
+		}
+		// the action can use the bindings of each solution of the condition
+		action, ex := goalArg(args[1], b)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		if done := r.solveOnce(ctx, action, b); done == nil {
+			return
+		} else if done.IsException() {
+			send(ctx, out, done)
+			return
+		}
+	}
+	if ctx.Err() == nil {
+		send(ctx, out, c)
+	}
+}
@@ -0,0 +1,57 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestNegation(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+	r := resolver.New(familyIndex())
+
+	for _, name := range []string{"\\+", "not"} {
+		expectOne(t, name+" without a solution", solve(r, f(name, f("parent", a("ann"), v("X")))), nil)
+		if results := solve(r, f(name, f("parent", a("tom"), v("X")))); len(results) != 0 {
+			t.Errorf("%s with a solution: expected it to fail, got %v", name, results)
+		}
+		// nothing the goal bound is kept
+		expectOne(t, name+" doesnt bind", solve(r, f(name, f(name, f("parent", a("tom"), v("X"))))), map[string]string{"X": "X"})
+		expectError(t, name+" unbound", solve(r, f(name, v("G"))), "instantiation_error")
+		expectError(t, name+" not callable", solve(r, f(name, num(1))), "type_error(callable,1.000000)")
+	}
+}
+
+func TestOnce(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+	r := resolver.New(familyIndex())
+
+	expectOne(t, "once", solve(r, f("once", f("parent", v("X"), v("Y")))), map[string]string{"X": "tom", "Y": "bob"})
+	if results := solve(r, f("once", f("parent", a("ann"), v("X")))); len(results) != 0 {
+		t.Errorf("once without a solution: expected it to fail, got %v", results)
+	}
+	expectOne(t, "ignore", solve(r, f("ignore", f("parent", v("X"), a("ann")))), map[string]string{"X": "bob"})
+	expectOne(t, "ignore without a solution", solve(r, f("ignore", f("parent", a("ann"), v("X")))), map[string]string{"X": "X"})
+}
+
+func TestForall(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+	r := resolver.New(familyIndex())
+
+	// every parent of a grandchild is a child of tom
+	expectOne(t, "forall holds", solve(r, f("forall", f("grandparent", a("tom"), v("Z")), f("parent", v("Y"), v("Z")))), nil)
+	if results := solve(r, f("forall", f("parent", v("X"), v("Y")), f("parent", v("Y"), v("_")))); len(results) != 0 {
+		t.Errorf("forall with a counterexample: expected it to fail, got %v", results)
+	}
+	expectOne(t, "forall without any solutions", solve(r, f("forall", f("parent", a("ann"), v("X")), f("fail"))), nil)
+	// the action can be bound by the condition
+	expectOne(t, "forall bound action", solve(r, f("forall", f("=", v("G"), f("true")), v("G"))), nil)
+	expectError(t, "forall unbound action", solve(r, f("forall", f("true"), v("G"))), "instantiation_error")
+}
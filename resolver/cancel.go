@@ -0,0 +1,20 @@
+package resolver
+
+import (
+	"context"
+)
+
+/**
+ * Every goroutine in the resolver writes its results with send so that once the query's context is cancelled nothing is left blocked on a channel that is no longer read.
+ * Producers should stop looking for solutions as soon as send returns false.
+ */
+
+// send writes b to out, returning false instead if the context is cancelled first
+func send(ctx context.Context, out chan<- *Bindings, b *Bindings) bool {
+	select {
+	case out <- b:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
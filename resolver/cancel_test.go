@@ -0,0 +1,137 @@
+package resolver_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// waitForGoroutines waits for the number of goroutines to drop back to the baseline, failing the test if it doesnt
+func waitForGoroutines(t *testing.T, label string, baseline int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Errorf("%s: leaked %d goroutines", label, runtime.NumGoroutine()-baseline)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCancelStopsGoroutines(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	i := indexer.NewDefault()
+	// loop(X) :- loop(X).
+	i.IndexStatement(ast.CreateRule(f("loop", v("X")), f("loop", v("X"))))
+	// nat(z).
+	// nat(s(X)) :- nat(X).
+	i.IndexStatement(f("nat", a("z")))
+	i.IndexStatement(ast.CreateRule(f("nat", f("s", v("X"))), f("nat", v("X"))))
+
+	cases := []struct {
+		label string
+		query *ast.Query
+		// read is the number of solutions to read before cancelling
+		read int
+	}{
+		{"infinite builtin", &ast.Query{f("length", v("L"), v("N"))}, 3},
+		{"infinite recursion without solutions", &ast.Query{f("loop", a("x"))}, 0},
+		{"infinite rule", &ast.Query{f("nat", v("X"))}, 5},
+		{"conjunction", &ast.Query{f("nat", v("X")), f("length", v("L"), v("N"))}, 2},
+		{"meta call", &ast.Query{f("call", f("nat", v("X")))}, 2},
+		{"catch", &ast.Query{f("catch", f("nat", v("X")), v("_"), a("true"))}, 2},
+	}
+
+	r := resolver.New(i)
+	for _, c := range cases {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		out := make(chan *resolver.Bindings)
+		go r.ResolveStatementList(ctx, []ast.Statement{c.query}, resolver.EmptyBindings(), out)
+
+		for n := 0; n < c.read; n++ {
+			if _, ok := <-out; !ok {
+				t.Fatalf("%s: expected at least %d solutions", c.label, c.read)
+			}
+		}
+		if c.read == 0 {
+			// give the search a chance to get going
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel()
+		waitForGoroutines(t, c.label, baseline)
+	}
+}
+
+func TestNoLeaksAfterCompletion(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	cases := []struct {
+		label string
+		query *ast.Query
+	}{
+		// each of these only needs the first solution of a goal with several solutions
+		{"with_output_to", &ast.Query{f("with_output_to", f("atom", v("A")), f("member", v("X"), l(a("a"), a("b"))))}},
+		{"include", &ast.Query{f("include", f("memberchk", v("_")), l(l(a("a"), a("b"))), v("I"))}},
+		{"exception", &ast.Query{f("catch", f(",", f("length", v("L"), v("N")), f("throw", a("x"))), a("x"), a("true"))}},
+	}
+
+	i := indexer.NewDefault()
+	// member(X, [X|_]).
+	// member(X, [_|T]) :- member(X, T).
+	i.IndexStatement(f("member", v("X"), ast.CreatePartialList([]ast.Term{v("X")}, v("_"))))
+	i.IndexStatement(ast.CreateRule(f("member", v("X"), ast.CreatePartialList([]ast.Term{v("_")}, v("T"))), f("member", v("X"), v("T"))))
+	r := resolver.New(i)
+
+	for _, c := range cases {
+		baseline := runtime.NumGoroutine()
+		out := make(chan *resolver.Bindings)
+		go r.ResolveStatementList(context.Background(), []ast.Statement{c.query}, resolver.EmptyBindings(), out)
+		for range out {
+		}
+		waitForGoroutines(t, c.label, baseline)
+	}
+}
+
+// countResolver is a FactResolver for count(N) which gives N = 1..5 without looking at the context
+type countResolver struct{}
+
+func (countResolver) Resolve(ctx context.Context, f *ast.Fact, c *resolver.Bindings, out chan<- *resolver.Bindings, matched chan<- bool) {
+	if f.Head != "count" || len(f.Args) != 1 {
+		matched <- false
+		return
+	}
+	for n := 1; n <= 5; n++ {
+		b := c.Clone()
+		b.Bind(f.Args[0].String(), ast.CreateNumericLiteral(float64(n)))
+		out <- b
+	}
+	matched <- true
+}
+
+func TestCancelFactResolver(t *testing.T) {
+	r := resolver.New(indexer.NewDefault())
+	r.AddFactResolver(countResolver{})
+
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *resolver.Bindings)
+	q := &ast.Query{ast.CreateFact("count", ast.CreateVariable("N"))}
+	go r.ResolveStatementList(ctx, []ast.Statement{q}, resolver.EmptyBindings(), out)
+	if _, ok := <-out; !ok {
+		t.Fatal("expected a solution")
+	}
+	cancel()
+	waitForGoroutines(t, "fact resolver", baseline)
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * compare implements compare/3.
+ * compare(Order, A, B) unifies Order with <, > or = depending on how A and B compare
+ * in the standard order of terms. This is mostly useful for writing predicates for predsort/3.
+ */
+func compare(args []ast.Term, c *Bindings) (*Bindings, bool, error) {
+	order := "="
+	switch ast.CompareTerms(c.Ground(args[1]), c.Ground(args[2])) {
+	case -1:
+		order = "<"
+	case 1:
+		order = ">"
+	}
+
+	b := unifyTerms(args[0], ast.CreateAtom(order), c)
+	return b, b != nil, nil
+}
+
+/**
+ * newCompare provides the builtins which compare two terms:
+ *   A == B, A \== B, A @< B, A @> B, A @=< B and A @>= B compare them in the standard order of terms without binding anything
+ *   A \= B succeeds if A and B dont unify
+ *   A =:= B, A =\= B, A < B, A > B, A =< B and A >= B evaluate both sides and compare the numbers
+ */
+func newCompare() nativePredicates {
+	return nativePredicates{
+		"==/2":   standardOrder(func(o int) bool { return o == 0 }),
+		"\\==/2": standardOrder(func(o int) bool { return o != 0 }),
+		"@</2":   standardOrder(func(o int) bool { return o < 0 }),
+		"@>/2":   standardOrder(func(o int) bool { return o > 0 }),
+		"@=</2":  standardOrder(func(o int) bool { return o <= 0 }),
+		"@>=/2":  standardOrder(func(o int) bool { return o >= 0 }),
+		"\\=/2": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			if unifyTerms(args[0], args[1], c) == nil {
+				send(ctx, out, c)
+			}
+		},
+		"=:=/2":  arithmeticOrder(func(a, b float64) bool { return a == b }),
+		"=\\=/2": arithmeticOrder(func(a, b float64) bool { return a != b }),
+		"</2":    arithmeticOrder(func(a, b float64) bool { return a < b }),
+		">/2":    arithmeticOrder(func(a, b float64) bool { return a > b }),
+		"=</2":   arithmeticOrder(func(a, b float64) bool { return a <= b }),
+		">=/2":   arithmeticOrder(func(a, b float64) bool { return a >= b }),
+	}
+}
+
+// standardOrder succeeds if test holds for the way the arguments compare, see ast.CompareTerms
+func standardOrder(test func(int) bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		if test(ast.CompareTerms(c.Ground(args[0]), c.Ground(args[1]))) {
+			send(ctx, out, c)
+		}
+	}
+}
+
+// arithmeticOrder evaluates both arguments and succeeds if test holds for their values
+func arithmeticOrder(test func(a, b float64) bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		values := [2]float64{}
+		for i, arg := range args {
+			v, err := evalArithmetic(arg, c)
+			if err == ErrUnboundVariable {
+				send(ctx, out, instantiationError())
+				return
+			} else if err != nil {
+				send(ctx, out, typeError("evaluable", c.Ground(arg)))
+				return
+			}
+			values[i] = v
+		}
+		if test(values[0], values[1]) {
+			send(ctx, out, c)
+		}
+	}
+}
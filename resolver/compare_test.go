@@ -0,0 +1,97 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestCompareBuiltins(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	r := resolver.New(indexer.NewDefault())
+
+	cases := []struct {
+		label string
+		goal  *ast.Fact
+		holds bool
+	}{
+		{"== on equal terms", f("==", f("p", num(1), a("a")), f("p", num(1), a("a"))), true},
+		{"== on different terms", f("==", num(3), num(4)), false},
+		{"== doesnt bind", f("==", v("X"), a("a")), false},
+		{"== on the same variable", f("==", v("X"), v("X")), true},
+		{"\\== on different terms", f("\\==", num(3), num(4)), true},
+		{"@< numbers before atoms", f("@<", num(9), a("a")), true},
+		{"@> compound after atoms", f("@>", f("p", a("a")), a("z")), true},
+		{"@=< equal", f("@=<", a("a"), a("a")), true},
+		{"@>= smaller", f("@>=", a("a"), a("b")), false},
+		{"\\= on terms that dont unify", f("\\=", f("p", a("a")), f("p", a("b"))), true},
+		{"\\= on terms that unify", f("\\=", f("p", v("X")), f("p", a("b"))), false},
+		{"=:= evaluates", f("=:=", f("+", num(1), num(2)), num(3)), true},
+		{"=\\= evaluates", f("=\\=", num(1), num(2)), true},
+		{"<", f("<", num(1), num(2)), true},
+		{">", f(">", num(1), num(2)), false},
+		{"=<", f("=<", num(2), num(2)), true},
+		{">=", f(">=", f("*", num(2), num(3)), num(7)), false},
+	}
+	for _, c := range cases {
+		results := solve(r, c.goal)
+		if c.holds {
+			expectOne(t, c.label, results, nil)
+		} else if len(results) != 0 {
+			t.Errorf("%s: expected it to fail, got %v", c.label, results)
+		}
+	}
+
+	expectError(t, "< unbound", solve(r, f("<", v("X"), num(2))), "instantiation_error")
+	expectError(t, "< not evaluable", solve(r, f("<", a("foo"), num(2))), "type_error(evaluable,foo)")
+}
+
+func TestTypeChecks(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	r := resolver.New(indexer.NewDefault())
+
+	terms := map[string]ast.Term{
+		"var":      v("X"),
+		"atom":     a("a"),
+		"empty":    ast.CreateList(),
+		"integer":  num(3),
+		"float":    num(1.5),
+		"string":   ast.CreateStringLiteral("s"),
+		"compound": f("p", v("Y")),
+		"list":     ast.CreateList(num(1), num(2)),
+	}
+	expected := map[string][]string{
+		"var":      {"var"},
+		"nonvar":   {"atom", "empty", "integer", "float", "string", "compound", "list"},
+		"atom":     {"atom", "empty"},
+		"number":   {"integer", "float"},
+		"integer":  {"integer"},
+		"float":    {"float"},
+		"atomic":   {"atom", "empty", "integer", "float", "string"},
+		"compound": {"compound", "list"},
+		"callable": {"atom", "empty", "compound", "list"},
+		"string":   {"string"},
+		"is_list":  {"empty", "list"},
+		"ground":   {"atom", "empty", "integer", "float", "string", "list"},
+	}
+	for check, holds := range expected {
+		for name, term := range terms {
+			shouldHold := false
+			for _, h := range holds {
+				shouldHold = shouldHold || h == name
+			}
+			if results := solve(r, f(check, term)); (len(results) == 1) != shouldHold {
+				t.Errorf("%s(%s): expected %v, got %v", check, term, shouldHold, results)
+			}
+		}
+	}
+
+	// the checks look at what a variable is bound to
+	expectOne(t, "bound variable", solve(r, f("=", v("X"), num(1)), f("integer", v("X"))), map[string]string{"X": "1.000000"})
+}
@@ -0,0 +1,272 @@
+package resolver
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+/**
+ * Coverage counts how many times each clause from a source file has been used, a clause is used each time its head
+ * unifies with a goal. Only clauses that know the file they came from are counted, see ast.SetFile.
+ * Counts are kept for each indexed clause rather than each position, since term_expansion/2 and grammar rules
+ * can turn one clause in the source into several which all share its position.
+ */
+type Coverage struct {
+	lock   sync.Mutex
+	counts map[ast.Statement]int64
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{counts: make(map[ast.Statement]int64)}
+}
+
+// WithCoverage counts the clauses used by every query into c
+func WithCoverage(c *Coverage) Option {
+	return func(r *R) {
+		r.coverage = c
+	}
+}
+
+func (c *Coverage) hit(clause ast.Statement, pos ast.Position) {
+	if c == nil || pos.File == "" {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[clause]++
+}
+
+// ClauseCoverage is the number of times a single clause was used
+type ClauseCoverage struct {
+	Pos ast.Position
+	// Clause is the index of the clause among the ones expanded from the same source clause, 0 for the first
+	Clause    int
+	Signature string
+	Count     int64
+}
+
+/**
+ * Clauses lists every clause in the indexer which came from a file along with how many times it was used,
+ * ordered by file and then by line. The indexer has to implement indexer.Lister to find the clauses that were never used.
+ */
+func (c *Coverage) Clauses(i indexer.Indexer) []ClauseCoverage {
+	lister, ok := i.(indexer.Lister)
+	if !ok {
+		return []ClauseCoverage{}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ret := []ClauseCoverage{}
+	expanded := make(map[ast.Position]int)
+	for _, s := range lister.Statements() {
+		var pos ast.Position
+		var sig *ast.Signature
+		switch clause := s.(type) {
+		case *ast.Fact:
+			pos, sig = clause.Pos, clause.Signature()
+		case *ast.Rule:
+			pos, sig = clause.Pos, clause.Signature()
+		default:
+			continue
+		}
+		if pos.File == "" {
+			continue
+		}
+		ret = append(ret, ClauseCoverage{Pos: pos, Clause: expanded[pos], Signature: sig.String(), Count: c.counts[s]})
+		expanded[pos]++
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		a, b := ret[i].Pos, ret[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return ret[i].Clause < ret[j].Clause
+	})
+	return ret
+}
+
+// coveredPercent is the percentage of the clauses that were used at least once
+func coveredPercent(clauses []ClauseCoverage) float64 {
+	if len(clauses) == 0 {
+		return 0
+	}
+	covered := 0
+	for _, c := range clauses {
+		if c.Count > 0 {
+			covered++
+		}
+	}
+	return 100 * float64(covered) / float64(len(clauses))
+}
+
+/**
+ * WriteCoverageText writes one line for each clause with the number of times it was used, followed by the
+ * percentage of clauses covered in each file and in total. When a source clause was expanded into several
+ * clauses each one is numbered after its line:
+ *
+ *	family.pl:1:	parent/2	2
+ *	family.pl:3:	grandparent/2	0
+ *	family.pl:5#1:	greeting/2	1
+ *	family.pl:5#2:	greeting/2	0
+ *	family.pl:	50.0% of 4 clauses
+ *	total:	50.0% of 4 clauses
+ */
+func WriteCoverageText(w io.Writer, clauses []ClauseCoverage) error {
+	expanded := make(map[ast.Position]bool)
+	for _, c := range clauses {
+		if c.Clause > 0 {
+			expanded[c.Pos] = true
+		}
+	}
+	files := coverageFiles(clauses)
+	for _, file := range files {
+		for _, c := range clauses {
+			if c.Pos.File != file {
+				continue
+			}
+			line := fmt.Sprintf("%d", c.Pos.Line)
+			if expanded[c.Pos] {
+				line = fmt.Sprintf("%d#%d", c.Pos.Line, c.Clause+1)
+			}
+			if _, err := fmt.Fprintf(w, "%s:%s:\t%s\t%d\n", file, line, c.Signature, c.Count); err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range files {
+		inFile := []ClauseCoverage{}
+		for _, c := range clauses {
+			if c.Pos.File == file {
+				inFile = append(inFile, c)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s:\t%.1f%% of %d clauses\n", file, coveredPercent(inFile), len(inFile)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "total:\t%.1f%% of %d clauses\n", coveredPercent(clauses), len(clauses))
+	return err
+}
+
+// coverageFiles returns the files the clauses came from in the order they first appear
+func coverageFiles(clauses []ClauseCoverage) []string {
+	files := []string{}
+	seen := make(map[string]bool)
+	for _, c := range clauses {
+		if !seen[c.Pos.File] {
+			seen[c.Pos.File] = true
+			files = append(files, c.Pos.File)
+		}
+	}
+	return files
+}
+
+// coverageLine is a line of source in the HTML report, Class is covered, uncovered or empty for lines outside any clause
+type coverageLine struct {
+	Number int
+	Text   string
+	Class  string
+	Count  string
+}
+
+type coverageFile struct {
+	Name    string
+	Percent float64
+	Lines   []coverageLine
+}
+
+/**
+ * WriteCoverageHTML writes a page showing the source of each file with the clauses that were used in green
+ * and the ones that werent in red. The files are read again to show their source, when a file cant be read
+ * its clauses are listed by signature instead. A source clause expanded into several clauses is red unless
+ * all of them were used and its count is their total.
+ */
+func WriteCoverageHTML(w io.Writer, clauses []ClauseCoverage) error {
+	files := []coverageFile{}
+	for _, name := range coverageFiles(clauses) {
+		inFile := []ClauseCoverage{}
+		for _, c := range clauses {
+			if c.Pos.File == name {
+				inFile = append(inFile, c)
+			}
+		}
+		file := coverageFile{Name: name, Percent: coveredPercent(inFile)}
+
+		src, err := ioutil.ReadFile(name)
+		if err == nil {
+			for i, text := range strings.Split(strings.TrimRight(string(src), "\n"), "\n") {
+				file.Lines = append(file.Lines, coverageLine{Number: i + 1, Text: text})
+			}
+		}
+		totals := make(map[ast.Position]int64)
+		for _, c := range inFile {
+			totals[c.Pos] += c.Count
+		}
+		for _, c := range inFile {
+			class := "covered"
+			if c.Count == 0 {
+				class = "uncovered"
+			}
+			if err != nil {
+				count := fmt.Sprintf("%d", c.Count)
+				file.Lines = append(file.Lines, coverageLine{Number: c.Pos.Line, Text: c.Signature, Class: class, Count: count})
+				continue
+			}
+			for line := c.Pos.Line; line <= c.Pos.EndLine && line <= len(file.Lines); line++ {
+				if file.Lines[line-1].Class != "uncovered" {
+					file.Lines[line-1].Class = class
+				}
+			}
+			if c.Pos.Line <= len(file.Lines) {
+				file.Lines[c.Pos.Line-1].Count = fmt.Sprintf("%d", totals[c.Pos])
+			}
+		}
+		files = append(files, file)
+	}
+	return coverageTemplate.Execute(w, struct {
+		Files   []coverageFile
+		Percent float64
+	}{files, coveredPercent(clauses)})
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gopl coverage</title>
+<style>
+body { background: black; color: rgb(80, 80, 80); font-family: Menlo, monospace; }
+h2 { color: rgb(200, 200, 200); font-size: 14px; }
+table { border-collapse: collapse; }
+td { padding: 0 8px; white-space: pre; }
+td.number, td.count { text-align: right; color: rgb(80, 80, 80); }
+tr.covered td.source { color: rgb(44, 212, 149); }
+tr.uncovered td.source { color: rgb(192, 0, 0); }
+</style>
+</head>
+<body>
+<h2>total: {{printf "%.1f" .Percent}}% of clauses covered</h2>
+{{range .Files}}
+<h2>{{.Name}} ({{printf "%.1f" .Percent}}%)</h2>
+<table>
+{{range .Lines}}<tr class="{{.Class}}"><td class="number">{{.Number}}</td><td class="count">{{.Count}}</td><td class="source">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,134 @@
+package resolver_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func TestCoverageReports(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	src, err := ioutil.TempFile("", "family*.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(src.Name())
+	if _, err := src.WriteString("parent(tom, bob).\nparent(bob, ann).\nlikes(X, X).\ncolour(red).\n"); err != nil {
+		t.Fatal(err)
+	}
+	src.Close()
+
+	clause := func(fact *ast.Fact, line int) *ast.Fact {
+		fact.Pos = ast.Position{File: src.Name(), Line: line, Column: 1, EndLine: line, EndColumn: 17}
+		return fact
+	}
+	i := indexer.NewDefault()
+	i.IndexStatement(clause(f("parent", a("tom"), a("bob")), 1))
+	i.IndexStatement(clause(f("parent", a("bob"), a("ann")), 2))
+	i.IndexStatement(clause(f("likes", v("X"), v("X")), 3))
+	// both of these stand for the clause on line 4, like the clauses term_expansion/2 returns for it
+	i.IndexStatement(clause(f("colour", a("red")), 4))
+	i.IndexStatement(clause(f("colour", a("green")), 4))
+	// facts without a file arent part of the report
+	i.IndexStatement(f("parent", a("ann"), a("joe")))
+
+	coverage := resolver.NewCoverage()
+	r := resolver.New(i, resolver.WithCoverage(coverage))
+	if results := solve(r, f("parent", v("X"), v("Y"))); len(results) != 3 {
+		t.Fatalf("expected 3 solutions, got %v", results)
+	}
+	solve(r, f("parent", a("tom"), v("Y")))
+	solve(r, f("colour", a("green")))
+
+	var text bytes.Buffer
+	if err := resolver.WriteCoverageText(&text, coverage.Clauses(i)); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		src.Name() + ":1:\tparent/2\t2",
+		src.Name() + ":2:\tparent/2\t1",
+		src.Name() + ":3:\tlikes/2\t0",
+		src.Name() + ":4#1:\tcolour/1\t0",
+		src.Name() + ":4#2:\tcolour/1\t1",
+		src.Name() + ":\t60.0% of 5 clauses",
+		"total:\t60.0% of 5 clauses",
+		"",
+	}, "\n")
+	if text.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text.String())
+	}
+
+	var html bytes.Buffer
+	if err := resolver.WriteCoverageHTML(&html, coverage.Clauses(i)); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<tr class="covered"><td class="number">1</td><td class="count">2</td><td class="source">parent(tom, bob).</td></tr>`,
+		`<tr class="uncovered"><td class="number">3</td><td class="count">0</td><td class="source">likes(X, X).</td></tr>`,
+		`<tr class="uncovered"><td class="number">4</td><td class="count">1</td><td class="source">colour(red).</td></tr>`,
+		"60.0%",
+	} {
+		if !strings.Contains(html.String(), s) {
+			t.Errorf("expected %q in\n%s", s, html.String())
+		}
+	}
+}
+
+func TestCoveragePositions(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	const filename = "family.pl"
+	src := "parent(tom, bob).\nparent(bob, ann).\nparent(ann,\n\tjoe).\ngrandparent(X, Z) :-\n  parent(X, Y),\n  parent(Y, Z).\n"
+	statements, err := syntax.Parse([]rune(src), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clauses which werent read from a file arent tracked
+	unfiled, err := syntax.Parse([]rune("parent(joe, sue)."), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := indexer.NewDefault()
+	for _, s := range append(statements, unfiled...) {
+		i.IndexStatement(s)
+	}
+
+	coverage := resolver.NewCoverage()
+	r := resolver.New(i, resolver.WithCoverage(coverage))
+	solve(r, f("grandparent", a("tom"), v("Who")))
+
+	clauses := coverage.Clauses(i)
+	expected := []struct {
+		line, column, endLine, endColumn int
+		count                            int64
+	}{
+		{1, 1, 1, 17, 1},
+		{2, 1, 2, 17, 1},
+		{3, 1, 4, 9, 0},
+		{5, 1, 7, 15, 1},
+	}
+	if len(clauses) != len(expected) {
+		t.Fatalf("expected %d clauses, got %v", len(expected), clauses)
+	}
+	for i, c := range expected {
+		pos := clauses[i].Pos
+		if pos.File != filename || pos.Line != c.line || pos.Column != c.column || pos.EndLine != c.endLine || pos.EndColumn != c.endColumn {
+			t.Errorf("clause %d: unexpected position %+v", i, pos)
+		}
+		if clauses[i].Count != c.count {
+			t.Errorf("clause %d: expected it to be used %d times, got %d", i, c.count, clauses[i].Count)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build plan9 || js || wasip1
+// +build plan9 js wasip1
+
+package resolver
+
+import "time"
+
+// processCPU cant be measured on this platform, so profiles show no CPU time
+func processCPU() time.Duration {
+	return 0
+}
@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * newPhrase provides phrase/2 and phrase/3 for running grammar rules.
+ *   phrase(Body, List)        - Body parses the whole of List
+ *   phrase(Body, List, Rest)  - Body parses the start of List, leaving Rest
+ * Body is translated the same way as the body of a grammar rule, so it can be a non-terminal
+ * like `greeting` or something like `([hello], name)`.
+ */
+func newPhrase(r *R) nativePredicates {
+	return nativePredicates{
+		"phrase/2": r.phrase,
+		"phrase/3": r.phrase,
+	}
+}
+
+func (r *R) phrase(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	body := c.Ground(args[0])
+	if body.GetType() == ast.T_Variable {
+		send(ctx, out, instantiationError())
+		return
+	}
+	list := c.Dereference(args[1])
+	if list.GetType() != ast.T_Variable && !isListCell(list) {
+		send(ctx, out, typeError("list", list))
+		return
+	}
+	var rest ast.Term = ast.CreateList()
+	if len(args) == 3 {
+		rest = args[2]
+	}
+
+	goals, err := ast.TranslateDCGBody(body, args[1], rest, r.freshVariable)
+	if err != nil {
+		send(ctx, out, typeError("callable", body))
+		return
+	}
+	q := ast.Query{}
+	for _, g := range goals {
+		q = append(q, g)
+	}
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveQuery(ctx, &q, c, solutions)
+	for b := range solutions {
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+// isListCell reports whether the term is `[]` or `[_|_]`
+func isListCell(t ast.Term) bool {
+	f, ok := t.(*ast.Fact)
+	return ok && f.Head == "|" && (len(f.Args) == 0 || len(f.Args) == 2)
+}
@@ -0,0 +1,64 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func TestPhrase(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	src := `
+greeting --> [hello], name.
+name --> [world].
+name --> [gopl].
+digits([D|T]) --> digit(D), digits(T).
+digits([D]) --> digit(D).
+digit(D) --> [D], { memberchk(D, [48, 49, 50, 51, 52, 53, 54, 55, 56, 57]) }.
+number(N) --> digits(Ds), { number_codes(N, Ds) }.
+ab --> "ab".
+peek(X), [X] --> [X].
+twice(G) --> call(G), call(G).
+`
+	statements, err := syntax.Parse([]rune(src), "grammar.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := indexer.NewDefault()
+	for _, st := range statements {
+		i.IndexStatement(st)
+	}
+	r := resolver.New(i)
+
+	expectOne(t, "phrase/2", solve(r, f("phrase", a("greeting"), l(a("hello"), a("gopl")))), map[string]string{})
+	expectOne(t, "phrase/3", solve(r, f("phrase", a("greeting"), l(a("hello"), a("world"), a("!")), v("R"))), map[string]string{"R": "L[!]"})
+	if got := solve(r, f("phrase", a("greeting"), l(a("hello"), a("there")))); len(got) != 0 {
+		t.Errorf("no parse: expected no solutions, got %v", got)
+	}
+	expectOne(t, "goals in braces", solve(r,
+		f("atom_codes", a("42"), v("Cs")),
+		f("phrase", f("number", v("N")), v("Cs")),
+	), map[string]string{"N": "42.000000"})
+	expectOne(t, "string literal", solve(r, f("atom_codes", a("ab"), v("Cs")), f("phrase", a("ab"), v("Cs"))), map[string]string{})
+	expectOne(t, "pushback", solve(r, f("phrase", f("peek", v("X")), l(a("a"), a("b")), v("R"))), map[string]string{"X": "a", "R": "L[a,b]"})
+	expectOne(t, "call//N", solve(r, f("phrase", f("twice", a("name")), l(a("world"), a("gopl")))), map[string]string{})
+	expectOne(t, "body", solve(r, f("phrase", f(",", l(a("hello")), a("name")), l(a("hello"), a("world")))), map[string]string{})
+	expectOne(t, "string body", solve(r, f("phrase", s("ab"), v("L"))), map[string]string{"L": "L[97.000000,98.000000]"})
+
+	expectError(t, "unbound body", solve(r, f("phrase", v("G"), l())), "instantiation_error")
+	expectError(t, "not a list", solve(r, f("phrase", a("greeting"), a("hello"))), "type_error(list,hello)")
+	expectError(t, "not callable", solve(r, f("phrase", num(1), l())), "type_error(callable,1.000000)")
+
+	_, err = syntax.Parse([]rune("either --> [a] ; [b].\n"), "grammar.pl")
+	if err == nil || err.Error() != "grammar.pl:1:1: ;/2 is not supported in grammar rules\neither --> [a] ; [b].\n^" {
+		t.Errorf("expected control constructs to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * Exceptions travel through the resolver as bindings with the Exception field set.
+ * Anything which reads bindings from a channel must check IsException, forward the exception
+ * to its own output and stop producing solutions.
+ * catch/3 is the only place that stops an exception from propagating.
+ */
+
+// Throw creates the bindings which carry the (grounded) ball up to the nearest catch/3
+func Throw(ball ast.Term) *Bindings {
+	b := EmptyBindings()
+	b.Exception = ball
+	return b
+}
+
+// IsException returns true if the bindings are carrying an exception instead of a solution
+func (b *Bindings) IsException() bool {
+	return b != nil && b.Exception != nil
+}
+
+// isoError wraps the formal term in `error(Formal, Context)` the way ISO builtins report errors
+func isoError(formal ast.Term) *Bindings {
+	return Throw(ast.CreateFact("error", formal, ast.CreateVariable("_")))
+}
+
+func instantiationError() *Bindings {
+	return isoError(ast.CreateAtom("instantiation_error"))
+}
+
+func uninstantiationError(culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("uninstantiation_error", culprit))
+}
+
+func typeError(kind string, culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("type_error", ast.CreateAtom(kind), culprit))
+}
+
+func domainError(kind string, culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("domain_error", ast.CreateAtom(kind), culprit))
+}
+
+func existenceError(kind string, culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("existence_error", ast.CreateAtom(kind), culprit))
+}
+
+func permissionError(action string, kind string, culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("permission_error", ast.CreateAtom(action), ast.CreateAtom(kind), culprit))
+}
+
+func representationError(flag string) *Bindings {
+	return isoError(ast.CreateFact("representation_error", ast.CreateAtom(flag)))
+}
+
+func syntaxError(message string) *Bindings {
+	return isoError(ast.CreateFact("syntax_error", ast.CreateAtom(message)))
+}
+
+// ioError reports an error from the operating system while reading or writing a stream
+func ioError(action string, culprit ast.Term) *Bindings {
+	return isoError(ast.CreateFact("io_error", ast.CreateAtom(action), culprit))
+}
+
+/**
+ * Exceptions implements throw/1 and catch/3.
+ *   throw(Ball) raises a copy of Ball as an exception.
+ *   catch(Goal, Catcher, Recovery) behaves like call(Goal), if an exception is raised which
+ *   unifies with Catcher, Recovery is called instead. Solutions found before the exception are kept.
+ */
+func newExceptions(r *R) nativePredicates {
+	return nativePredicates{
+		"throw/1": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			ball := c.Ground(args[0])
+			if ball.GetType() == ast.T_Variable {
+				send(ctx, out, instantiationError())
+				return
+			}
+			send(ctx, out, Throw(ball))
+		},
+		"catch/3": r.catch,
+	}
+}
+
+func (r *R) catch(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	// anything still running inside the goal is stopped once an exception is caught
+	goalCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(goalCtx, args[0], c, solutions)
+	for b := range solutions {
+		if !b.IsException() {
+			send(ctx, out, b)
+			continue
+		}
+
+		// the ball may be caught more than once (i.e. rethrown), so give it its own variables
+		ball := r.renameTerm(b.Exception)
+		caught := unifyTerms(args[1], ball, c)
+		if caught == nil {
+			send(ctx, out, b)
+			return
+		}
+
+		recovery := make(chan *Bindings, paralellism)
+		go r.ResolveTerm(ctx, args[2], caught, recovery)
+		for rb := range recovery {
+			send(ctx, out, rb)
+		}
+		return
+	}
+}
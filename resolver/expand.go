@@ -0,0 +1,154 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+// maxGoalExpansions is how many times a goal can be expanded before goal_expansion/2 is assumed to be looping
+const maxGoalExpansions = 100
+
+/**
+ * Expand passes a fact or rule through the term_expansion/2 and goal_expansion/2 hooks before it is indexed,
+ * which is how programs add their own syntax on top of the parser.
+ *   - term_expansion(Clause, Expanded) is called with the clause as a term (see ast.ClauseTerm), Expanded can be
+ *     a single clause or a list of them. The clause is kept as it is if the hook fails or isnt defined.
+ *   - goal_expansion(Goal, Expanded) is called on each goal in the body of the rules that come out of that.
+ *     The goals it returns are expanded again until the hook fails, so an expansion can use sugar itself.
+ * Queries are returned as they are. An exception raised by either hook is returned as an *Exception.
+ */
+func (r *R) Expand(ctx context.Context, s ast.Statement) ([]ast.Statement, error) {
+	if s.GetType() != ast.T_Fact && s.GetType() != ast.T_Rule {
+		return []ast.Statement{s}, nil
+	}
+	clauses, err := r.expandTerm(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range clauses {
+		rule, ok := c.(*ast.Rule)
+		if !ok {
+			continue
+		}
+		body := ast.Query{}
+		for _, g := range *rule.Body {
+			goals, err := r.expandGoal(ctx, g, 0)
+			if err != nil {
+				return nil, err
+			}
+			body = append(body, goals...)
+		}
+		clauses[i] = &ast.Rule{Head: rule.Head, Body: &body, Pos: rule.Pos}
+	}
+	return clauses, nil
+}
+
+// expandTerm calls term_expansion/2 on the clause, returning the clauses it expands to
+func (r *R) expandTerm(ctx context.Context, s ast.Statement) ([]ast.Statement, error) {
+	expanded, err := r.callHook(ctx, "term_expansion", ast.ClauseTerm(s))
+	if err != nil || expanded == nil {
+		return []ast.Statement{s}, err
+	}
+
+	items := []ast.Term{expanded}
+	if ast.IsProperList(expanded) {
+		items, _ = ast.ListToSlice(expanded)
+	}
+	pos := ast.PositionOf(s)
+	clauses := []ast.Statement{}
+	for _, t := range items {
+		c, err := syntax.Clause(t, pos)
+		if err != nil {
+			return nil, fmt.Errorf("term_expansion of %s: %w", s, err)
+		}
+		if c.GetType() == ast.T_Query {
+			return nil, fmt.Errorf("term_expansion of %s: expected a clause but found the directive %s", s, c)
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses, nil
+}
+
+// expandGoal calls goal_expansion/2 on a goal, returning the goals it expands to
+func (r *R) expandGoal(ctx context.Context, g ast.Statement, depth int) (ast.Query, error) {
+	if depth > maxGoalExpansions {
+		return nil, fmt.Errorf("goal_expansion of %s did not stop after %d expansions", g, maxGoalExpansions)
+	}
+	goal := ast.GoalTerm(g)
+	expanded, err := r.callHook(ctx, "goal_expansion", goal)
+	if err != nil {
+		return nil, err
+	}
+	if expanded == nil || ast.WriteTerm(expanded, ast.WriteOptions{Quoted: true}) == ast.WriteTerm(goal, ast.WriteOptions{Quoted: true}) {
+		return ast.Query{g}, nil
+	}
+
+	goals, err := syntax.Goals(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("goal_expansion of %s: %w", g, err)
+	}
+	result := ast.Query{}
+	for _, eg := range goals {
+		more, err := r.expandGoal(ctx, eg, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, more...)
+	}
+	return result, nil
+}
+
+// callHook calls `hook(t, Expanded)` and returns Expanded, or nil if the hook isnt defined or fails
+func (r *R) callHook(ctx context.Context, hook string, t ast.Term) (ast.Term, error) {
+	if len(r.i.StatementsForSignature(&ast.Signature{Functor: hook, Arity: 2})) == 0 {
+		return nil, nil
+	}
+	expanded := r.freshVariable()
+	b := r.solveOnce(ctx, ast.CreateFact(hook, t, expanded), EmptyBindings())
+	if b == nil {
+		return nil, nil
+	}
+	if b.IsException() {
+		return nil, &Exception{Term: b.Exception}
+	}
+	return keepNames(b.Ground(expanded), t, b), nil
+}
+
+/**
+ * keepNames renames the variables of t that the hook left unbound back to the names they have in t.
+ * Unifying them with the hook's variables can bind them to fresh ones, which would leave the expansion
+ * with different variables to the rest of the clause.
+ */
+func keepNames(expanded ast.Term, t ast.Term, b *Bindings) ast.Term {
+	f, ok := t.(*ast.Fact)
+	if !ok {
+		return expanded
+	}
+	names := map[string]*ast.Variable{}
+	for _, v := range f.ExtractVariables() {
+		if g, ok := b.Dereference(v).(*ast.Variable); ok && g.String() != v.String() && names[g.String()] == nil {
+			names[g.String()] = v
+		}
+	}
+	return renameVariables(expanded, names)
+}
+
+// renameVariables replaces the variables in the term which have a new name in names, Ground leaves variables bound to variables alone
+func renameVariables(t ast.Term, names map[string]*ast.Variable) ast.Term {
+	switch v := t.(type) {
+	case *ast.Variable:
+		if n, ok := names[v.String()]; ok {
+			return n
+		}
+	case *ast.Fact:
+		args := make([]ast.Term, len(v.Args))
+		for i, a := range v.Args {
+			args[i] = renameVariables(a, names)
+		}
+		return &ast.Fact{Head: v.Head, Args: args, Pos: v.Pos}
+	}
+	return t
+}
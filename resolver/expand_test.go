@@ -0,0 +1,81 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// consult expands each clause in src and indexes what it expands to, the way the engine consults a source
+func consult(r *resolver.R, i indexer.Indexer, src string) error {
+	statements, err := r.Ops().Parse([]rune(src), "")
+	if err != nil {
+		return err
+	}
+	for _, s := range statements {
+		clauses, err := r.Expand(context.Background(), s)
+		if err != nil {
+			return err
+		}
+		for _, c := range clauses {
+			i.IndexStatement(c)
+		}
+	}
+	return nil
+}
+
+func TestExpand(t *testing.T) {
+	i := indexer.NewDefault()
+	r := resolver.New(i)
+	err := consult(r, i, `
+term_expansion(colour(C), [colour(C), shade(C, dark), shade(C, light)]).
+term_expansion(markers, [marker(1), marker(2)]).
+goal_expansion(twice(G), (G, G)).
+goal_expansion(inc(X, Y), Y is X + 1).
+colour(red).
+markers.
+count(X, Z) :- inc(X, Y), inc(Y, Z).
+pair(X) :- twice(member(X, [a, b])).
+member(X, [X|_]).
+member(X, [_|T]) :- member(X, T).
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		goal     string
+		variable string
+		expected []string
+	}{
+		{"shade(red, S)", "S", []string{"dark", "light"}},
+		{"marker(M)", "M", []string{"1", "2"}},
+		{"count(1, Z)", "Z", []string{"3"}},
+		{"pair(P)", "P", []string{"a", "b"}},
+	} {
+		results := solve(r, parseQuery(t, r, c.goal)...)
+		if len(results) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.goal, c.expected, results)
+			continue
+		}
+		for n, b := range results {
+			if got := ast.WriteTerm(b.Ground(ast.CreateVariable(c.variable)), ast.WriteOptions{}); got != c.expected[n] {
+				t.Errorf("%s: expected %s = %s, got %s", c.goal, c.variable, c.expected[n], got)
+			}
+		}
+	}
+
+	// the clause the hook matched is replaced by what it expanded to
+	if results := solve(r, ast.CreateFact("markers")); len(results) != 0 {
+		t.Errorf("expected markers to be expanded away, got %v", results)
+	}
+
+	err = consult(r, i, "term_expansion(bad, _) :- throw(oops).\nbad.")
+	var ex *resolver.Exception
+	if !errors.As(err, &ex) || ex.Term.String() != "oops" {
+		t.Errorf("expected the exception oops, got %v", err)
+	}
+}
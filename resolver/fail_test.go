@@ -1,58 +1,42 @@
 package resolver
 
+import "context"
 import "testing"
 import "github.com/kkoch986/gopl/ast"
+import "github.com/kkoch986/gopl/indexer"
+
+// resolveFact collects every solution of the fact
+func resolveFact(r *R, f *ast.Fact, c *Bindings) []*Bindings {
+	out := make(chan *Bindings)
+	go r.ResolveFact(context.Background(), f, c, out)
+	results := []*Bindings{}
+	for b := range out {
+		results = append(results, b)
+	}
+	return results
+}
 
 /**
- * TestFailPassthrough ensures that this does not respond to anything but fail/0
+ * TestFailPassthrough ensures that fail is only registered as fail/0
  */
 func TestFailPassthrough(t *testing.T) {
-	f := &Fail{}
-	m := make(chan bool)
-	out := make(chan *Bindings)
-
-	go f.Resolve(&ast.Fact{Head: "something", Args: []ast.Term{}}, EmptyBindings(), out, m)
-
-	select {
-	case o := <-out:
-		t.Errorf("Fail resolver wrote results for something/0 (got %s)", o)
-	case matched := <-m:
-		if matched {
-			t.Errorf("Fail resolver matched for something/0")
-		}
+	r := New(indexer.NewDefault())
+	if !r.Registered("fail", 0) {
+		t.Errorf("fail/0 is not registered")
 	}
 
 	// make sure it doesnt match "fail/1"
-	m = make(chan bool)
-	out = make(chan *Bindings)
-	go f.Resolve(&ast.Fact{Head: "fail", Args: []ast.Term{ast.CreateAtom("A")}}, EmptyBindings(), out, m)
-	select {
-	case o := <-out:
-		t.Errorf("Fail resolver wrote results for fail/1 (got %s)", o)
-	case matched := <-m:
-		if matched {
-			t.Errorf("Fail resolver matched for fail/1")
-		}
+	if r.Registered("fail", 1) {
+		t.Errorf("fail/1 is registered")
 	}
-
 }
 
 /**
- * TestNoResults affirms that fail matches fail/0 but does not return any results
+ * TestNoResults affirms that fail/0 does not return any results
  */
 func TestFailNoResults(t *testing.T) {
-	f := &Fail{}
-	m := make(chan bool)
-	out := make(chan *Bindings)
-
-	go f.Resolve(&ast.Fact{Head: "fail", Args: []ast.Term{}}, EmptyBindings(), out, m)
-
-	select {
-	case o := <-out:
-		t.Errorf("Fail resolver wrote results for fail/0 (got %s)", o)
-	case matched := <-m:
-		if !matched {
-			t.Errorf("Fail resolver did not match for fail/0")
-		}
+	r := New(indexer.NewDefault())
+	if results := resolveFact(r, &ast.Fact{Head: "fail", Args: []ast.Term{}}, EmptyBindings()); len(results) != 0 {
+		t.Errorf("fail/0 wrote results (got %s)", results)
 	}
 }
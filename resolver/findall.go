@@ -0,0 +1,29 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * findall implements findall/3 and findall/4.
+ *   findall(Template, Goal, Bag) unifies Bag with a list containing a copy of Template for each solution of Goal.
+ *   findall(Template, Goal, Bag, Tail) is the same but the list ends with Tail instead of `[]`.
+ * If Goal has no solutions, Bag is unified with the empty list (or Tail).
+ */
+func (r *R) findall(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	results, ex := r.findSolutions(ctx, args[0], args[1], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+
+	var bag ast.Term
+	if len(args) == 4 {
+		bag = ast.CreatePartialList(results, args[3])
+	} else {
+		bag = ast.CreateList(results...)
+	}
+	unifyAndSend(ctx, args[2], bag, c, out)
+}
@@ -0,0 +1,181 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func num(n float64) *ast.NumericLiteral {
+	return ast.CreateNumericLiteral(n)
+}
+
+func TestAllSolutionsCases(t *testing.T) {
+	// p(1, a). p(2, b). p(3, a). p(1, a).
+	// f(X) :- p(X, a).
+	program := []ast.Statement{
+		ast.CreateFact("p", num(1), ast.CreateAtom("a")),
+		ast.CreateFact("p", num(2), ast.CreateAtom("b")),
+		ast.CreateFact("p", num(3), ast.CreateAtom("a")),
+		ast.CreateFact("p", num(1), ast.CreateAtom("a")),
+		ast.CreateRule(
+			ast.CreateFact("f", ast.CreateVariable("X")),
+			ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateAtom("a")),
+		),
+	}
+
+	cases := []resolverTestCase{
+		{
+			"findall/3 over facts",
+			program,
+			&ast.Query{
+				ast.CreateFact("findall", ast.CreateVariable("X"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateVariable("Y")), ast.CreateVariable("L")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreateList(num(1), num(2), num(3), num(1))}),
+			},
+		},
+		{
+			"findall/3 over a rule",
+			program,
+			&ast.Query{
+				ast.CreateFact("findall", ast.CreateVariable("X"), ast.CreateFact("f", ast.CreateVariable("X")), ast.CreateVariable("L")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreateList(num(1), num(3), num(1))}),
+			},
+		},
+		{
+			"findall/3 with a conjunction and no solutions",
+			program,
+			&ast.Query{
+				ast.CreateFact("findall",
+					ast.CreateVariable("X"),
+					ast.CreateFact(",", ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateAtom("b")), ast.CreateFact("f", ast.CreateVariable("X"))),
+					ast.CreateVariable("L"),
+				),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreateList()}),
+			},
+		},
+		{
+			"findall/4",
+			program,
+			&ast.Query{
+				ast.CreateFact("findall", ast.CreateVariable("X"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateAtom("b")), ast.CreateVariable("L"), ast.CreateVariable("T")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreatePartialList([]ast.Term{num(2)}, ast.CreateVariable("T"))}),
+			},
+		},
+		{
+			"bagof/3 groups by free variables",
+			program,
+			&ast.Query{
+				ast.CreateFact("bagof", ast.CreateVariable("X"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateVariable("Y")), ast.CreateVariable("L")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"Y": ast.CreateAtom("a"), "L": ast.CreateList(num(1), num(3), num(1))}),
+				resolver.CreateBindings(map[string]ast.Term{"Y": ast.CreateAtom("b"), "L": ast.CreateList(num(2))}),
+			},
+		},
+		{
+			"bagof/3 with ^",
+			program,
+			&ast.Query{
+				ast.CreateFact("bagof",
+					ast.CreateVariable("X"),
+					ast.CreateFact("^", ast.CreateVariable("Y"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateVariable("Y"))),
+					ast.CreateVariable("L"),
+				),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreateList(num(1), num(2), num(3), num(1))}),
+			},
+		},
+		{
+			"bagof/3 fails without solutions",
+			program,
+			&ast.Query{
+				ast.CreateFact("bagof", ast.CreateVariable("X"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateAtom("c")), ast.CreateVariable("L")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{},
+		},
+		{
+			"setof/3 sorts and removes duplicates",
+			program,
+			&ast.Query{
+				ast.CreateFact("setof",
+					ast.CreateVariable("X"),
+					ast.CreateFact("^", ast.CreateVariable("Y"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateVariable("Y"))),
+					ast.CreateVariable("L"),
+				),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"L": ast.CreateList(num(1), num(2), num(3))}),
+			},
+		},
+		{
+			"setof/3 sorts the groups",
+			program,
+			&ast.Query{
+				ast.CreateFact("setof", ast.CreateVariable("Y"), ast.CreateFact("p", ast.CreateVariable("X"), ast.CreateVariable("Y")), ast.CreateVariable("L")),
+			},
+			resolver.EmptyBindings(),
+			[]*resolver.Bindings{
+				resolver.CreateBindings(map[string]ast.Term{"X": num(1), "L": ast.CreateList(ast.CreateAtom("a"))}),
+				resolver.CreateBindings(map[string]ast.Term{"X": num(2), "L": ast.CreateList(ast.CreateAtom("b"))}),
+				resolver.CreateBindings(map[string]ast.Term{"X": num(3), "L": ast.CreateList(ast.CreateAtom("a"))}),
+			},
+		},
+	}
+
+	for _, v := range cases {
+		runTestCase(t, v)
+	}
+}
+
+func TestAggregateAllCases(t *testing.T) {
+	program := []ast.Statement{
+		ast.CreateFact("age", ast.CreateAtom("peter"), num(7)),
+		ast.CreateFact("age", ast.CreateAtom("ann"), num(11)),
+		ast.CreateFact("age", ast.CreateAtom("pat"), num(8)),
+		ast.CreateFact("age", ast.CreateAtom("tom"), num(5)),
+		ast.CreateFact("age", ast.CreateAtom("mike"), num(11)),
+	}
+	age := ast.CreateFact("age", ast.CreateVariable("N"), ast.CreateVariable("A"))
+	aggregate := func(spec ast.Term, goal ast.Term) *ast.Query {
+		return &ast.Query{ast.CreateFact("aggregate_all", spec, goal, ast.CreateVariable("R"))}
+	}
+	result := func(t ast.Term) []*resolver.Bindings {
+		return []*resolver.Bindings{resolver.CreateBindings(map[string]ast.Term{"R": t})}
+	}
+
+	cases := []resolverTestCase{
+		{"count", program, aggregate(ast.CreateAtom("count"), age), resolver.EmptyBindings(), result(num(5))},
+		{"count no solutions", program, aggregate(ast.CreateAtom("count"), ast.CreateFact("age", ast.CreateAtom("bob"), ast.CreateVariable("A"))), resolver.EmptyBindings(), result(num(0))},
+		{"sum", program, aggregate(ast.CreateFact("sum", ast.CreateVariable("A")), age), resolver.EmptyBindings(), result(num(42))},
+		{"sum expression", program, aggregate(ast.CreateFact("sum", ast.CreateFact("*", ast.CreateVariable("A"), num(2))), age), resolver.EmptyBindings(), result(num(84))},
+		{"max", program, aggregate(ast.CreateFact("max", ast.CreateVariable("A")), age), resolver.EmptyBindings(), result(num(11))},
+		{"min", program, aggregate(ast.CreateFact("min", ast.CreateVariable("A")), age), resolver.EmptyBindings(), result(num(5))},
+		{"max no solutions", program, aggregate(ast.CreateFact("max", ast.CreateVariable("A")), ast.CreateFact("age", ast.CreateAtom("bob"), ast.CreateVariable("A"))), resolver.EmptyBindings(), []*resolver.Bindings{}},
+		{"max witness", program, aggregate(ast.CreateFact("max", ast.CreateVariable("A"), ast.CreateVariable("N")), age), resolver.EmptyBindings(), result(ast.CreateFact("max", num(11), ast.CreateAtom("ann")))},
+		{"min witness", program, aggregate(ast.CreateFact("min", ast.CreateVariable("A"), ast.CreateVariable("N")), age), resolver.EmptyBindings(), result(ast.CreateFact("min", num(5), ast.CreateAtom("tom")))},
+		{"bag", program, aggregate(ast.CreateFact("bag", ast.CreateVariable("A")), age), resolver.EmptyBindings(), result(ast.CreateList(num(7), num(11), num(8), num(5), num(11)))},
+		{"set", program, aggregate(ast.CreateFact("set", ast.CreateVariable("A")), age), resolver.EmptyBindings(), result(ast.CreateList(num(5), num(7), num(8), num(11)))},
+	}
+
+	for _, v := range cases {
+		runTestCase(t, v)
+	}
+}
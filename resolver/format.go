@@ -0,0 +1,329 @@
+package resolver
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+// fillPoint is a position in the current column segment where padding can be inserted (~t)
+type fillPoint struct {
+	offset int
+	char   rune
+}
+
+/**
+ * formatter holds the state needed to process a format string.
+ * Text is written into the current segment which is flushed into the output when a column stop (~| or ~+) is reached,
+ * at that point the segment is padded at its fill points to reach the column.
+ */
+type formatter struct {
+	out        strings.Builder
+	segment    []rune
+	fills      []fillPoint
+	lastColumn int
+	args       []ast.Term
+	// ops are the operators ~w, ~p and ~q write terms with
+	ops ast.Operators
+}
+
+/**
+ * formatString processes a format string the same way format/2 does.
+ * The supported directives are:
+ *   ~w ~p ~q ~a      write, print, writeq and write an atomic
+ *   ~d ~D            integers, the numeric argument inserts a decimal point, ~D groups digits with commas
+ *   ~f ~e ~g         floats, the numeric argument is the number of digits (default 6)
+ *   ~s               a string or list of codes / chars
+ *   ~c               a character code, repeated numeric argument times
+ *   ~r ~R            an integer in the radix given by the numeric argument
+ *   ~n ~~ ~i         newline, a literal ~ and ignore an argument
+ *   ~t ~| ~+         fill points and column stops
+ * The numeric argument can be given as digits, `*` (taken from the arguments) or `c (the character code of c).
+ */
+func formatString(format string, args []ast.Term, ops ast.Operators) (string, error) {
+	f := &formatter{args: args, ops: ops}
+	if err := f.run([]rune(format)); err != nil {
+		return "", err
+	}
+	if len(f.args) > 0 {
+		return "", fmt.Errorf("too many arguments for format %q", format)
+	}
+	f.flush()
+	return f.out.String(), nil
+}
+
+func (f *formatter) next() (ast.Term, error) {
+	if len(f.args) == 0 {
+		return nil, fmt.Errorf("not enough arguments")
+	}
+	a := f.args[0]
+	f.args = f.args[1:]
+	return a, nil
+}
+
+func (f *formatter) nextInt() (int, error) {
+	a, err := f.next()
+	if err != nil {
+		return 0, err
+	}
+	v, ok := a.(*ast.NumericLiteral)
+	if !ok || v.Value() != math.Trunc(v.Value()) {
+		return 0, fmt.Errorf("expected an integer, got %s", a)
+	}
+	return int(v.Value()), nil
+}
+
+func (f *formatter) nextNumber() (float64, error) {
+	a, err := f.next()
+	if err != nil {
+		return 0, err
+	}
+	v, ok := a.(*ast.NumericLiteral)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %s", a)
+	}
+	return v.Value(), nil
+}
+
+func (f *formatter) emit(s string) {
+	for _, r := range s {
+		if r == '\n' {
+			// a newline always ends the current segment
+			f.segment = append(f.segment, r)
+			f.flush()
+			f.lastColumn = 0
+			continue
+		}
+		f.segment = append(f.segment, r)
+	}
+}
+
+// flush writes the current segment without any padding
+func (f *formatter) flush() {
+	f.out.WriteString(string(f.segment))
+	f.lastColumn += len(f.segment)
+	f.segment = nil
+	f.fills = nil
+}
+
+// column pads the current segment so that it ends at the given column and then flushes it
+func (f *formatter) column(target int) {
+	pad := target - f.lastColumn - len(f.segment)
+	if pad > 0 {
+		fills := f.fills
+		if len(fills) == 0 {
+			// without fill points the text is left aligned
+			fills = []fillPoint{{offset: len(f.segment), char: ' '}}
+		}
+		each, extra := pad/len(fills), pad%len(fills)
+		padded := []rune{}
+		prev := 0
+		for i, fp := range fills {
+			padded = append(padded, f.segment[prev:fp.offset]...)
+			n := each
+			if i == len(fills)-1 {
+				n += extra
+			}
+			padded = append(padded, []rune(strings.Repeat(string(fp.char), n))...)
+			prev = fp.offset
+		}
+		f.segment = append(padded, f.segment[prev:]...)
+	}
+	f.flush()
+	// when the text was already past the column, the column stop moves to the end of the text
+	if f.lastColumn < target {
+		f.lastColumn = target
+	}
+}
+
+func (f *formatter) run(format []rune) error {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '~' {
+			f.emit(string(format[i]))
+			continue
+		}
+
+		i++
+		if i >= len(format) {
+			return fmt.Errorf("format ends with ~")
+		}
+
+		// the optional numeric argument
+		arg, hasArg := 0, false
+		switch {
+		case format[i] == '*':
+			v, err := f.nextInt()
+			if err != nil {
+				return err
+			}
+			arg, hasArg = v, true
+			i++
+		case format[i] == '`':
+			if i+1 >= len(format) {
+				return fmt.Errorf("format ends with ~`")
+			}
+			arg, hasArg = int(format[i+1]), true
+			i += 2
+		default:
+			start := i
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+			if i > start {
+				arg, _ = strconv.Atoi(string(format[start:i]))
+				hasArg = true
+			}
+		}
+		if i >= len(format) {
+			return fmt.Errorf("format ends without a directive")
+		}
+
+		if err := f.directive(format[i], arg, hasArg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *formatter) directive(d rune, arg int, hasArg bool) error {
+	switch d {
+	case '~':
+		f.emit("~")
+	case 'n':
+		if !hasArg {
+			arg = 1
+		}
+		f.emit(strings.Repeat("\n", arg))
+	case 'w', 'p', 'q':
+		a, err := f.next()
+		if err != nil {
+			return err
+		}
+		f.emit(ast.WriteTerm(a, ast.WriteOptions{Quoted: d != 'w', Ops: f.ops}))
+	case 'a':
+		a, err := f.next()
+		if err != nil {
+			return err
+		}
+		s, ok := textOf(a, EmptyBindings())
+		if !ok {
+			return fmt.Errorf("~a expects an atomic argument, got %s", a)
+		}
+		f.emit(s)
+	case 'i':
+		if _, err := f.next(); err != nil {
+			return err
+		}
+	case 'd', 'D':
+		v, err := f.nextInt()
+		if err != nil {
+			return err
+		}
+		f.emit(formatInteger(v, arg, d == 'D'))
+	case 'f', 'e', 'g':
+		v, err := f.nextNumber()
+		if err != nil {
+			return err
+		}
+		if !hasArg {
+			arg = 6
+		}
+		f.emit(strconv.FormatFloat(v, byte(d), arg, 64))
+	case 's':
+		a, err := f.next()
+		if err != nil {
+			return err
+		}
+		s, ok := codesText(a, EmptyBindings())
+		if !ok {
+			if s, ok = charsText(a, EmptyBindings()); !ok {
+				if a.GetType() != ast.T_String {
+					return fmt.Errorf("~s expects a string or list of codes, got %s", a)
+				}
+				s = a.String()
+			}
+		}
+		f.emit(s)
+	case 'c':
+		v, err := f.nextInt()
+		if err != nil {
+			return err
+		}
+		if !hasArg {
+			arg = 1
+		}
+		f.emit(strings.Repeat(string(rune(v)), arg))
+	case 'r', 'R':
+		v, err := f.nextInt()
+		if err != nil {
+			return err
+		}
+		if !hasArg || arg < 2 || arg > 36 {
+			return fmt.Errorf("~%c needs a radix between 2 and 36", d)
+		}
+		s := strconv.FormatInt(int64(v), arg)
+		if d == 'R' {
+			s = strings.ToUpper(s)
+		}
+		f.emit(s)
+	case 't':
+		char := ' '
+		if hasArg {
+			char = rune(arg)
+		}
+		f.fills = append(f.fills, fillPoint{offset: len(f.segment), char: char})
+	case '|':
+		target := f.lastColumn + len(f.segment)
+		if hasArg {
+			target = arg
+		}
+		f.column(target)
+	case '+':
+		if !hasArg {
+			arg = 8
+		}
+		f.column(f.lastColumn + arg)
+	default:
+		return fmt.Errorf("unknown directive ~%c", d)
+	}
+	return nil
+}
+
+/**
+ * formatInteger writes an integer for ~d and ~D.
+ * If decimals is > 0 a decimal point is inserted that many digits from the right.
+ * If group is true the integer part is grouped into threes with commas.
+ */
+func formatInteger(v int, decimals int, group bool) string {
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	digits := strconv.Itoa(v)
+	frac := ""
+	if decimals > 0 {
+		for utf8.RuneCountInString(digits) <= decimals {
+			digits = "0" + digits
+		}
+		frac = "." + digits[len(digits)-decimals:]
+		digits = digits[:len(digits)-decimals]
+	}
+	if group {
+		var sb strings.Builder
+		for i, r := range digits {
+			if i > 0 && (len(digits)-i)%3 == 0 {
+				sb.WriteRune(',')
+			}
+			sb.WriteRune(r)
+		}
+		digits = sb.String()
+	}
+	if neg {
+		digits = "-" + digits
+	}
+	return digits + frac
+}
@@ -0,0 +1,153 @@
+package resolver_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// runOutputTestCase resolves the goal and checks what was written to the output
+func runOutputTestCase(t *testing.T, label string, goal *ast.Fact, expected string) {
+	var buf bytes.Buffer
+	r := resolver.New(indexer.NewDefault())
+	r.SetOutput(&buf)
+
+	out := make(chan *resolver.Bindings, 1)
+	go r.ResolveStatementList(context.Background(), []ast.Statement{&ast.Query{goal}}, resolver.EmptyBindings(), out)
+	count := 0
+	for range out {
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("%s: expected 1 solution, got %d", label, count)
+	}
+	if buf.String() != expected {
+		t.Errorf("%s: expected output %q, got %q", label, expected, buf.String())
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	cases := []struct {
+		label    string
+		goal     *ast.Fact
+		expected string
+	}{
+		{"write number", f("write", num(3)), "3"},
+		{"write float", f("write", num(1.5)), "1.5"},
+		{"write nested", f("write", f("f", l(num(1), a("b")), s("c d"))), "f([1,b],c d)"},
+		{"writeq quotes", f("writeq", f("f", a("Hello world"), s("c d"), a("[]"))), "f('Hello world',\"c d\",[])"},
+		{"print quotes", f("print", a("it's")), "'it\\'s'"},
+		{"writeln grounds nested terms", f("writeln", f("f", l(num(1), num(2)))), "f([1,2])\n"},
+		{"nl", f("nl"), "\n"},
+		{"tab", f("tab", f("+", num(1), num(2))), "   "},
+		{"format/1", f("format", a("hello~n")), "hello\n"},
+		{"format ~w ~a", f("format", s("~w and ~a"), l(f("f", a("x")), a("y"))), "f(x) and y"},
+		{"format single argument", f("format", s("<~w>"), a("x")), "<x>"},
+		{"format ~q", f("format", s("~q"), l(a("A b"))), "'A b'"},
+		{"format ~d", f("format", s("~d ~2d ~D"), l(num(42), num(1234), num(1234567))), "42 12.34 1,234,567"},
+		{"format ~f ~e", f("format", s("~2f ~f ~1e"), l(num(3.14159), num(1), num(1234.5))), "3.14 1.000000 1.2e+03"},
+		{"format ~s", f("format", s("~s!"), l(l(num(104), num(105)))), "hi!"},
+		{"format ~c", f("format", s("~c~3c"), l(num(97), num(98))), "abbb"},
+		{"format ~*c", f("format", s("~*c"), l(num(4), num(120))), "xxxx"},
+		{"format ~r", f("format", s("~8r ~16R"), l(num(8), num(255))), "10 FF"},
+		{"format ~~ ~i", f("format", s("~~~i~w"), l(a("skip"), a("x"))), "~x"},
+		{"format column left aligned", f("format", s("~w~10|~w"), l(a("abc"), a("def"))), "abc       def"},
+		{"format column right aligned", f("format", s("~t~w~10|"), l(a("abc"))), "       abc"},
+		{"format column centered", f("format", s("~t~w~t~9|"), l(a("abc"))), "   abc   "},
+		{"format fill char", f("format", s("~`-t~30|~n")), "------------------------------\n"},
+		{"format column +", f("format", s("~w~t~5+~w~t~5+|"), l(a("a"), a("b"))), "a    b    |"},
+	}
+
+	for _, c := range cases {
+		runOutputTestCase(t, c.label, c.goal, c.expected)
+	}
+}
+
+func TestWriteOperators(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	cases := []struct {
+		label    string
+		goal     *ast.Fact
+		expected string
+	}{
+		{"infix", f("write", f("-", a("a"), a("b"))), "a-b"},
+		{"word infix", f("write", f("is", a("x"), f("+", num(1), num(2)))), "x is 1+2"},
+		{"left associative", f("write", f("-", f("-", num(1), num(2)), num(3))), "1-2-3"},
+		{"right operand needs brackets", f("write", f("-", num(1), f("-", num(2), num(3)))), "1-(2-3)"},
+		{"lower priority needs brackets", f("write", f("*", num(2), f("+", num(1), num(3)))), "2*(1+3)"},
+		{"higher priority doesnt", f("write", f("+", num(2), f("*", num(1), num(3)))), "2+1*3"},
+		{"xfx", f("write", f("=", f("=", a("a"), a("b")), a("c"))), "(a=b)=c"},
+		{"prefix", f("write", f("-", a("a"))), "-a"},
+		{"prefix word", f("write", f("dynamic", f("/", a("foo"), num(1)))), "dynamic foo/1"},
+		{"nested prefix", f("write", f("-", f("-", a("a")))), "- -a"},
+		{"prefix of a number", f("write", f("-", num(1))), "-(1)"},
+		{"prefix of an expression", f("write", f("-", f("^", num(1), num(2)))), "- 1^2"},
+		{"prefix of brackets", f("write", f("-", f(",", a("a"), a("b")))), "- (a,b)"},
+		{"negative operand", f("write", f("-", a("a"), num(-1))), "a- -1"},
+		{"operator atom operand", f("write", f("-", a("-"))), "- (-)"},
+		{"arguments", f("writeq", f("f", f(":-", a("a"), a("b")), f(",", a("c"), a("d")), a("-"))), "f((a:-b),(c,d),-)"},
+		{"list items", f("print", l(f("-", a("a"), num(1)), f(";", a("b"), a("c")))), "[a-1,(b;c)]"},
+		{"writeln", f("writeln", f("\\+", a("a"))), "\\+a\n"},
+		{"format ~w", f("format", s("~w ~q"), l(f("-", a("a"), a("b")), f("+", a("A"), a("b")))), "a-b 'A'+b"},
+		{"write_canonical ignores operators", f("write_canonical", f("-", a("a"), a("b"))), "-(a,b)"},
+		{"user defined operators", f("call", f(",", f("op", num(700), a("xfx"), a("likes")), f("write", f("likes", a("mary"), a("wine"))))), "mary likes wine"},
+	}
+
+	for _, c := range cases {
+		runOutputTestCase(t, c.label, c.goal, c.expected)
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	s := ast.CreateStringLiteral
+	q := func(head string, args ...ast.Term) *ast.Query {
+		return &ast.Query{ast.CreateFact(head, args...)}
+	}
+	formatError := func(message string) []*resolver.Bindings {
+		return []*resolver.Bindings{resolver.Throw(ast.CreateFact("error", ast.CreateFact("format", s(message)), ast.CreateVariable("_")))}
+	}
+	cases := []resolverTestCase{
+		{"too few arguments", nil, q("format", s("~w ~w"), ast.CreateList(ast.CreateAtom("a"))), resolver.EmptyBindings(), formatError("not enough arguments")},
+		{"too many arguments", nil, q("format", s("~w"), ast.CreateList(ast.CreateAtom("a"), ast.CreateAtom("b"))), resolver.EmptyBindings(), formatError(`too many arguments for format "~w"`)},
+		{"unknown directive", nil, q("format", s("~y"), ast.CreateList()), resolver.EmptyBindings(), formatError("unknown directive ~y")},
+	}
+	for _, c := range cases {
+		runTestCase(t, c)
+	}
+}
+
+func TestOutputCapture(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	query := func(head string, args ...ast.Term) *ast.Query {
+		return &ast.Query{f(head, args...)}
+	}
+	type m = map[string]ast.Term
+
+	cases := []resolverTestCase{
+		{"with_output_to atom", nil, query("with_output_to", f("atom", v("A")), f(",", f("write", a("a")), f("write", num(1)))), resolver.EmptyBindings(), []*resolver.Bindings{resolver.CreateBindings(m{"A": a("a1")})}},
+		{"with_output_to string", nil, query("with_output_to", f("string", v("S")), f("format", s("~w-~w"), ast.CreateList(a("x"), a("y")))), resolver.EmptyBindings(), []*resolver.Bindings{resolver.CreateBindings(m{"S": s("x-y")})}},
+		{"with_output_to chars", nil, query("with_output_to", f("chars", v("C")), f("write", a("ab"))), resolver.EmptyBindings(), []*resolver.Bindings{resolver.CreateBindings(m{"C": ast.CreateList(a("a"), a("b"))})}},
+		{"with_output_to failing goal", nil, query("with_output_to", f("atom", v("A")), a("fail")), resolver.EmptyBindings(), []*resolver.Bindings{}},
+		{"format/3", nil, query("format", f("atom", v("A")), s("~a~a"), ast.CreateList(a("x"), a("y"))), resolver.EmptyBindings(), []*resolver.Bindings{resolver.CreateBindings(m{"A": a("xy")})}},
+	}
+	for _, c := range cases {
+		runTestCase(t, c)
+	}
+}
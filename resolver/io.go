@@ -0,0 +1,613 @@
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+/**
+ * StreamIO implements the stream based I/O builtins.
+ *   open(File, Mode, Stream), open(File, Mode, Stream, Options)  - mode is one of read, write or append
+ *   close(Stream), close(Stream, Options)
+ *   current_input(S), current_output(S), set_input(S), set_output(S)
+ *   read_term(T, Options), read_term(S, T, Options), read(T), read(S, T)
+ *   get_char/1,2, peek_char/1,2, put_char/1,2
+ *   read_line_to_string(S, String), at_end_of_stream/0,1
+ * Streams can be referred to by their handle or by an alias given when opening them.
+ * Errors are raised as ISO error terms, i.e. `error(existence_error(source_sink, File), _)`.
+ */
+type StreamIO struct {
+	r *R
+}
+
+func newStreamIO(r *R) nativePredicates {
+	w := &StreamIO{r: r}
+	return nativePredicates{
+		"open/3":           w.open,
+		"open/4":           w.open,
+		"close/1":          w.close,
+		"close/2":          w.close,
+		"current_input/1":  w.currentInput,
+		"current_output/1": w.currentOutput,
+		"set_input/1":      w.setInput,
+		"set_output/1":     w.setOutput,
+		"read_term/2":      w.readTerm,
+		"read_term/3":      w.readTerm,
+		"read/1":           w.read,
+		"read/2":           w.read,
+		"get_char/1": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.getChar(ctx, false, a, c, out)
+		},
+		"get_char/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.getChar(ctx, false, a, c, out)
+		},
+		"peek_char/1": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.getChar(ctx, true, a, c, out)
+		},
+		"peek_char/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.getChar(ctx, true, a, c, out)
+		},
+		"put_char/1":            w.putChar,
+		"put_char/2":            w.putChar,
+		"read_line_to_string/2": w.readLineToString,
+		"at_end_of_stream/0":    w.atEndOfStream,
+		"at_end_of_stream/1":    w.atEndOfStream,
+	}
+}
+
+// source splits the arguments into the stream to read from and the rest, see R.target
+func (w *StreamIO) source(args []ast.Term, n int, c *Bindings) (*stream, []ast.Term, *Bindings) {
+	if len(args) > n {
+		s, ex := w.r.streams.lookupInput(args[0], c)
+		return s, args[1:], ex
+	}
+	return w.r.streams.currentInput(), args, nil
+}
+
+// openOptions applies the options from open/4 to the stream
+func (w *StreamIO) openOptions(s *stream, options ast.Term, c *Bindings) *Bindings {
+	items, tail := ast.ListToSlice(c.Ground(options))
+	if tail.GetType() == ast.T_Variable {
+		return instantiationError()
+	}
+	if !ast.IsEmptyList(tail) {
+		return typeError("list", c.Ground(options))
+	}
+
+	for _, o := range items {
+		if o.GetType() == ast.T_Variable {
+			return instantiationError()
+		}
+		f, ok := o.(*ast.Fact)
+		if !ok || len(f.Args) != 1 {
+			return domainError("stream_option", o)
+		}
+		value := f.Args[0]
+		if value.GetType() == ast.T_Variable {
+			return instantiationError()
+		}
+		switch f.Head {
+		case "alias":
+			if value.GetType() != ast.T_Atom {
+				return domainError("stream_option", o)
+			}
+			if w.r.streams.aliasTaken(value.String()) {
+				return permissionError("open", "source_sink", o)
+			}
+			s.alias = value.String()
+		case "eof_action":
+			switch value.String() {
+			case "error", "eof_code", "reset":
+				s.eofAction = value.String()
+			default:
+				return domainError("stream_option", o)
+			}
+		case "type", "encoding":
+			// all streams are utf-8 text streams
+		default:
+			return domainError("stream_option", o)
+		}
+	}
+	return nil
+}
+
+// open(File, Mode, Stream) and open(File, Mode, Stream, Options)
+func (w *StreamIO) open(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	file := c.Dereference(args[0])
+	mode := c.Dereference(args[1])
+	if file.GetType() == ast.T_Variable || mode.GetType() == ast.T_Variable {
+		send(ctx, out, instantiationError())
+		return
+	}
+	if file.GetType() != ast.T_Atom && file.GetType() != ast.T_String {
+		send(ctx, out, domainError("source_sink", c.Ground(file)))
+		return
+	}
+	if mode.GetType() != ast.T_Atom {
+		send(ctx, out, typeError("atom", c.Ground(mode)))
+		return
+	}
+	if c.Dereference(args[2]).GetType() != ast.T_Variable {
+		send(ctx, out, uninstantiationError(c.Ground(args[2])))
+		return
+	}
+
+	s := &stream{mode: mode.String(), eofAction: "eof_code"}
+	if len(args) == 4 {
+		if ex := w.openOptions(s, args[3], c); ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+	}
+
+	var f *os.File
+	var err error
+	switch s.mode {
+	case "read":
+		f, err = os.Open(file.String())
+	case "write":
+		f, err = os.Create(file.String())
+	case "append":
+		f, err = os.OpenFile(file.String(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	default:
+		send(ctx, out, domainError("io_mode", mode))
+		return
+	}
+	if err != nil {
+		switch {
+		case os.IsNotExist(err):
+			send(ctx, out, existenceError("source_sink", file))
+		case os.IsPermission(err):
+			send(ctx, out, permissionError("open", "source_sink", file))
+		default:
+			send(ctx, out, ioError("open", file))
+		}
+		return
+	}
+
+	if s.isInput() {
+		s.reader = bufio.NewReader(f)
+	} else {
+		s.writer = f
+	}
+	s.closer = f
+	w.r.streams.add(s)
+	unifyAndSend(ctx, args[2], s.handle(), c, out)
+}
+
+// close(Stream) closes the stream, closing one of the standard streams does nothing
+func (w *StreamIO) close(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, ex := w.r.streams.lookup(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if s.closer == nil {
+		send(ctx, out, c)
+		return
+	}
+
+	w.r.streams.remove(s)
+	if err := s.closer.Close(); err != nil {
+		send(ctx, out, ioError("close", s.handle()))
+		return
+	}
+	send(ctx, out, c)
+}
+
+func (w *StreamIO) currentInput(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	unifyAndSend(ctx, args[0], w.r.streams.currentInput().handle(), c, out)
+}
+
+func (w *StreamIO) currentOutput(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	unifyAndSend(ctx, args[0], w.r.streams.currentOutput().handle(), c, out)
+}
+
+func (w *StreamIO) setInput(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, ex := w.r.streams.lookupInput(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	w.r.streams.setInput(s)
+	send(ctx, out, c)
+}
+
+func (w *StreamIO) setOutput(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, ex := w.r.streams.lookupOutput(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	w.r.streams.setOutput(s)
+	send(ctx, out, c)
+}
+
+/**
+ * atEOF checks the stream before a read.
+ * Once the end of the stream has been read, reading again will either raise a permission error,
+ * return end_of_file again or reset the stream depending on the streams eof_action.
+ */
+func atEOF(s *stream) (bool, *Bindings) {
+	if !s.pastEOF {
+		return false, nil
+	}
+	switch s.eofAction {
+	case "error":
+		return true, permissionError("input", "past_end_of_stream", s.handle())
+	case "reset":
+		s.pastEOF = false
+		return false, nil
+	}
+	return true, nil
+}
+
+// checkChar makes sure the term is either unbound or a single character
+func checkChar(t ast.Term, kind string, c *Bindings) *Bindings {
+	t = c.Dereference(t)
+	if t.GetType() == ast.T_Variable {
+		return nil
+	}
+	if t.GetType() != ast.T_Atom || utf8.RuneCountInString(t.String()) != 1 {
+		return typeError(kind, c.Ground(t))
+	}
+	return nil
+}
+
+// get_char(S, C) and peek_char(S, C), C is unified with end_of_file at the end of the stream
+func (w *StreamIO) getChar(ctx context.Context, peek bool, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, args, ex := w.source(args, 1, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if ch := c.Dereference(args[0]); ch.GetType() != ast.T_Atom || ch.String() != "end_of_file" {
+		if ex := checkChar(ch, "in_character", c); ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+	}
+
+	eof, ex := atEOF(s)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if eof {
+		unifyAndSend(ctx, args[0], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+
+	ru, _, err := s.reader.ReadRune()
+	if err == io.EOF {
+		if !peek {
+			s.pastEOF = true
+		}
+		unifyAndSend(ctx, args[0], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+	if err != nil {
+		send(ctx, out, ioError("read", s.handle()))
+		return
+	}
+	if peek {
+		_ = s.reader.UnreadRune()
+	}
+	unifyAndSend(ctx, args[0], ast.CreateAtom(string(ru)), c, out)
+}
+
+// put_char(S, C) writes a single character
+func (w *StreamIO) putChar(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, args, ex := w.r.target(args, 1, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	ch := c.Dereference(args[0])
+	if ch.GetType() == ast.T_Variable {
+		send(ctx, out, instantiationError())
+		return
+	}
+	if ex := checkChar(ch, "character", c); ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	emit(ctx, s, ch.String(), c, out)
+}
+
+// read_line_to_string(S, String) reads the next line without its line ending, or end_of_file
+func (w *StreamIO) readLineToString(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, ex := w.r.streams.lookupInput(args[0], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	eof, ex := atEOF(s)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if eof {
+		unifyAndSend(ctx, args[1], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+
+	line, err := s.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		s.pastEOF = true
+		unifyAndSend(ctx, args[1], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+	if err != nil && err != io.EOF {
+		send(ctx, out, ioError("read", s.handle()))
+		return
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	unifyAndSend(ctx, args[1], ast.CreateStringLiteral(line), c, out)
+}
+
+// at_end_of_stream(S) succeeds if there is nothing left to read from S
+func (w *StreamIO) atEndOfStream(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, _, ex := w.source(args, 0, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if s.pastEOF {
+		send(ctx, out, c)
+		return
+	}
+	if _, err := s.reader.Peek(1); err == io.EOF {
+		send(ctx, out, c)
+	}
+}
+
+func (w *StreamIO) read(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.readTerm(ctx, append(append([]ast.Term{}, args...), ast.CreateList()), c, out)
+}
+
+/**
+ * read_term(S, Term, Options) reads the next clause from S, Term is end_of_file at the end of the stream.
+ * The supported options are:
+ *   variable_names(Vars) - Vars is a list of Name = Var for each named variable in the term
+ *   variables(Vars)      - Vars is a list of the variables in the term
+ */
+func (w *StreamIO) readTerm(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, args, ex := w.source(args, 2, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	eof, ex := atEOF(s)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	if eof {
+		unifyAndSend(ctx, args[0], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+
+	text, eof, err := readClauseText(s.reader)
+	if err == errUnexpectedEOF {
+		s.pastEOF = true
+		send(ctx, out, syntaxError("end_of_file"))
+		return
+	}
+	if err != nil {
+		send(ctx, out, ioError("read", s.handle()))
+		return
+	}
+	if eof {
+		s.pastEOF = true
+		unifyAndSend(ctx, args[0], ast.CreateAtom("end_of_file"), c, out)
+		return
+	}
+
+	term, ex := w.parseTerm(text)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+
+	// give the term its own variables, keeping track of their names for the options
+	names := []ast.Term{}
+	vars := []ast.Term{}
+	mappings := make(map[string]string)
+	w.r.varLock.Lock()
+	renamed, used := ast.CreateFact("", term).Anonymize(w.r.nextVar, "_sf", &mappings)
+	w.r.nextVar = w.r.nextVar + used
+	w.r.varLock.Unlock()
+	seen := make(map[string]bool)
+	for _, v := range ast.CreateFact("", term).ExtractVariables() {
+		if seen[v.String()] {
+			continue
+		}
+		seen[v.String()] = true
+		fresh := ast.CreateVariable(mappings[v.String()])
+		vars = append(vars, fresh)
+		if !ast.IsAnonymous(v) {
+			names = append(names, ast.CreateFact("=", ast.CreateAtom(v.String()), fresh))
+		}
+	}
+
+	b := unifyTerms(args[0], renamed.Args[0], c)
+	if b == nil {
+		return
+	}
+	options, _ := ast.ListToSlice(c.Ground(args[1]))
+	for _, o := range options {
+		f, ok := o.(*ast.Fact)
+		if !ok || len(f.Args) != 1 {
+			send(ctx, out, domainError("read_option", o))
+			return
+		}
+		switch f.Head {
+		case "variable_names":
+			b = unifyTerms(f.Args[0], ast.CreateList(names...), b)
+		case "variables":
+			b = unifyTerms(f.Args[0], ast.CreateList(vars...), b)
+		default:
+			send(ctx, out, domainError("read_option", o))
+			return
+		}
+		if b == nil {
+			return
+		}
+	}
+	send(ctx, out, b)
+}
+
+/**
+ * parseTerm parses the text of one clause (without the final `.`) into a term,
+ * using the operators defined so far with op/3.
+ */
+func (w *StreamIO) parseTerm(text string) (ast.Term, *Bindings) {
+	term, err := w.r.ops.ReadTerm(text + " .")
+	if err == nil {
+		return term, nil
+	}
+	se, ok := err.(*syntax.Error)
+	switch {
+	case !ok:
+		return nil, syntaxError(err.Error())
+	case se.Message != "":
+		return nil, syntaxError(se.Message)
+	case se.Found == "'.'" || se.Found == "the end of the file":
+		return nil, syntaxError("unexpected end of clause")
+	case se.Expected[len(se.Expected)-1] == "an operator":
+		return nil, syntaxError("operator expected")
+	}
+	return nil, syntaxError(fmt.Sprintf("unexpected %s", strings.Trim(se.Found, "'")))
+}
+
+var errUnexpectedEOF = fmt.Errorf("unexpected end of file")
+
+// symbolChars are the characters symbol atoms are made from, a `.` after one of them is part of the atom
+const symbolChars = "+-*/\\^<>=~:.?@#&$"
+
+// peekRune returns the next rune without reading it, 0 at the end of the stream
+func peekRune(rd *bufio.Reader) rune {
+	ru, _, err := rd.ReadRune()
+	if err != nil {
+		return 0
+	}
+	_ = rd.UnreadRune()
+	return ru
+}
+
+// skipBlockComment reads up to the end of a `/* */` comment whose `/` has been read
+func skipBlockComment(rd *bufio.Reader) error {
+	var last rune
+	if _, _, err := rd.ReadRune(); err != nil {
+		return err
+	}
+	for {
+		ru, _, err := rd.ReadRune()
+		if err == io.EOF {
+			return errUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		if last == '*' && ru == '/' {
+			return nil
+		}
+		last = ru
+	}
+}
+
+/**
+ * readClauseText reads up to and including the `.` which ends the next clause and returns the text before it.
+ * A clause ends with a `.` followed by whitespace, a comment or the end of the stream, `.`s inside quotes,
+ * comments, numbers or symbol atoms like `=..` dont count. If there is nothing but whitespace and comments left,
+ * eof is returned as true.
+ */
+func readClauseText(rd *bufio.Reader) (string, bool, error) {
+	var sb strings.Builder
+	var quote, prev rune
+	started := false
+	for {
+		last := prev
+		ru, _, err := rd.ReadRune()
+		prev = ru
+		if err == io.EOF {
+			if !started {
+				return "", true, nil
+			}
+			return "", false, errUnexpectedEOF
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		if quote != 0 {
+			sb.WriteRune(ru)
+			if ru == '\\' {
+				if next, _, err := rd.ReadRune(); err == nil {
+					sb.WriteRune(next)
+				}
+			} else if ru == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ru == '%':
+			if _, err := rd.ReadString('\n'); err == io.EOF && !started {
+				return "", true, nil
+			}
+			continue
+		case ru == '/' && peekRune(rd) == '*':
+			if err := skipBlockComment(rd); err != nil {
+				return "", false, err
+			}
+			// the comment separates the tokens either side of it
+			ru = ' '
+		case ru == '\'' && last == '0':
+			// a `0'c` character code, the quote doesnt start a quoted atom
+			sb.WriteRune(ru)
+			if next, _, err := rd.ReadRune(); err == nil {
+				sb.WriteRune(next)
+				if next == '\\' || (next == '\'' && peekRune(rd) == '\'') {
+					next, _, _ = rd.ReadRune()
+					sb.WriteRune(next)
+				}
+			}
+			continue
+		case ru == '"' || ru == '\'' || ru == '`':
+			quote = ru
+		case ru == '.' && started && !strings.ContainsRune(symbolChars, last):
+			next, _, err := rd.ReadRune()
+			if err == io.EOF {
+				return sb.String(), false, nil
+			}
+			if err != nil {
+				return "", false, err
+			}
+			if unicode.IsSpace(next) {
+				return sb.String(), false, nil
+			}
+			_ = rd.UnreadRune()
+			if next == '%' {
+				return sb.String(), false, nil
+			}
+		}
+
+		if !unicode.IsSpace(ru) {
+			started = true
+		}
+		if started {
+			sb.WriteRune(ru)
+		}
+	}
+}
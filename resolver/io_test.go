@@ -0,0 +1,226 @@
+package resolver_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// solve resolves the goals as a single query and returns every solution
+func solve(r *resolver.R, goals ...ast.Statement) []*resolver.Bindings {
+	out := make(chan *resolver.Bindings, 1)
+	q := ast.Query(goals)
+	go r.ResolveStatementList(context.Background(), []ast.Statement{&q}, resolver.EmptyBindings(), out)
+	results := []*resolver.Bindings{}
+	for b := range out {
+		results = append(results, b)
+	}
+	return results
+}
+
+// expectOne checks that there is exactly one solution and that each variable grounds to the expected term
+func expectOne(t *testing.T, label string, results []*resolver.Bindings, expected map[string]string) {
+	if len(results) != 1 {
+		t.Fatalf("%s: expected 1 solution, got %d (%v)", label, len(results), results)
+	}
+	if results[0].IsException() {
+		t.Fatalf("%s: unexpected exception %s", label, results[0].Exception)
+	}
+	for k, v := range expected {
+		if got := results[0].Ground(ast.CreateVariable(k)).String(); got != v {
+			t.Errorf("%s: expected %s = %s, got %s", label, k, v, got)
+		}
+	}
+}
+
+// expectError checks that the only result is an `error(Formal, _)` exception
+func expectError(t *testing.T, label string, results []*resolver.Bindings, formal string) {
+	if len(results) != 1 || !results[0].IsException() {
+		t.Fatalf("%s: expected an exception, got %v", label, results)
+	}
+	ball, ok := results[0].Exception.(*ast.Fact)
+	if !ok || ball.Head != "error" || len(ball.Args) != 2 {
+		t.Fatalf("%s: expected an error term, got %s", label, results[0].Exception)
+	}
+	if got := ball.Args[0].String(); got != formal {
+		t.Errorf("%s: expected %s, got %s", label, formal, got)
+	}
+}
+
+func TestStreamReadWrite(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	dir, err := ioutil.TempDir("", "gopl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := s(filepath.Join(dir, "out.pl"))
+	r := resolver.New(indexer.NewDefault())
+
+	expectOne(t, "write a file", solve(r,
+		f("open", path, a("write"), v("S")),
+		f("writeq", v("S"), f("point", num(1), a("A b"))),
+		f("write", v("S"), a(".")),
+		f("nl", v("S")),
+		f("format", v("S"), s("pair(~w, ~w). % comment~n"), ast.CreateList(v("X"), v("Y"))),
+		f("close", v("S")),
+	), nil)
+
+	content, _ := ioutil.ReadFile(path.String())
+	if string(content) != "point(1,'A b').\npair(X, Y). % comment\n" {
+		t.Errorf("unexpected file content %q", content)
+	}
+
+	// the `.`s in quotes, comments, character codes and symbol atoms dont end the clause
+	src := "point(1, 'a. b').\n/* not. the end */ pair(X, Y, X). % comment\n  foo, bar.\nops(=.., 0'., '.').\n"
+	if err := ioutil.WriteFile(path.String(), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectOne(t, "read terms", solve(r,
+		f("open", path, a("read"), v("S"), ast.CreateList(f("alias", a("in")))),
+		f("read", a("in"), v("T1")),
+		f("read_term", v("S"), v("T2"), ast.CreateList(f("variable_names", v("V")))),
+		f("read", v("S"), v("T3")),
+		f("read", v("S"), v("T4")),
+		f("read", v("S"), v("T5")),
+		f("close", a("in")),
+	), map[string]string{
+		"T1": "point(1.000000,a. b)",
+		"T4": "ops(=..,46.000000,.)",
+		"T5": "end_of_file",
+	})
+
+	expectOne(t, "read with variable names", solve(r,
+		f("open", path, a("read"), v("S")),
+		f("read", v("S"), v("T")),
+		f("read_term", v("S"), f("pair", v("A"), v("B"), v("C")), ast.CreateList(f("variable_names", v("V")))),
+		f("read", v("S"), f(",", v("P"), v("Q"))),
+		f("close", v("S")),
+		f("=", v("A"), num(1)),
+		f("=", v("B"), num(2)),
+	), map[string]string{"P": "foo", "Q": "bar", "C": "1.000000", "V": "L[=(X,1.000000),=(Y,2.000000)]"})
+}
+
+func TestStreamChars(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	r := resolver.New(indexer.NewDefault())
+	r.SetInput(bytes.NewBufferString("ab\nline two\n"))
+
+	expectOne(t, "get and peek", solve(r,
+		f("peek_char", v("A")),
+		f("get_char", v("B")),
+		f("get_char", a("user_input"), v("C")),
+		f("read_line_to_string", a("user_input"), v("L1")),
+		f("read_line_to_string", a("user_input"), v("L2")),
+		f("at_end_of_stream"),
+		f("read_line_to_string", a("user_input"), v("L3")),
+		f("get_char", v("E")),
+	), map[string]string{"A": "a", "B": "a", "C": "b", "L1": "", "L2": "line two", "L3": "end_of_file", "E": "end_of_file"})
+
+	var buf bytes.Buffer
+	r.SetOutput(&buf)
+	expectOne(t, "put_char", solve(r,
+		f("put_char", a("x")),
+		f("with_output_to", f("string", v("S")), f(",", f("put_char", a("y")), f("current_output", v("O")))),
+		f("put_char", a("user_output"), a("z")),
+	), map[string]string{"S": "y"})
+	if buf.String() != "xz" {
+		t.Errorf("expected xz, got %q", buf.String())
+	}
+
+	expectError(t, "put_char unbound", solve(r, f("put_char", v("X"))), "instantiation_error")
+	expectError(t, "put_char not a char", solve(r, f("put_char", a("xy"))), "type_error(character,xy)")
+	expectError(t, "get_char bad char", solve(r, f("get_char", num(1))), "type_error(in_character,1.000000)")
+	expectError(t, "write to input", solve(r, f("write", a("user_input"), a("x"))), "permission_error(output,stream,user_input)")
+	expectError(t, "read from output", solve(r, f("get_char", a("user_output"), v("C"))), "permission_error(input,stream,user_output)")
+	expectError(t, "unknown alias", solve(r, f("write", a("nope"), a("x"))), "existence_error(stream,nope)")
+	expectError(t, "not a stream", solve(r, f("write", num(3), a("x"))), "domain_error(stream_or_alias,3.000000)")
+	expectError(t, "bad mode", solve(r, f("open", s("x"), a("sideways"), v("S"))), "domain_error(io_mode,sideways)")
+	expectError(t, "missing file", solve(r, f("open", s("/does/not/exist"), a("read"), v("S"))), "existence_error(source_sink,/does/not/exist)")
+	expectError(t, "bound stream", solve(r, f("open", s("x"), a("read"), a("s"))), "uninstantiation_error(s)")
+	expectError(t, "bad option", solve(r, f("open", s("x"), a("read"), v("S"), ast.CreateList(f("colour", a("red"))))), "domain_error(stream_option,colour(red))")
+}
+
+func TestStreamSetOutput(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	dir, err := ioutil.TempDir("", "gopl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := s(filepath.Join(dir, "out.txt"))
+
+	var buf bytes.Buffer
+	r := resolver.New(indexer.NewDefault())
+	r.SetOutput(&buf)
+	expectOne(t, "set_output", solve(r,
+		f("open", path, a("write"), v("S")),
+		f("set_output", v("S")),
+		f("writeln", a("to file")),
+		f("current_output", v("S")),
+		f("close", v("S")),
+		f("writeln", a("to user")),
+	), nil)
+
+	content, _ := ioutil.ReadFile(path.String())
+	if string(content) != "to file\n" {
+		t.Errorf("unexpected file content %q", content)
+	}
+	if buf.String() != "to user\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	expectError(t, "closed stream", solve(r,
+		f("open", path, a("read"), v("S"), ast.CreateList(f("eof_action", a("error")))),
+		f("close", v("S")),
+		f("get_char", v("S"), v("C")),
+	), "existence_error(stream,$stream(4.000000))")
+
+	expectError(t, "past end of stream", solve(r,
+		f("open", path, a("read"), v("S"), ast.CreateList(f("eof_action", a("error")))),
+		f("read_line_to_string", v("S"), v("L1")),
+		f("read_line_to_string", v("S"), v("L2")),
+		f("read_line_to_string", v("S"), v("L3")),
+	), "permission_error(input,past_end_of_stream,$stream(5.000000))")
+}
+
+func TestCatchThrow(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	r := resolver.New(indexer.NewDefault())
+
+	expectOne(t, "catch", solve(r, f("catch", f("throw", f("oops", a("a"))), f("oops", v("X")), f("=", v("Y"), a("recovered")))), map[string]string{"X": "a", "Y": "recovered"})
+	expectOne(t, "catch iso error", solve(r, f("catch", f("put_char", v("C")), f("error", v("E"), v("_")), a("true"))), map[string]string{"E": "instantiation_error"})
+	expectOne(t, "nothing thrown", solve(r, f("catch", f("=", v("X"), a("a")), v("_"), a("true"))), map[string]string{"X": "a"})
+
+	results := solve(r, f("catch", f("throw", a("a")), a("b"), a("true")))
+	if len(results) != 1 || !results[0].IsException() || results[0].Exception.String() != "a" {
+		t.Errorf("expected the exception to pass through, got %v", results)
+	}
+
+	// exceptions stop the rest of the query and escape findall
+	results = solve(r, f("findall", v("X"), f(",", f("=", v("X"), a("a")), f("throw", a("stop"))), v("L")), f("=", v("Y"), a("b")))
+	if len(results) != 1 || !results[0].IsException() || results[0].Exception.String() != "stop" {
+		t.Errorf("expected the exception from findall, got %v", results)
+	}
+}
@@ -0,0 +1,279 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * Limits bounds the resources a single query can use, a zero value means there is no limit.
+ * When a limit is reached the query raises `error(resource_error(Resource), _)` where Resource
+ * is one of inferences, depth, time or bindings.
+ */
+type Limits struct {
+	// MaxInferences is the maximum number of goals the query can try to prove
+	MaxInferences int64
+	// MaxDepth is the maximum number of nested rule calls
+	MaxDepth int
+	// Timeout is the maximum amount of time the query can run for
+	Timeout time.Duration
+	// MaxBindings is the maximum number of variable bindings a single branch of the query can hold
+	MaxBindings int
+}
+
+// Option configures a resolver created with New
+type Option func(*R)
+
+// WithLimits sets the resource limits used for every query
+func WithLimits(l Limits) Option {
+	return func(r *R) {
+		r.limits = l
+	}
+}
+
+func WithMaxInferences(n int64) Option {
+	return func(r *R) {
+		r.limits.MaxInferences = n
+	}
+}
+
+func WithMaxDepth(n int) Option {
+	return func(r *R) {
+		r.limits.MaxDepth = n
+	}
+}
+
+func WithTimeout(d time.Duration) Option {
+	return func(r *R) {
+		r.limits.Timeout = d
+	}
+}
+
+func WithMaxBindings(n int) Option {
+	return func(r *R) {
+		r.limits.MaxBindings = n
+	}
+}
+
+// query holds the state shared by every branch of a single query
+type query struct {
+	limits     Limits
+	inferences int64
+	// names maps the resolver's variable names back to the ones in the indexed clauses, it is only filled in while
+	// debugging and is guarded by the debugger's lock
+	names map[string]string
+}
+
+/**
+ * depthScope is created by call_with_depth_limit/3.
+ * Going deeper than the scope's limit makes that branch fail instead of raising an error,
+ * the scope remembers that it happened along with the deepest level that was reached.
+ */
+type depthScope struct {
+	lock     sync.Mutex
+	base     int
+	limit    int
+	deepest  int
+	exceeded bool
+}
+
+/**
+ * frame is the context used while resolving a query, it tracks the query's limits and how deep the current branch is.
+ * A frame never wraps another frame, so the context chain doesnt grow as the recursion gets deeper.
+ */
+type frame struct {
+	context.Context
+	query *query
+	depth int
+	scope *depthScope
+	// bindings is how many bindings the calls this branch is nested in hold, which stay alive until it is done
+	bindings int
+
+	// deadline is the earliest time limit that applies to this branch and ball is what is thrown when it passes
+	deadline time.Time
+	ball     ast.Term
+
+	// profile collects statistics about the goals in this branch and call is the goal being profiled that they belong to
+	profile *Profile
+	call    *profileCall
+
+	// module is the context module goals in this branch are looked up in, empty for indexer.User
+	module string
+
+	// table is the tabled call being evaluated on this branch, see tabledAnswers
+	table *tableCall
+}
+
+type frameKey struct{}
+
+func (f *frame) Value(key interface{}) interface{} {
+	if key == (frameKey{}) {
+		return f
+	}
+	return f.Context.Value(key)
+}
+
+func frameFrom(ctx context.Context) *frame {
+	f, _ := ctx.Value(frameKey{}).(*frame)
+	return f
+}
+
+// with creates a copy of the frame on top of ctx, ctx can be the frame itself or a context derived from it
+func (f *frame) with(ctx context.Context) *frame {
+	next := *f
+	if cf, ok := ctx.(*frame); ok {
+		next.Context = cf.Context
+	} else {
+		next.Context = ctx
+	}
+	return &next
+}
+
+// startQuery sets up the frame for a new query unless ctx already belongs to one
+func (r *R) startQuery(ctx context.Context) context.Context {
+	if frameFrom(ctx) != nil {
+		return ctx
+	}
+	f := &frame{Context: ctx, query: &query{limits: r.limits}, profile: r.profile}
+	if r.limits.Timeout > 0 {
+		f.deadline = time.Now().Add(r.limits.Timeout)
+		f.ball = resourceError("time").Exception
+	}
+	return f
+}
+
+// deeper returns the context used to resolve the body of a rule called with the bindings c
+func deeper(ctx context.Context, c *Bindings) context.Context {
+	f := frameFrom(ctx)
+	if f == nil {
+		return ctx
+	}
+	next := f.with(ctx)
+	next.depth = f.depth + 1
+	next.bindings = f.bindings + len(c.B)
+	return next
+}
+
+func resourceError(resource string) *Bindings {
+	return isoError(ast.CreateFact("resource_error", ast.CreateAtom(resource)))
+}
+
+/**
+ * checkLimits is called before every inference.
+ * It returns an exception if one of the query's limits was reached, or ok = false if the branch
+ * should fail because it went past the depth limit of call_with_depth_limit/3.
+ */
+func checkLimits(ctx context.Context, c *Bindings) (ex *Bindings, ok bool) {
+	f := frameFrom(ctx)
+	if f == nil {
+		return nil, true
+	}
+	limits := f.query.limits
+
+	if n := atomic.AddInt64(&f.query.inferences, 1); limits.MaxInferences > 0 && n > limits.MaxInferences {
+		return resourceError("inferences"), false
+	}
+	if limits.MaxDepth > 0 && f.depth > limits.MaxDepth {
+		return resourceError("depth"), false
+	}
+	if limits.MaxBindings > 0 && f.bindings+len(c.B) > limits.MaxBindings {
+		return resourceError("bindings"), false
+	}
+	if !f.deadline.IsZero() && time.Now().After(f.deadline) {
+		return Throw(f.ball), false
+	}
+
+	if s := f.scope; s != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if f.depth > s.limit {
+			s.exceeded = true
+			return nil, false
+		}
+		if f.depth-s.base > s.deepest {
+			s.deepest = f.depth - s.base
+		}
+	}
+	return nil, true
+}
+
+/**
+ * newLimits provides the builtins which limit part of a query.
+ *   call_with_time_limit(Time, Goal) runs Goal, throwing time_limit_exceeded if it takes longer than Time seconds
+ *   call_with_depth_limit(Goal, Limit, Result) runs Goal without going more than Limit levels deep.
+ *     For each solution Result is the deepest level used so far, once there are no more solutions Result
+ *     is depth_limit_exceeded if the limit cut off part of the search.
+ */
+func newLimits(r *R) nativePredicates {
+	return nativePredicates{
+		"call_with_time_limit/2":  r.callWithTimeLimit,
+		"call_with_depth_limit/3": r.callWithDepthLimit,
+	}
+}
+
+func (r *R) callWithTimeLimit(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	seconds, err := evalArithmetic(args[0], c)
+	if err != nil {
+		send(ctx, out, typeError("evaluable", c.Ground(args[0])))
+		return
+	}
+
+	ctx = r.startQuery(ctx)
+	f := frameFrom(ctx).with(ctx)
+	deadline := time.Now().Add(time.Duration(seconds * float64(time.Second)))
+	if f.deadline.IsZero() || deadline.Before(f.deadline) {
+		f.deadline = deadline
+		f.ball = ast.CreateAtom("time_limit_exceeded")
+	}
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(f, args[1], c, solutions)
+	for b := range solutions {
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+func (r *R) callWithDepthLimit(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	limit, ok := intValue(args[1], c)
+	if !ok {
+		if c.Dereference(args[1]).GetType() == ast.T_Variable {
+			send(ctx, out, instantiationError())
+		} else {
+			send(ctx, out, typeError("integer", c.Ground(args[1])))
+		}
+		return
+	}
+
+	ctx = r.startQuery(ctx)
+	f := frameFrom(ctx).with(ctx)
+	// the goal itself counts as the first level
+	scope := &depthScope{base: f.depth - 1, limit: f.depth - 1 + limit}
+	f.scope = scope
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(f, args[0], c, solutions)
+	for b := range solutions {
+		if b.IsException() {
+			send(ctx, out, b)
+			return
+		}
+		scope.lock.Lock()
+		deepest := scope.deepest
+		scope.lock.Unlock()
+		if result := unifyTerms(args[2], ast.CreateNumericLiteral(float64(deepest)), b); result != nil {
+			if !send(ctx, out, result) {
+				return
+			}
+		}
+	}
+
+	if scope.exceeded {
+		unifyAndSend(ctx, args[2], ast.CreateAtom("depth_limit_exceeded"), c, out)
+	}
+}
@@ -0,0 +1,74 @@
+package resolver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// limitsIndex contains endless loops and a short chain of rules
+//
+//	loop :- loop.
+//	big(L) :- big([x|L]).
+//	a :- b.
+//	b :- c.
+//	c.
+func limitsIndex() indexer.Indexer {
+	f := ast.CreateFact
+	i := indexer.NewDefault()
+	i.IndexStatement(ast.CreateRule(f("loop"), f("loop")))
+	i.IndexStatement(ast.CreateRule(f("big", ast.CreateVariable("L")), f("big", ast.CreatePartialList([]ast.Term{ast.CreateAtom("x")}, ast.CreateVariable("L")))))
+	i.IndexStatement(ast.CreateRule(f("a"), f("b")))
+	i.IndexStatement(ast.CreateRule(f("b"), f("c")))
+	i.IndexStatement(f("c"))
+	return i
+}
+
+func TestResourceLimits(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	r := resolver.New(limitsIndex(), resolver.WithMaxInferences(100))
+	expectError(t, "inferences", solve(r, f("loop")), "resource_error(inferences)")
+	expectOne(t, "inferences are counted per query", solve(r, f("a")), nil)
+
+	r = resolver.New(limitsIndex(), resolver.WithMaxDepth(50))
+	expectError(t, "depth", solve(r, f("loop")), "resource_error(depth)")
+
+	r = resolver.New(limitsIndex(), resolver.WithTimeout(20*time.Millisecond))
+	expectError(t, "timeout", solve(r, f("loop")), "resource_error(time)")
+
+	r = resolver.New(limitsIndex(), resolver.WithMaxBindings(2))
+	expectError(t, "bindings", solve(r,
+		f("=", v("X"), a("x")),
+		f("=", v("Y"), a("y")),
+		f("=", v("Z"), a("z")),
+		f("true"),
+	), "resource_error(bindings)")
+	// the bindings held by each call a recursive rule is nested in count towards the limit too
+	r = resolver.New(limitsIndex(), resolver.WithMaxBindings(20))
+	expectError(t, "bindings in recursion", solve(r, f("big", ast.CreateList())), "resource_error(bindings)")
+
+	r = resolver.New(limitsIndex(), resolver.WithLimits(resolver.Limits{MaxDepth: 2, MaxInferences: 3}))
+	expectOne(t, "within the limits", solve(r, f("a")), nil)
+}
+
+func TestCallWithLimits(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+
+	r := resolver.New(limitsIndex())
+	results := solve(r, f("call_with_time_limit", num(0.02), f("loop")))
+	if len(results) != 1 || !results[0].IsException() || results[0].Exception.String() != "time_limit_exceeded" {
+		t.Errorf("expected time_limit_exceeded, got %v", results)
+	}
+	expectOne(t, "time limit not reached", solve(r, f("call_with_time_limit", num(1), f("a"))), nil)
+
+	expectOne(t, "depth used", solve(r, f("call_with_depth_limit", f("a"), num(5), v("R"))), map[string]string{"R": "3.000000"})
+	expectOne(t, "depth exceeded", solve(r, f("call_with_depth_limit", f("a"), num(2), v("R"))), map[string]string{"R": "depth_limit_exceeded"})
+	expectOne(t, "depth limited loop", solve(r, f("call_with_depth_limit", f("loop"), num(20), v("R"))), map[string]string{"R": "depth_limit_exceeded"})
+}
@@ -0,0 +1,436 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * Lists provides native implementations of the list predicates which would be too slow
+ * (or impossible without cut) to write in the standard library itself.
+ * The rest of the list library lives in `library/lists.pl`.
+ */
+type Lists struct {
+	r *R
+}
+
+func newLists(r *R) nativePredicates {
+	w := &Lists{r: r}
+	return nativePredicates{
+		"length/2":    w.length,
+		"memberchk/2": w.memberchk,
+		"nth0/3":      func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) { w.nth(ctx, 0, a, c, out) },
+		"nth1/3":      func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) { w.nth(ctx, 1, a, c, out) },
+		"msort/2":     w.msort,
+		"sort/2":      w.sort,
+		"sort/4":      w.sort4,
+		"predsort/3":  w.predsort,
+		"keysort/2":   w.keysort,
+		"sum_list/2":  w.sumList,
+		"max_list/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.extremeList(ctx, true, a, c, out)
+		},
+		"min_list/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.extremeList(ctx, false, a, c, out)
+		},
+		"numlist/3":   w.numlist,
+		"include/3":   w.include,
+		"exclude/3":   w.exclude,
+		"partition/4": w.partition,
+	}
+}
+
+// properList returns the items in a list if the term is a proper list
+func properList(t ast.Term, c *Bindings) ([]ast.Term, bool) {
+	items, tail := ast.ListToSlice(c.Ground(t))
+	if !ast.IsEmptyList(tail) {
+		return nil, false
+	}
+	return items, true
+}
+
+// intValue returns the integer value of a term if it is bound to an integral number
+func intValue(t ast.Term, c *Bindings) (int, bool) {
+	t = c.Dereference(t)
+	if t.GetType() != ast.T_Number {
+		return 0, false
+	}
+	v := t.(*ast.NumericLiteral).Value()
+	if v != math.Trunc(v) {
+		return 0, false
+	}
+	return int(v), true
+}
+
+func unifyAndSend(ctx context.Context, lhs ast.Term, rhs ast.Term, c *Bindings, out chan<- *Bindings) {
+	if b := unifyTerms(lhs, rhs, c); b != nil {
+		send(ctx, out, b)
+	}
+}
+
+/**
+ * length(List, Length)
+ * If List is a partial list and Length is unbound, lists of increasing length will be generated forever.
+ */
+func (w *Lists) length(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items, tail := ast.ListToSlice(c.Ground(args[0]))
+	if ast.IsEmptyList(tail) {
+		unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(float64(len(items))), c, out)
+		return
+	}
+	if tail.GetType() != ast.T_Variable {
+		return
+	}
+
+	// the list is partial, fill in the rest of it with fresh variables
+	if n, ok := intValue(args[1], c); ok {
+		if n < len(items) {
+			return
+		}
+		unifyAndSend(ctx, tail, w.freshList(n-len(items)), c, out)
+		return
+	} else if c.Dereference(args[1]).GetType() != ast.T_Variable {
+		return
+	}
+
+	for extra := 0; ; extra++ {
+		b := unifyTerms(tail, w.freshList(extra), c)
+		if b == nil {
+			return
+		}
+		b = unifyTerms(args[1], ast.CreateNumericLiteral(float64(len(items)+extra)), b)
+		if b != nil && !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+// freshList creates a list of n new variables
+func (w *Lists) freshList(n int) *ast.Fact {
+	items := make([]ast.Term, n)
+	for i := range items {
+		items[i] = w.r.freshVariable()
+	}
+	return ast.CreateList(items...)
+}
+
+// memberchk(Elem, List) is true if Elem unifies with an item in List, only the first match is used.
+func (w *Lists) memberchk(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items, _ := ast.ListToSlice(c.Ground(args[1]))
+	for _, item := range items {
+		if b := unifyTerms(args[0], item, c); b != nil {
+			send(ctx, out, b)
+			return
+		}
+	}
+}
+
+// nth0(Index, List, Elem) and nth1(Index, List, Elem), if Index is unbound every position is enumerated.
+func (w *Lists) nth(ctx context.Context, base int, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items, _ := ast.ListToSlice(c.Ground(args[1]))
+	if i, ok := intValue(args[0], c); ok {
+		if i-base >= 0 && i-base < len(items) {
+			unifyAndSend(ctx, args[2], items[i-base], c, out)
+		}
+		return
+	} else if c.Dereference(args[0]).GetType() != ast.T_Variable {
+		return
+	}
+
+	for i, item := range items {
+		b := unifyTerms(args[0], ast.CreateNumericLiteral(float64(i+base)), c)
+		if b == nil {
+			continue
+		}
+		unifyAndSend(ctx, args[2], item, b, out)
+	}
+}
+
+// msort(List, Sorted) sorts the list in the standard order of terms without removing duplicates.
+func (w *Lists) msort(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if items, ok := properList(args[0], c); ok {
+		unifyAndSend(ctx, args[1], ast.CreateList(sortTerms(items, false)...), c, out)
+	}
+}
+
+// sort(List, Sorted) sorts the list in the standard order of terms and removes duplicates.
+func (w *Lists) sort(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if items, ok := properList(args[0], c); ok {
+		unifyAndSend(ctx, args[1], ast.CreateList(sortTerms(items, true)...), c, out)
+	}
+}
+
+/**
+ * sort(Key, Order, List, Sorted)
+ * Key is 0 to sort on the whole term or N to sort on the Nth argument of each item.
+ * Order is one of @< and @> (removing duplicates) or @=< and @>= (keeping duplicates).
+ */
+func (w *Lists) sort4(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	key, ok := intValue(args[0], c)
+	if !ok || key < 0 {
+		return
+	}
+	items, ok := properList(args[2], c)
+	if !ok {
+		return
+	}
+
+	order := c.Dereference(args[1]).String()
+	descending := order == "@>" || order == "@>="
+	dedupe := order == "@<" || order == "@>"
+	if !descending && !dedupe && order != "@=<" {
+		return
+	}
+
+	keys := make([]ast.Term, len(items))
+	for i, item := range items {
+		if key == 0 {
+			keys[i] = item
+			continue
+		}
+		if item.GetType() != ast.T_Fact || len(item.(*ast.Fact).Args) < key {
+			return
+		}
+		keys[i] = item.(*ast.Fact).Args[key-1]
+	}
+
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		cmp := ast.CompareTerms(keys[idx[i]], keys[idx[j]])
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	sorted := []ast.Term{}
+	for i, v := range idx {
+		if dedupe && i > 0 && ast.CompareTerms(keys[idx[i-1]], keys[v]) == 0 {
+			continue
+		}
+		sorted = append(sorted, items[v])
+	}
+	unifyAndSend(ctx, args[3], ast.CreateList(sorted...), c, out)
+}
+
+/**
+ * predsort(Pred, List, Sorted) sorts the list using call(Pred, Order, A, B) to compare items.
+ * Pred should bind Order to one of <, > or =. Items which compare as = are removed.
+ */
+func (w *Lists) predsort(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items, ok := properList(args[1], c)
+	if !ok {
+		return
+	}
+
+	pred := c.Dereference(args[0])
+	var thrown *Bindings
+	compare := func(a ast.Term, b ast.Term) (string, bool) {
+		order := w.r.freshVariable()
+		goal := addArgs(pred, []ast.Term{order, a, b})
+		if goal == nil {
+			return "", false
+		}
+		result := w.r.solveOnce(ctx, goal, c)
+		if result == nil {
+			return "", false
+		}
+		if result.IsException() {
+			thrown = result
+			return "", false
+		}
+		o := result.Dereference(order).String()
+		return o, o == "<" || o == ">" || o == "="
+	}
+
+	var mergeSort func(items []ast.Term) ([]ast.Term, bool)
+	mergeSort = func(items []ast.Term) ([]ast.Term, bool) {
+		if len(items) <= 1 {
+			return items, true
+		}
+		lhs, ok := mergeSort(items[:len(items)/2])
+		if !ok {
+			return nil, false
+		}
+		rhs, ok := mergeSort(items[len(items)/2:])
+		if !ok {
+			return nil, false
+		}
+
+		merged := []ast.Term{}
+		for len(lhs) > 0 && len(rhs) > 0 {
+			o, ok := compare(lhs[0], rhs[0])
+			if !ok {
+				return nil, false
+			}
+			switch o {
+			case "<":
+				merged = append(merged, lhs[0])
+				lhs = lhs[1:]
+			case ">":
+				merged = append(merged, rhs[0])
+				rhs = rhs[1:]
+			default:
+				merged = append(merged, lhs[0])
+				lhs = lhs[1:]
+				rhs = rhs[1:]
+			}
+		}
+		merged = append(merged, lhs...)
+		return append(merged, rhs...), true
+	}
+
+	sorted, ok := mergeSort(items)
+	if thrown != nil {
+		send(ctx, out, thrown)
+		return
+	}
+	if ok {
+		unifyAndSend(ctx, args[2], ast.CreateList(sorted...), c, out)
+	}
+}
+
+// keysort(Pairs, Sorted) stable sorts a list of Key-Value pairs by their keys.
+func (w *Lists) keysort(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items, ok := properList(args[0], c)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		if item.GetType() != ast.T_Fact || item.(*ast.Fact).Signature().String() != "-/2" {
+			return
+		}
+	}
+
+	sorted := make([]ast.Term, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ast.CompareTerms(sorted[i].(*ast.Fact).Args[0], sorted[j].(*ast.Fact).Args[0]) < 0
+	})
+	unifyAndSend(ctx, args[1], ast.CreateList(sorted...), c, out)
+}
+
+func (w *Lists) numbers(t ast.Term, c *Bindings) ([]float64, bool) {
+	items, ok := properList(t, c)
+	if !ok {
+		return nil, false
+	}
+	values := make([]float64, len(items))
+	for i, item := range items {
+		v, err := evalArithmetic(item, c)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// sum_list(List, Sum)
+func (w *Lists) sumList(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	values, ok := w.numbers(args[0], c)
+	if !ok {
+		return
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum = sum + v
+	}
+	unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(sum), c, out)
+}
+
+// max_list(List, Max) and min_list(List, Min), both fail for an empty list.
+func (w *Lists) extremeList(ctx context.Context, max bool, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	values, ok := w.numbers(args[0], c)
+	if !ok || len(values) == 0 {
+		return
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		if (max && v > best) || (!max && v < best) {
+			best = v
+		}
+	}
+	unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(best), c, out)
+}
+
+// numlist(Low, High, List) creates the list [Low, Low+1, ..., High]
+func (w *Lists) numlist(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	low, ok := intValue(args[0], c)
+	if !ok {
+		return
+	}
+	high, ok := intValue(args[1], c)
+	if !ok || high < low {
+		return
+	}
+	items := []ast.Term{}
+	for i := low; i <= high; i++ {
+		items = append(items, ast.CreateNumericLiteral(float64(i)))
+	}
+	unifyAndSend(ctx, args[2], ast.CreateList(items...), c, out)
+}
+
+/**
+ * filter splits the list into the items for which call(Goal, Item) succeeds and those for which it doesnt.
+ * If the goal raises an exception it is sent to out and filter returns false.
+ */
+func (w *Lists) filter(ctx context.Context, goal ast.Term, list ast.Term, c *Bindings, out chan<- *Bindings) ([]ast.Term, []ast.Term, bool) {
+	items, ok := properList(list, c)
+	if !ok {
+		return nil, nil, false
+	}
+	goal = c.Dereference(goal)
+
+	included, excluded := []ast.Term{}, []ast.Term{}
+	for _, item := range items {
+		g := addArgs(goal, []ast.Term{item})
+		if g == nil {
+			return nil, nil, false
+		}
+		result := w.r.solveOnce(ctx, g, c)
+		if result.IsException() {
+			send(ctx, out, result)
+			return nil, nil, false
+		}
+		if result != nil {
+			included = append(included, item)
+		} else {
+			excluded = append(excluded, item)
+		}
+	}
+	return included, excluded, true
+}
+
+// include(Goal, List, Included)
+func (w *Lists) include(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if included, _, ok := w.filter(ctx, args[0], args[1], c, out); ok {
+		unifyAndSend(ctx, args[2], ast.CreateList(included...), c, out)
+	}
+}
+
+// exclude(Goal, List, Excluded)
+func (w *Lists) exclude(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if _, excluded, ok := w.filter(ctx, args[0], args[1], c, out); ok {
+		unifyAndSend(ctx, args[2], ast.CreateList(excluded...), c, out)
+	}
+}
+
+// partition(Goal, List, Included, Excluded)
+func (w *Lists) partition(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	included, excluded, ok := w.filter(ctx, args[0], args[1], c, out)
+	if !ok {
+		return
+	}
+	b := unifyTerms(args[2], ast.CreateList(included...), c)
+	if b == nil {
+		return
+	}
+	unifyAndSend(ctx, args[3], ast.CreateList(excluded...), b, out)
+}
@@ -0,0 +1,146 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+/**
+ * newModules provides the builtins for working with modules.
+ *   Module:Goal            - resolves Goal in Module rather than the context module
+ *   meta_predicate(Spec)   - marks the arguments of a predicate defined in the context module which are goals,
+ *                            i.e. `:- meta_predicate maplist(1, ?)`. When the predicate is called those arguments
+ *                            (0-9, `:` and `^` in the spec) are qualified with the caller's context module so
+ *                            they run there rather than in the module the predicate is defined in.
+ *   context_module(M)      - M is the context module of the goal
+ * Every query starts in the user module, the body of a clause runs in the module it is defined in.
+ * Indexers which dont implement indexer.Modules put everything in the user module.
+ */
+func newModules(r *R) nativePredicates {
+	return nativePredicates{
+		":/2":              r.qualified,
+		"meta_predicate/1": r.metaPredicate,
+		"context_module/1": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			unifyAndSend(ctx, args[0], ast.CreateAtom(contextModule(ctx)), c, out)
+		},
+	}
+}
+
+// contextModule returns the module goals are looked up in on this branch
+func contextModule(ctx context.Context) string {
+	if f := frameFrom(ctx); f != nil && f.module != "" {
+		return f.module
+	}
+	return indexer.User
+}
+
+// inModule returns the context used to resolve goals in the module
+func inModule(ctx context.Context, module string) context.Context {
+	f := frameFrom(ctx)
+	if f == nil {
+		f = &frame{Context: ctx, query: &query{}}
+	} else if contextModule(ctx) == module {
+		return ctx
+	} else {
+		f = f.with(ctx)
+	}
+	f.module = module
+	return f
+}
+
+// clauses returns the clauses the goal resolves to in the context module along with the module that defines them
+func (r *R) clauses(ctx context.Context, f *ast.Fact) (string, []ast.Statement) {
+	modules, ok := r.i.(indexer.Modules)
+	if !ok {
+		return indexer.User, r.i.StatementsForSignature(f.Signature())
+	}
+	return modules.ModuleStatements(contextModule(ctx), f.Signature())
+}
+
+// qualifyMetaArgs qualifies the goal arguments of a meta predicate with the context module of the caller
+func (r *R) qualifyMetaArgs(ctx context.Context, module string, goal *ast.Fact) *ast.Fact {
+	modules, ok := r.i.(indexer.Modules)
+	if !ok {
+		return goal
+	}
+	spec, ok := modules.MetaPredicate(module, goal.Signature())
+	if !ok {
+		return goal
+	}
+	caller := ast.CreateAtom(contextModule(ctx))
+	args := make([]ast.Term, len(goal.Args))
+	for i, a := range goal.Args {
+		args[i] = a
+		if isMetaArg(spec.Args[i]) && !isQualified(a) {
+			args[i] = ast.CreateFact(":", caller, a)
+		}
+	}
+	f := ast.CreateFact(goal.Head, args...)
+	f.Pos = goal.Pos
+	return f
+}
+
+// isMetaArg reports whether an argument of a meta_predicate/1 spec is a goal, an integer from 0 to 9, `:` or `^`
+func isMetaArg(t ast.Term) bool {
+	switch v := t.(type) {
+	case *ast.NumericLiteral:
+		n := v.Value()
+		return n == float64(int(n)) && n >= 0 && n <= 9
+	case *ast.Atom:
+		return v.String() == ":" || v.String() == "^"
+	}
+	return false
+}
+
+// isQualified reports whether the term is Module:Term
+func isQualified(t ast.Term) bool {
+	f, ok := t.(*ast.Fact)
+	return ok && f.Head == ":" && len(f.Args) == 2
+}
+
+// Module:Goal
+func (r *R) qualified(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	module := c.Dereference(args[0])
+	switch module.GetType() {
+	case ast.T_Variable:
+		send(ctx, out, instantiationError())
+		return
+	case ast.T_Atom:
+	default:
+		send(ctx, out, typeError("module", module))
+		return
+	}
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.ResolveTerm(inModule(ctx, module.String()), args[1], c, solutions)
+	for b := range solutions {
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+// meta_predicate(Spec)
+func (r *R) metaPredicate(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	specs := []*ast.Fact{}
+	for _, t := range ast.Conjuncts(c.Ground(args[0])) {
+		switch t.GetType() {
+		case ast.T_Variable:
+			send(ctx, out, instantiationError())
+			return
+		case ast.T_Fact:
+			specs = append(specs, t.(*ast.Fact))
+		default:
+			send(ctx, out, typeError("compound", t))
+			return
+		}
+	}
+	if modules, ok := r.i.(indexer.Modules); ok {
+		for _, spec := range specs {
+			modules.SetMetaPredicate(contextModule(ctx), spec)
+		}
+	}
+	send(ctx, out, c)
+}
@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * Predicate is a deterministic builtin, it has at most one solution.
+ * It returns the new bindings and true if it succeeds or false if it fails.
+ * A non nil error is raised as an exception, see errorBindings.
+ */
+type Predicate func(args []ast.Term, b *Bindings) (*Bindings, bool, error)
+
+// Iterator returns the next solution each time it is called, ok is false once there are no more solutions
+type Iterator func() (b *Bindings, ok bool, err error)
+
+// NondetPredicate is a builtin that can have any number of solutions, it returns an iterator over them
+type NondetPredicate func(args []ast.Term, b *Bindings) Iterator
+
+// Exception can be returned from a Predicate or Iterator to throw Term
+type Exception struct {
+	Term ast.Term
+}
+
+func (e *Exception) Error() string {
+	return ast.WriteTerm(e.Term, ast.WriteOptions{Quoted: true})
+}
+
+// errorBindings converts an error from a builtin into an exception, errors other than Exception become `error(system_error(Message), _)`
+func errorBindings(err error) *Bindings {
+	if e, ok := err.(*Exception); ok {
+		return Throw(e.Term)
+	}
+	return isoError(ast.CreateFact("system_error", ast.CreateStringLiteral(err.Error())))
+}
+
+// nativePredicate writes every solution to out, the caller takes care of closing the channel
+type nativePredicate func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings)
+
+// nativePredicates is a group of related builtins keyed by their signature (i.e. `length/2`)
+type nativePredicates map[string]nativePredicate
+
+func signature(name string, arity int) string {
+	return fmt.Sprintf("%s/%d", name, arity)
+}
+
+// Register adds a deterministic builtin, replacing anything already registered as name/arity
+func (r *R) Register(name string, arity int, p Predicate) {
+	r.natives[signature(name, arity)] = func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		b, ok, err := p(args, c)
+		if err != nil {
+			send(ctx, out, errorBindings(err))
+		} else if ok {
+			send(ctx, out, b)
+		}
+	}
+}
+
+/**
+ * RegisterNondet adds a builtin with any number of solutions, replacing anything already registered as name/arity.
+ * The iterator stops being called once the query no longer needs more solutions.
+ */
+func (r *R) RegisterNondet(name string, arity int, p NondetPredicate) {
+	r.natives[signature(name, arity)] = func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		next := p(args, c)
+		for {
+			b, ok, err := next()
+			if err != nil {
+				send(ctx, out, errorBindings(err))
+				return
+			}
+			if !ok || !send(ctx, out, b) {
+				return
+			}
+		}
+	}
+}
+
+// Registered returns true if there is a builtin registered as name/arity
+func (r *R) Registered(name string, arity int) bool {
+	_, ok := r.natives[signature(name, arity)]
+	return ok
+}
+
+func (r *R) registerNatives(groups ...nativePredicates) {
+	for _, g := range groups {
+		for sig, p := range g {
+			r.natives[sig] = p
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestRegister(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	r := resolver.New(indexer.NewDefault())
+	r.Register("double", 2, func(args []ast.Term, b *resolver.Bindings) (*resolver.Bindings, bool, error) {
+		n, ok := b.Dereference(args[0]).(*ast.NumericLiteral)
+		if !ok {
+			return nil, false, &resolver.Exception{Term: a("not_a_number")}
+		}
+		out := b.Clone()
+		out.Bind(args[1].String(), ast.CreateNumericLiteral(n.Value()*2))
+		return out, true, nil
+	})
+	r.Register("broken", 0, func(args []ast.Term, b *resolver.Bindings) (*resolver.Bindings, bool, error) {
+		return nil, false, errors.New("broken")
+	})
+
+	expectOne(t, "deterministic", solve(r, f("double", num(2), v("X"))), map[string]string{"X": "4.000000"})
+
+	results := solve(r, f("double", a("two"), v("X")))
+	if len(results) != 1 || !results[0].IsException() || results[0].Exception.String() != "not_a_number" {
+		t.Errorf("expected not_a_number to be thrown, got %v", results)
+	}
+	expectError(t, "plain errors", solve(r, f("broken")), "system_error(broken)")
+
+	if !r.Registered("double", 2) || r.Registered("double", 1) {
+		t.Errorf("double should only be registered as double/2")
+	}
+}
+
+func TestRegisterNondet(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+
+	r := resolver.New(indexer.NewDefault())
+	calls := 0
+	r.RegisterNondet("nat", 1, func(args []ast.Term, b *resolver.Bindings) resolver.Iterator {
+		n := 0
+		return func() (*resolver.Bindings, bool, error) {
+			calls++
+			out := b.Clone()
+			out.Bind(args[0].String(), ast.CreateNumericLiteral(float64(n)))
+			n++
+			return out, true, nil
+		}
+	})
+	r.RegisterNondet("upto", 2, func(args []ast.Term, b *resolver.Bindings) resolver.Iterator {
+		max := b.Dereference(args[0]).(*ast.NumericLiteral).Value()
+		n := 0.0
+		return func() (*resolver.Bindings, bool, error) {
+			if n > max {
+				return nil, false, nil
+			}
+			out := b.Clone()
+			out.Bind(args[1].String(), ast.CreateNumericLiteral(n))
+			n++
+			return out, true, nil
+		}
+	})
+
+	if results := solve(r, f("upto", num(3), v("X"))); len(results) != 4 {
+		t.Errorf("expected 4 solutions, got %v", results)
+	}
+
+	// the iterator stops being called once no more solutions are needed
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *resolver.Bindings)
+	go r.ResolveStatementList(ctx, []ast.Statement{&ast.Query{f("nat", v("X")), f("=", v("X"), num(5))}}, resolver.EmptyBindings(), out)
+	if b := <-out; b.Ground(v("X")).String() != "5.000000" {
+		t.Errorf("expected X = 5, got %s", b)
+	}
+	cancel()
+	for range out {
+	}
+	waitForGoroutines(t, "cancelled iterator", before)
+	if calls > 10 {
+		t.Errorf("iterator was called %d times after the query was cancelled", calls)
+	}
+	if results := solve(r, f("findall", v("X"), f("upto", num(2), v("X")), v("L"))); len(results) != 1 || ast.WriteTerm(results[0].Ground(v("L")), ast.WriteOptions{}) != "[0,1,2]" {
+		t.Errorf("unexpected findall results %v", results)
+	}
+}
+
+// TestUnsupportedControlConstructs checks that the control constructs without a resolution strategy raise an error rather than failing
+func TestUnsupportedControlConstructs(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	r := resolver.New(indexer.NewDefault())
+
+	disjunction := f(";", f("=", v("X"), num(1)), f("=", v("X"), num(2)))
+	expectError(t, "findall over ;/2", solve(r, f("findall", v("X"), disjunction, v("L"))), "existence_error(procedure,/(;,2.000000))")
+	expectError(t, "if-then", solve(r, f("->", f("true"), f("fail"))), "existence_error(procedure,/(->,2.000000))")
+	expectError(t, "soft cut", solve(r, f("*->", f("true"), f("fail"))), "existence_error(procedure,/(*->,2.000000))")
+	expectError(t, "cut", solve(r, f("!")), "existence_error(procedure,/(!,0.000000))")
+}
@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+/**
+ * Operators provides the builtins for changing the operators used to read terms.
+ *   op(Priority, Type, Name) - Name can be an atom or a list of atoms, a Priority of 0 removes the operator
+ *   current_op(Priority, Type, Name)
+ * The operators are shared by everything the resolver reads, i.e. consulted clauses, queries and read_term.
+ */
+type Operators struct {
+	ops *syntax.Ops
+}
+
+func newOps(r *R) nativePredicates {
+	o := &Operators{ops: r.ops}
+	return nativePredicates{
+		"op/3":         o.op,
+		"current_op/3": o.currentOp,
+		"is/2":         is,
+	}
+}
+
+// Ops returns the operator table used to read terms, which op/3 changes
+func (r *R) Ops() *syntax.Ops {
+	return r.ops
+}
+
+func (o *Operators) op(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	priority, typ, names := c.Ground(args[0]), c.Ground(args[1]), c.Ground(args[2])
+	if priority.GetType() == ast.T_Variable || typ.GetType() == ast.T_Variable || names.GetType() == ast.T_Variable {
+		send(ctx, out, instantiationError())
+		return
+	}
+	n, ok := priority.(*ast.NumericLiteral)
+	if !ok || n.Value() != float64(int(n.Value())) {
+		send(ctx, out, typeError("integer", priority))
+		return
+	}
+	if n.Value() < 0 || n.Value() > 1200 {
+		send(ctx, out, domainError("operator_priority", priority))
+		return
+	}
+	if typ.GetType() != ast.T_Atom {
+		send(ctx, out, typeError("atom", typ))
+		return
+	}
+	if !syntax.IsOpType(typ.String()) {
+		send(ctx, out, domainError("operator_specifier", typ))
+		return
+	}
+
+	list := []ast.Term{names}
+	if ast.IsProperList(names) {
+		list, _ = ast.ListToSlice(names)
+	}
+	for _, name := range list {
+		if name.GetType() == ast.T_Variable {
+			send(ctx, out, instantiationError())
+			return
+		}
+		if name.GetType() != ast.T_Atom {
+			send(ctx, out, typeError("atom", name))
+			return
+		}
+		if name.String() == "," || name.String() == "|" {
+			send(ctx, out, permissionError("modify", "operator", name))
+			return
+		}
+	}
+	for _, name := range list {
+		o.ops.Add(int(n.Value()), typ.String(), name.String())
+	}
+	send(ctx, out, c)
+}
+
+func (o *Operators) currentOp(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	for _, op := range o.ops.All() {
+		b := unifyTerms(args[0], ast.CreateNumericLiteral(float64(op.Priority)), c)
+		if b != nil {
+			b = unifyTerms(args[1], ast.CreateAtom(op.Type), b)
+		}
+		if b != nil {
+			b = unifyTerms(args[2], ast.CreateAtom(op.Name), b)
+		}
+		if b != nil && !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+/**
+ * is(Result, Expr)
+ * Clauses written `X is Expr` are read as math assignments when they can be, this covers the rest,
+ * i.e. expressions using min/2 or a Result which is already bound.
+ */
+func is(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	v, err := evalArithmetic(args[1], c)
+	if err != nil {
+		return
+	}
+	unifyAndSend(ctx, args[0], ast.CreateNumericLiteral(v), c, out)
+}
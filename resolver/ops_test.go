@@ -0,0 +1,118 @@
+package resolver_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestOp(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	r := resolver.New(indexer.NewDefault())
+
+	expectOne(t, "define operators", solve(r,
+		f("op", num(700), a("xfx"), a("likes")),
+		f("op", num(200), a("xfy"), ast.CreateList(a("=>"), a("&"))),
+		f("current_op", v("P"), v("T"), a("likes")),
+		f("current_op", num(200), a("xfy"), v("N")),
+		f("=", v("N"), a("=>")),
+	), map[string]string{"P": "700.000000", "T": "xfx"})
+	if op, ok := r.Ops().Infix("&"); !ok || op.Priority != 200 {
+		t.Errorf("expected & to be an operator, got %v", op)
+	}
+
+	expectOne(t, "remove an operator", solve(r,
+		f("op", num(0), a("xfx"), a("likes")),
+		f("findall", v("P"), f("current_op", v("P"), v("_"), a("likes")), v("L")),
+	), map[string]string{"L": "L[]"})
+
+	// the default operators are all there
+	expectOne(t, "default operators", solve(r,
+		f("current_op", v("P"), a("yfx"), a("*")),
+		f("current_op", v("Q"), a("fy"), a("-")),
+	), map[string]string{"P": "400.000000", "Q": "200.000000"})
+
+	expectError(t, "unbound priority", solve(r, f("op", v("P"), a("xfx"), a("x"))), "instantiation_error")
+	expectError(t, "priority", solve(r, f("op", num(1201), a("xfx"), a("x"))), "domain_error(operator_priority,1201.000000)")
+	expectError(t, "priority type", solve(r, f("op", a("high"), a("xfx"), a("x"))), "type_error(integer,high)")
+	expectError(t, "specifier", solve(r, f("op", num(700), a("xyz"), a("x"))), "domain_error(operator_specifier,xyz)")
+	expectError(t, "name", solve(r, f("op", num(700), a("xfx"), num(1))), "type_error(atom,1.000000)")
+	expectError(t, "comma", solve(r, f("op", num(700), a("xfx"), a(","))), "permission_error(modify,operator,,)")
+}
+
+func TestReadTermWithOperators(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	dir, err := ioutil.TempDir("", "gopl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := s(filepath.Join(dir, "rules.pl"))
+	if err := ioutil.WriteFile(path.String(), []byte("a => b.\nx(1 + 2 * 3).\nY is max(1, 2).\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolver.New(indexer.NewDefault())
+	expectError(t, "unknown operator", solve(r,
+		f("open", path, a("read"), v("S")),
+		f("read", v("S"), v("T")),
+	), "syntax_error(operator expected)")
+
+	expectOne(t, "read with operators", solve(r,
+		f("op", num(1050), a("xfx"), a("=>")),
+		f("open", path, a("read"), v("S")),
+		f("read", v("S"), f("=>", v("L"), v("R"))),
+		f("read", v("S"), f("x", f("+", v("X"), v("Y")))),
+		f("read", v("S"), v("T")),
+		f("close", v("S")),
+		f("call", v("T")),
+	), map[string]string{"L": "a", "R": "b", "X": "1.000000", "Y": "*(2.000000,3.000000)", "T": "is(2.000000,max(1.000000,2.000000))"})
+}
+
+// parseQuery reads a goal with the resolver's operators, failing the test if it cant
+func parseQuery(t *testing.T, r *resolver.R, goal string) []ast.Statement {
+	q, err := r.Ops().ParseQuery(goal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *q
+}
+
+func TestUserOperators(t *testing.T) {
+	a := ast.CreateAtom
+	f := ast.CreateFact
+
+	i := indexer.NewDefault()
+	r := resolver.New(i)
+	statements, err := r.Ops().Parse([]rune(":- op(700, xfx, likes).\nmary likes wine.\njohn likes X :- mary likes X.\n"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statements[1:] {
+		i.IndexStatement(s)
+	}
+	expectOne(t, "john likes W", solve(r, parseQuery(t, r, "john likes W")...), map[string]string{"W": "wine"})
+
+	// operators defined by op/3 are used by the clauses and queries read after it
+	if _, err := r.Ops().ParseQuery("X = (a => b)"); err == nil {
+		t.Errorf("expected => not to be an operator yet")
+	}
+	expectOne(t, "define =>", solve(r, f("op", num(1050), a("xfx"), a("=>"))), nil)
+	statements, err = r.Ops().Parse([]rune("a => b."), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.IndexStatement(statements[0])
+	expectOne(t, "H => B", solve(r, parseQuery(t, r, "H => B")...), map[string]string{"H": "a", "B": "b"})
+}
@@ -0,0 +1,153 @@
+package resolver
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"time"
+)
+
+/**
+ * WritePprof writes the call stacks seen by the profile in the gzipped protocol buffer format read by
+ * `go tool pprof`. Each predicate is shown as a function named by its signature and every sample holds the
+ * calls made with that stack along with the CPU and wall time spent in them, not counting the goals they called.
+ */
+func (p *Profile) WritePprof(w io.Writer) error {
+	p.lock.Lock()
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := make([]profileSample, len(keys))
+	for i, key := range keys {
+		samples[i] = *p.samples[key]
+	}
+	start := p.start
+	p.lock.Unlock()
+
+	b := &pprofBuilder{strings: map[string]int64{"": 0}, stringTable: []string{""}, functions: map[string]uint64{}}
+	// Profile.sample_type
+	for _, t := range [][2]string{{"calls", "count"}, {"cpu", "nanoseconds"}, {"wall", "nanoseconds"}} {
+		b.message(1, b.valueType(t[0], t[1]))
+	}
+	// Profile.sample
+	for _, s := range samples {
+		sample := &protoBuffer{}
+		locations := make([]uint64, len(s.stack))
+		for i, signature := range s.stack {
+			locations[i] = b.function(signature)
+		}
+		sample.packed(1, locations)
+		sample.packed(2, []uint64{uint64(s.calls), uint64(s.cpu), uint64(s.wall)})
+		b.message(2, sample)
+	}
+	// Profile.location, each function gets a location with the same id
+	for id := uint64(1); id <= uint64(len(b.functions)); id++ {
+		line := &protoBuffer{}
+		line.varint(1, id)
+		location := &protoBuffer{}
+		location.varint(1, id)
+		location.message(4, line)
+		b.message(4, location)
+	}
+	// Profile.function
+	names := make([]string, len(b.functions))
+	for name, id := range b.functions {
+		names[id-1] = name
+	}
+	for i, name := range names {
+		function := &protoBuffer{}
+		function.varint(1, uint64(i+1))
+		function.varint(2, uint64(b.str(name)))
+		function.varint(3, uint64(b.str(name)))
+		b.message(5, function)
+	}
+	// Profile.time_nanos, duration_nanos, period_type, period and default_sample_type
+	b.varint(9, uint64(start.UnixNano()))
+	b.varint(10, uint64(time.Since(start)))
+	b.message(11, b.valueType("cpu", "nanoseconds"))
+	b.varint(12, 1)
+	defaultType := b.str("cpu")
+	// Profile.string_table has to be written once every string has been added
+	for _, s := range b.stringTable {
+		b.bytes(6, []byte(s))
+	}
+	b.varint(14, uint64(defaultType))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.buf); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// pprofBuilder is the top level Profile message along with its string table and functions
+type pprofBuilder struct {
+	protoBuffer
+	strings     map[string]int64
+	stringTable []string
+	functions   map[string]uint64
+}
+
+func (b *pprofBuilder) str(s string) int64 {
+	if i, ok := b.strings[s]; ok {
+		return i
+	}
+	i := int64(len(b.stringTable))
+	b.strings[s] = i
+	b.stringTable = append(b.stringTable, s)
+	return i
+}
+
+func (b *pprofBuilder) function(signature string) uint64 {
+	if id, ok := b.functions[signature]; ok {
+		return id
+	}
+	id := uint64(len(b.functions) + 1)
+	b.functions[signature] = id
+	return id
+}
+
+func (b *pprofBuilder) valueType(kind string, unit string) *protoBuffer {
+	t := &protoBuffer{}
+	t.varint(1, uint64(b.str(kind)))
+	t.varint(2, uint64(b.str(unit)))
+	return t
+}
+
+// protoBuffer encodes the few protocol buffer field types used by the pprof format
+type protoBuffer struct {
+	buf []byte
+}
+
+func (p *protoBuffer) uvarint(x uint64) {
+	for x >= 0x80 {
+		p.buf = append(p.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	p.buf = append(p.buf, byte(x))
+}
+
+func (p *protoBuffer) varint(field int, x uint64) {
+	p.uvarint(uint64(field) << 3)
+	p.uvarint(x)
+}
+
+func (p *protoBuffer) bytes(field int, b []byte) {
+	p.uvarint(uint64(field)<<3 | 2)
+	p.uvarint(uint64(len(b)))
+	p.buf = append(p.buf, b...)
+}
+
+func (p *protoBuffer) packed(field int, xs []uint64) {
+	inner := &protoBuffer{}
+	for _, x := range xs {
+		inner.uvarint(x)
+	}
+	p.bytes(field, inner.buf)
+}
+
+func (p *protoBuffer) message(field int, m *protoBuffer) {
+	p.bytes(field, m.buf)
+}
@@ -0,0 +1,361 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * PredicateStats is what a Profile saw of one predicate.
+ * Calls, Redos, Exits and Fails count the ports of its goals the same way the tracer does, so a goal that has
+ * already exited ends without counting as a failure.
+ * Inferences, Wall and CPU include everything done by the goals it called, recursive calls are only counted once.
+ */
+type PredicateStats struct {
+	Signature string
+	Calls     int64
+	Redos     int64
+	Exits     int64
+	Fails     int64
+	// Inferences is the number of goals tried while proving the predicate's goals, including the goals themselves
+	Inferences int64
+	// Wall is the time from each call until it had no more solutions, including time the caller spent between solutions
+	Wall time.Duration
+	/**
+	 * CPU is the processor time used while searching for solutions. Goals run concurrently so the CPU time the process
+	 * used between two events (a goal being called, handing a solution to its caller or finishing) is shared
+	 * equally between the goals that were searching without waiting on a goal they called.
+	 */
+	CPU time.Duration
+}
+
+/**
+ * Profile collects PredicateStats while goals are resolved, along with the call stacks that were used so it can be
+ * written out for `go tool pprof`. See WithProfile and profile/1.
+ */
+type Profile struct {
+	lock    sync.Mutex
+	start   time.Time
+	preds   map[string]*PredicateStats
+	samples map[string]*profileSample
+
+	// cpu is the CPU time the process had used at the last event, see account
+	cpu time.Duration
+	// searching are the calls searching for a solution which arent waiting on any of the goals they called
+	searching map[*profileCall]bool
+}
+
+// profileSample is the time spent in one call stack, not counting the time spent in the goals it called
+type profileSample struct {
+	stack []string
+	calls int64
+	cpu   time.Duration
+	wall  time.Duration
+}
+
+// profileCall is a goal being profiled, the goals it calls have it as their parent
+type profileCall struct {
+	signature string
+	parent    *profileCall
+	// recursive is set when the same predicate is already being called further up the stack
+	recursive bool
+	// onStack holds the signatures of the calls on the stack up to and including this one, it is shared and never changed
+	onStack map[string]bool
+
+	// these are updated by the goals it calls as they finish
+	inferences int64
+	childWall  int64
+
+	// these are only used with the profile's lock held
+	active         bool
+	activeChildren int
+	cpu            time.Duration
+	childCPU       time.Duration
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		start:     time.Now(),
+		preds:     make(map[string]*PredicateStats),
+		samples:   make(map[string]*profileSample),
+		cpu:       processCPU(),
+		searching: make(map[*profileCall]bool),
+	}
+}
+
+// WithProfile collects statistics about every query into p
+func WithProfile(p *Profile) Option {
+	return func(r *R) {
+		r.profile = p
+	}
+}
+
+func (p *Profile) stats(signature string) *PredicateStats {
+	s, ok := p.preds[signature]
+	if !ok {
+		s = &PredicateStats{Signature: signature}
+		p.preds[signature] = s
+	}
+	return s
+}
+
+func (p *Profile) enter(signature string, parent *profileCall) *profileCall {
+	call := &profileCall{signature: signature, parent: parent, inferences: 1}
+	if parent != nil && parent.onStack[signature] {
+		// a recursive call has the same predicates on its stack as its parent
+		call.recursive = true
+		call.onStack = parent.onStack
+	} else {
+		call.onStack = map[string]bool{signature: true}
+		if parent != nil {
+			for s := range parent.onStack {
+				call.onStack[s] = true
+			}
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stats(signature).Calls++
+	p.setActive(call, true)
+	return call
+}
+
+// account shares the CPU time the process used since the last event between the calls which were searching
+func (p *Profile) account() {
+	now := processCPU()
+	used := now - p.cpu
+	p.cpu = now
+	if used <= 0 || len(p.searching) == 0 {
+		return
+	}
+	share := used / time.Duration(len(p.searching))
+	for call := range p.searching {
+		call.cpu += share
+	}
+}
+
+/**
+ * setActive records whether the call is looking for a solution, it isnt while it waits for its caller to take one
+ * or once it has finished. Only the active calls without any active goals of their own are using the CPU.
+ */
+func (p *Profile) setActive(call *profileCall, active bool) {
+	if call.active == active {
+		return
+	}
+	p.account()
+	call.active = active
+	p.updateSearching(call)
+	if parent := call.parent; parent != nil {
+		if active {
+			parent.activeChildren++
+		} else {
+			parent.activeChildren--
+		}
+		p.updateSearching(parent)
+	}
+}
+
+func (p *Profile) updateSearching(call *profileCall) {
+	if call.active && call.activeChildren == 0 {
+		p.searching[call] = true
+	} else {
+		delete(p.searching, call)
+	}
+}
+
+// waiting records whether the call is blocked until its caller takes a solution, it carries on searching once it has
+func (p *Profile) waiting(call *profileCall, waiting bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.setActive(call, !waiting)
+}
+
+func (p *Profile) exit(call *profileCall, wall time.Duration, exits int64, failed bool) {
+	inferences := atomic.LoadInt64(&call.inferences)
+	if parent := call.parent; parent != nil {
+		atomic.AddInt64(&parent.inferences, inferences)
+		atomic.AddInt64(&parent.childWall, int64(wall))
+	}
+
+	stack := []string{}
+	for c := call; c != nil; c = c.parent {
+		stack = append(stack, c.signature)
+	}
+	key := strings.Join(stack, "\x00")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.setActive(call, false)
+	cpu := call.cpu + call.childCPU
+	if parent := call.parent; parent != nil {
+		parent.childCPU += cpu
+	}
+
+	s := p.stats(call.signature)
+	s.Exits += exits
+	if exits > 1 {
+		s.Redos += exits - 1
+	}
+	if failed {
+		s.Fails++
+	}
+	if !call.recursive {
+		s.Inferences += inferences
+		s.Wall += wall
+		s.CPU += cpu
+	}
+
+	sample, ok := p.samples[key]
+	if !ok {
+		sample = &profileSample{stack: stack}
+		p.samples[key] = sample
+	}
+	sample.calls++
+	sample.cpu += call.cpu
+	// goals run concurrently so the time spent in the goals a call made can add up to more than the call took
+	if self := wall - time.Duration(atomic.LoadInt64(&call.childWall)); self > 0 {
+		sample.wall += self
+	}
+}
+
+/**
+ * profileFact resolves the fact while recording it in the frame's profile.
+ * The goals it calls are resolved with this goal as their parent so the call stacks can be rebuilt.
+ */
+func (r *R) profileFact(ctx context.Context, fr *frame, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	defer close(out)
+	p := fr.profile
+	call := p.enter(f.Signature().String(), fr.call)
+	inner := fr.with(ctx)
+	inner.call = call
+
+	start := time.Now()
+	var exits int64
+	failed := false
+	defer func() {
+		p.exit(call, time.Since(start), exits, failed)
+	}()
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.debugFact(inner, f, c, solutions)
+	for b := range solutions {
+		if !b.IsException() {
+			exits++
+		}
+		p.waiting(call, true)
+		ok := send(ctx, out, b)
+		p.waiting(call, false)
+		if !ok || b.IsException() {
+			return
+		}
+	}
+	failed = exits == 0 && ctx.Err() == nil
+}
+
+// Stats returns the statistics for each predicate that was called, ordered by signature
+func (p *Profile) Stats() []PredicateStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ret := make([]PredicateStats, 0, len(p.preds))
+	for _, s := range p.preds {
+		ret = append(ret, *s)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Signature < ret[j].Signature
+	})
+	return ret
+}
+
+// ProfileColumns are the columns a profile table can be sorted by
+var ProfileColumns = []string{"predicate", "calls", "redos", "exits", "fails", "inferences", "wall", "cpu"}
+
+func profileKey(column string, s PredicateStats) int64 {
+	switch column {
+	case "calls":
+		return s.Calls
+	case "redos":
+		return s.Redos
+	case "exits":
+		return s.Exits
+	case "fails":
+		return s.Fails
+	case "inferences":
+		return s.Inferences
+	case "wall":
+		return int64(s.Wall)
+	}
+	return int64(s.CPU)
+}
+
+/**
+ * WriteTable writes the statistics as a table with one predicate per line.
+ * The rows are sorted by the given column (one of ProfileColumns), largest first apart from predicate which is
+ * sorted by name. Times are shown in milliseconds.
+ */
+func (p *Profile) WriteTable(w io.Writer, sortBy string) error {
+	known := false
+	for _, column := range ProfileColumns {
+		known = known || column == sortBy
+	}
+	if !known {
+		return fmt.Errorf("unknown profile column %q, expected one of %s", sortBy, strings.Join(ProfileColumns, ", "))
+	}
+
+	stats := p.Stats()
+	if sortBy != "predicate" {
+		sort.SliceStable(stats, func(i, j int) bool {
+			return profileKey(sortBy, stats[i]) > profileKey(sortBy, stats[j])
+		})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Predicate\tCalls\tRedos\tExits\tFails\tInferences\tWall (ms)\tCPU (ms)\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%.3f\t%.3f\t\n", s.Signature, s.Calls, s.Redos, s.Exits, s.Fails,
+			s.Inferences, s.Wall.Seconds()*1000, s.CPU.Seconds()*1000)
+	}
+	return tw.Flush()
+}
+
+/**
+ * newProfile provides profile/1.
+ * profile(Goal) runs Goal like once/1 while collecting a profile of it, the table is then written to the
+ * current output sorted by CPU time.
+ */
+func newProfile(r *R) nativePredicates {
+	return nativePredicates{
+		"profile/1": r.profileGoal,
+	}
+}
+
+func (r *R) profileGoal(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	ctx = r.startQuery(ctx)
+	f := frameFrom(ctx).with(ctx)
+	p := NewProfile()
+	f.profile = p
+	f.call = nil
+
+	b := r.solveOnce(f, args[0], c)
+
+	var table bytes.Buffer
+	_ = p.WriteTable(&table, "cpu")
+	s := r.streams.currentOutput()
+	if err := s.write(table.String()); err != nil {
+		send(ctx, out, ioError("write", s.handle()))
+		return
+	}
+	if b != nil {
+		send(ctx, out, b)
+	}
+}
@@ -0,0 +1,157 @@
+package resolver_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestProfile(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	p := resolver.NewProfile()
+	r := resolver.New(familyIndex(), resolver.WithProfile(p))
+	expectOne(t, "grandparent", solve(r, f("grandparent", a("tom"), v("Who"))), map[string]string{"Who": "ann"})
+	if results := solve(r, f("parent", a("ann"), v("X"))); len(results) != 0 {
+		t.Fatalf("expected no solutions, got %v", results)
+	}
+
+	stats := map[string]resolver.PredicateStats{}
+	for _, s := range p.Stats() {
+		stats[s.Signature] = s
+	}
+	gp, parent := stats["grandparent/2"], stats["parent/2"]
+	if gp.Calls != 1 || gp.Exits != 1 || gp.Fails != 0 || gp.Inferences != 3 {
+		t.Errorf("unexpected stats for grandparent/2: %+v", gp)
+	}
+	if parent.Calls != 3 || parent.Exits != 2 || parent.Fails != 1 || parent.Inferences != 3 {
+		t.Errorf("unexpected stats for parent/2: %+v", parent)
+	}
+	if gp.Wall <= 0 || gp.CPU < parent.CPU {
+		t.Errorf("unexpected times for grandparent/2: %+v", gp)
+	}
+
+	var table bytes.Buffer
+	if err := p.WriteTable(&table, "calls"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "Inferences") || !strings.HasPrefix(strings.TrimSpace(lines[1]), "parent/2") {
+		t.Errorf("unexpected table\n%s", table.String())
+	}
+	if err := p.WriteTable(&table, "size"); err == nil {
+		t.Errorf("expected an error sorting by an unknown column")
+	}
+
+	var pprof bytes.Buffer
+	if err := p.WritePprof(&pprof); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&pprof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"grandparent/2", "parent/2", "cpu", "nanoseconds"} {
+		if !bytes.Contains(raw, []byte(s)) {
+			t.Errorf("expected %q in the pprof string table", s)
+		}
+	}
+}
+
+func TestProfileRecursion(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	// len([], 0).
+	// len([_|T], N) :- len(T, M), N is M + 1.
+	i := indexer.NewDefault()
+	i.IndexStatement(f("len", ast.CreateList(), num(0)))
+	i.IndexStatement(ast.CreateRule(f("len", ast.CreatePartialList([]ast.Term{v("_")}, v("T")), v("N")),
+		f("len", v("T"), v("M")), f("is", v("N"), f("+", v("M"), num(1)))))
+
+	p := resolver.NewProfile()
+	r := resolver.New(i, resolver.WithProfile(p))
+	expectOne(t, "len", solve(r, f("len", ast.CreateList(a("a"), a("b"), a("c")), v("N"))), map[string]string{"N": "3.000000"})
+
+	for _, s := range p.Stats() {
+		// only the outermost call counts the inferences of the calls inside it
+		if s.Signature == "len/2" && (s.Calls != 4 || s.Exits != 4 || s.Inferences != 7) {
+			t.Errorf("unexpected stats for len/2: %+v", s)
+		}
+	}
+}
+
+func TestProfileGoal(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+
+	var out bytes.Buffer
+	r := resolver.New(familyIndex())
+	r.SetOutput(&out)
+
+	// profile/1 only finds the first solution
+	expectOne(t, "profile", solve(r, f("profile", f("parent", v("X"), v("Y")))), map[string]string{"X": "tom", "Y": "bob"})
+	if !strings.Contains(out.String(), "Predicate") || !strings.Contains(out.String(), "parent/2") {
+		t.Errorf("expected a profile table, got\n%s", out.String())
+	}
+
+	out.Reset()
+	if results := solve(r, f("profile", f("parent", v("X"), v("X")))); len(results) != 0 {
+		t.Errorf("expected no solutions, got %v", results)
+	}
+	if !strings.Contains(out.String(), "parent/2") {
+		t.Errorf("expected the table to be written when the goal fails, got\n%s", out.String())
+	}
+}
+
+// TestProfileCPU checks that the CPU column is processor time, a goal which sleeps uses far less of it than its wall time
+func TestProfileCPU(t *testing.T) {
+	f := ast.CreateFact
+
+	// work :- burn, nap.
+	i := indexer.NewDefault()
+	i.IndexStatement(ast.CreateRule(f("work"), f("burn"), f("nap")))
+
+	p := resolver.NewProfile()
+	r := resolver.New(i, resolver.WithProfile(p))
+	r.Register("burn", 0, func(args []ast.Term, c *resolver.Bindings) (*resolver.Bindings, bool, error) {
+		for start, n := time.Now(), 0; time.Since(start) < 50*time.Millisecond; n++ {
+			_ = n * n
+		}
+		return c, true, nil
+	})
+	r.Register("nap", 0, func(args []ast.Term, c *resolver.Bindings) (*resolver.Bindings, bool, error) {
+		time.Sleep(50 * time.Millisecond)
+		return c, true, nil
+	})
+	expectOne(t, "work", solve(r, f("work")), nil)
+
+	stats := map[string]resolver.PredicateStats{}
+	for _, s := range p.Stats() {
+		stats[s.Signature] = s
+	}
+	work, burn, nap := stats["work/0"], stats["burn/0"], stats["nap/0"]
+	if burn.CPU < 25*time.Millisecond {
+		t.Errorf("expected burn/0 to use most of its 50ms on the CPU, got %s", burn.CPU)
+	}
+	if nap.Wall < 50*time.Millisecond || nap.CPU > 20*time.Millisecond {
+		t.Errorf("expected nap/0 to take 50ms without using the CPU, got %s wall and %s cpu", nap.Wall, nap.CPU)
+	}
+	if work.CPU < burn.CPU+nap.CPU {
+		t.Errorf("expected work/0 to include the CPU time of the goals it called, got %s", work.CPU)
+	}
+}
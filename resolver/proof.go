@@ -0,0 +1,317 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+/**
+ * Proof explains why a goal holds, it is only recorded when the resolver is created WithExplain.
+ * Clause is the statement from the indexer that proved the goal (with the variable names from the source when the
+ * indexer knows them) and is nil for builtins. Bindings holds the values of the clause's variables
+ * and Children are the proofs of the goals in the clause's body, in order.
+ */
+type Proof struct {
+	Goal     ast.Term
+	Clause   ast.Statement
+	Bindings map[string]ast.Term
+	Children []*Proof
+}
+
+// proofList is an immutable list of the proofs found so far in a conjunction, newest first
+type proofList struct {
+	proof *Proof
+	next  *proofList
+}
+
+// WithExplain records a proof tree for every solution, see Bindings.Proofs
+func WithExplain() Option {
+	return func(r *R) {
+		r.explain = true
+	}
+}
+
+// Proofs returns the proof of each goal in the query that produced these bindings, in the order of the goals
+func (b *Bindings) Proofs() []*Proof {
+	return b.proofsSince(nil)
+}
+
+// proofsSince returns the proofs added to b after the bindings whose proofs start at since
+func (b *Bindings) proofsSince(since *proofList) []*Proof {
+	ret := []*Proof{}
+	for p := b.proofs; p != since; p = p.next {
+		if p == nil {
+			return []*Proof{}
+		}
+		ret = append(ret, p.proof)
+	}
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
+
+func (b *Bindings) addProof(p *Proof) {
+	b.proofs = &proofList{p, b.proofs}
+}
+
+/**
+ * proofTerm renames any variables left in a term by the resolver so they dont look like the indexer's names.
+ * Goals with no arguments are shown as atoms.
+ */
+func proofTerm(t ast.Term) ast.Term {
+	switch t.GetType() {
+	case ast.T_Variable:
+		if strings.HasPrefix(t.String(), "_sf") {
+			return ast.CreateVariable("_G" + strings.TrimPrefix(t.String(), "_sf"))
+		}
+	case ast.T_Fact:
+		f := t.(*ast.Fact)
+		if len(f.Args) == 0 && f.Head != "|" {
+			return ast.CreateAtom(f.Head)
+		}
+		args := make([]ast.Term, len(f.Args))
+		for i, a := range f.Args {
+			args[i] = proofTerm(a)
+		}
+		return ast.CreateFact(f.Head, args...)
+	}
+	return t
+}
+
+// sourceName returns the name an indexed variable had in the source
+func (r *R) sourceName(indexed string) string {
+	if namer, ok := r.i.(indexer.VariableNamer); ok {
+		if name, ok := namer.VariableName(indexed); ok {
+			return name
+		}
+	}
+	return indexed
+}
+
+// sourceClause rewrites an indexed clause with the variable names from the source
+func (r *R) sourceClause(s ast.Statement) ast.Statement {
+	names := make(map[string]string)
+	rename := func(vars []*ast.Variable) {
+		for _, v := range vars {
+			names[v.String()] = r.sourceName(v.String())
+		}
+	}
+
+	switch s.GetType() {
+	case ast.T_Fact:
+		f := s.(*ast.Fact)
+		rename(f.ExtractVariables())
+		sf, _ := f.Anonymize(0, "_G", &names)
+		return sf
+	case ast.T_Rule:
+		rule := s.(*ast.Rule)
+		rename(rule.Head.ExtractVariables())
+		for _, g := range *rule.Body {
+			switch b := g.(type) {
+			case *ast.Fact:
+				rename(b.ExtractVariables())
+			case *ast.MathAssignment:
+				rename(b.ExtractVariables())
+			}
+		}
+		head, _ := rule.Head.Anonymize(0, "_G", &names)
+		body := ast.Query{}
+		for _, g := range *rule.Body {
+			switch b := g.(type) {
+			case *ast.Fact:
+				af, _ := b.Anonymize(0, "_G", &names)
+				body = append(body, af)
+			case *ast.MathAssignment:
+				am, _ := b.Anonymize(0, "_G", &names)
+				body = append(body, am)
+			}
+		}
+		return &ast.Rule{Head: head, Body: &body}
+	}
+	return s
+}
+
+// ruleProof builds the proof of a goal solved by a rule, mappings are the renamed rule's variables
+func (r *R) ruleProof(goal *ast.Fact, rule *ast.Rule, mappings map[string]string, db *Bindings) *Proof {
+	p := &Proof{
+		Goal:     proofTerm(db.Ground(goal)),
+		Clause:   r.sourceClause(rule),
+		Bindings: make(map[string]ast.Term),
+		Children: db.Proofs(),
+	}
+	for indexed, renamed := range mappings {
+		p.Bindings[r.sourceName(indexed)] = proofTerm(db.Ground(ast.CreateVariable(renamed)))
+	}
+	return p
+}
+
+// explainNative runs a builtin, adding a proof for it to each solution along with any proofs found by goals it called
+func (r *R) explainNative(ctx context.Context, p nativePredicate, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	solutions := make(chan *Bindings, paralellism)
+	go func() {
+		defer close(solutions)
+		p(ctx, f.Args, c, solutions)
+	}()
+	for b := range solutions {
+		if !b.IsException() {
+			children := b.proofsSince(c.proofs)
+			proved := b.Clone()
+			proved.proofs = c.proofs
+			proved.addProof(&Proof{Goal: proofTerm(b.Ground(f)), Children: children})
+			b = proved
+		}
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+}
+
+/**
+ * WriteProofText writes each proof as an indented tree, one goal per line followed by how it was proved:
+ *
+ *	grandparent(tom,ann)  [grandparent(X,Z) :- parent(X,Y),parent(Y,Z) | X = tom, Y = bob, Z = ann]
+ *	  parent(tom,bob)  [parent(tom,bob)]
+ *	  parent(bob,ann)  [parent(bob,ann)]
+ */
+func WriteProofText(w io.Writer, proofs []*Proof) error {
+	for _, p := range proofs {
+		if err := writeProofText(w, p, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeProofText(w io.Writer, p *Proof, depth int) error {
+	if _, err := fmt.Fprintf(w, "%s%s  [%s]\n", strings.Repeat("  ", depth), termText(p.Goal), p.reason()); err != nil {
+		return err
+	}
+	for _, child := range p.Children {
+		if err := writeProofText(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reason describes the clause and bindings that proved the goal
+func (p *Proof) reason() string {
+	if p.Clause == nil {
+		return "builtin"
+	}
+	bindings := p.bindingsText()
+	if len(bindings) == 0 {
+		return clauseText(p.Clause)
+	}
+	return clauseText(p.Clause) + " | " + strings.Join(bindings, ", ")
+}
+
+func (p *Proof) bindingsText() []string {
+	names := make([]string, 0, len(p.Bindings))
+	for name := range p.Bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make([]string, len(names))
+	for i, name := range names {
+		ret[i] = name + " = " + termText(p.Bindings[name])
+	}
+	return ret
+}
+
+func termText(t ast.Term) string {
+	return ast.WriteTerm(proofTerm(t), ast.WriteOptions{Quoted: true})
+}
+
+func clauseText(s ast.Statement) string {
+	switch c := s.(type) {
+	case *ast.Fact:
+		return termText(c)
+	case *ast.Rule:
+		body := make([]string, len(*c.Body))
+		for i, g := range *c.Body {
+			if f, ok := g.(*ast.Fact); ok {
+				body[i] = termText(f)
+			} else {
+				body[i] = g.String()
+			}
+		}
+		return termText(c.Head) + " :- " + strings.Join(body, ",")
+	}
+	return s.String()
+}
+
+// MarshalJSON writes the proof with its terms written as text
+func (p *Proof) MarshalJSON() ([]byte, error) {
+	children := p.Children
+	if children == nil {
+		children = []*Proof{}
+	}
+	m := map[string]interface{}{
+		"goal":     termText(p.Goal),
+		"children": children,
+	}
+	if p.Clause != nil {
+		bindings := make(map[string]string)
+		for name, value := range p.Bindings {
+			bindings[name] = termText(value)
+		}
+		m["clause"] = clauseText(p.Clause)
+		m["bindings"] = bindings
+	} else {
+		m["builtin"] = true
+	}
+	return json.Marshal(m)
+}
+
+// WriteProofJSON writes the proofs as an indented JSON array
+func WriteProofJSON(w io.Writer, proofs []*Proof) error {
+	b, err := json.MarshalIndent(proofs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// WriteProofDOT writes the proofs as a Graphviz digraph with an edge from each goal to the goals that proved it
+func WriteProofDOT(w io.Writer, proofs []*Proof) error {
+	d := &dotWriter{w: w}
+	d.printf("digraph proof {\n")
+	d.printf("  node [shape=box];\n")
+	for _, p := range proofs {
+		d.node(p)
+	}
+	d.printf("}\n")
+	return d.err
+}
+
+type dotWriter struct {
+	w    io.Writer
+	next int
+	err  error
+}
+
+func (d *dotWriter) printf(format string, args ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+func (d *dotWriter) node(p *Proof) int {
+	id := d.next
+	d.next++
+	d.printf("  n%d [label=%q];\n", id, termText(p.Goal)+"\n"+p.reason())
+	for _, child := range p.Children {
+		d.printf("  n%d -> n%d;\n", id, d.node(child))
+	}
+	return id
+}
@@ -0,0 +1,106 @@
+package resolver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// familyIndex contains
+//
+//	parent(tom, bob).
+//	parent(bob, ann).
+//	grandparent(X, Z) :- parent(X, Y), parent(Y, Z).
+func familyIndex() indexer.Indexer {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+	i := indexer.NewDefault()
+	i.IndexStatement(f("parent", a("tom"), a("bob")))
+	i.IndexStatement(f("parent", a("bob"), a("ann")))
+	i.IndexStatement(ast.CreateRule(f("grandparent", v("X"), v("Z")), f("parent", v("X"), v("Y")), f("parent", v("Y"), v("Z"))))
+	return i
+}
+
+func TestExplain(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	r := resolver.New(familyIndex(), resolver.WithExplain())
+	results := solve(r, f("grandparent", a("tom"), v("Who")), f("true"))
+	expectOne(t, "grandparent", results, map[string]string{"Who": "ann"})
+
+	var text bytes.Buffer
+	if err := resolver.WriteProofText(&text, results[0].Proofs()); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"grandparent(tom,ann)  [grandparent(X,Z) :- parent(X,Y),parent(Y,Z) | X = tom, Y = bob, Z = ann]",
+		"  parent(tom,bob)  [parent(tom,bob)]",
+		"  parent(bob,ann)  [parent(bob,ann)]",
+		"true  [builtin]",
+		"",
+	}, "\n")
+	if text.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text.String())
+	}
+
+	var js bytes.Buffer
+	if err := resolver.WriteProofJSON(&js, results[0].Proofs()); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0]["goal"] != "grandparent(tom,ann)" || decoded[1]["builtin"] != true {
+		t.Errorf("unexpected JSON %s", js.String())
+	}
+	if b := decoded[0]["bindings"].(map[string]interface{}); b["Y"] != "bob" {
+		t.Errorf("expected Y = bob in %s", js.String())
+	}
+	if children := decoded[0]["children"].([]interface{}); len(children) != 2 {
+		t.Errorf("expected 2 children in %s", js.String())
+	}
+
+	var dot bytes.Buffer
+	if err := resolver.WriteProofDOT(&dot, results[0].Proofs()); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"digraph proof {", "n0 -> n1;", "n0 -> n2;", `n3 [label="true\nbuiltin"];`} {
+		if !strings.Contains(dot.String(), s) {
+			t.Errorf("expected %q in\n%s", s, dot.String())
+		}
+	}
+}
+
+func TestExplainBuiltins(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	// goals called by builtins are proved underneath them
+	r := resolver.New(familyIndex(), resolver.WithExplain())
+	results := solve(r, f("call", f("parent", a("tom")), v("X")))
+	expectOne(t, "call", results, map[string]string{"X": "bob"})
+	proofs := results[0].Proofs()
+	if len(proofs) != 1 || len(proofs[0].Children) != 1 || proofs[0].Clause != nil {
+		t.Fatalf("unexpected proofs %v", proofs)
+	}
+	if got := ast.WriteTerm(proofs[0].Children[0].Goal, ast.WriteOptions{}); got != "parent(tom,bob)" {
+		t.Errorf("expected parent(tom,bob) to be proved inside call/2, got %s", got)
+	}
+
+	// without the option nothing is recorded
+	r = resolver.New(familyIndex())
+	results = solve(r, f("grandparent", a("tom"), v("Who")))
+	if len(results) != 1 || len(results[0].Proofs()) != 0 {
+		t.Errorf("expected no proofs, got %v", results)
+	}
+}
@@ -1,12 +1,15 @@
 package resolver
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/kkoch986/gopl/ast"
 	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/syntax"
 )
 
 const paralellism = 0
@@ -19,14 +22,44 @@ var (
 	ErrInvalidFactor      = errors.New("MathExpr Factor found with no value assigned")
 )
 
+/**
+ * FactResolver lets a builtin decide for itself which facts it handles by writing true or false on the bool channel.
+ * FactResolvers are only tried for facts that dont have a builtin registered for their signature (see Register).
+ */
 type FactResolver interface {
-	Resolve(*ast.Fact, *Bindings, chan<- *Bindings, chan<- bool)
+	Resolve(context.Context, *ast.Fact, *Bindings, chan<- *Bindings, chan<- bool)
 }
 
 type R struct {
 	fr      []FactResolver
+	natives map[string]nativePredicate
 	i       indexer.Indexer
 	nextVar int
+	varLock sync.Mutex
+
+	// streams holds the open streams used by the I/O builtins
+	streams *streamTable
+
+	// limits are applied to every query resolved by ResolveStatementList
+	limits Limits
+
+	// debug holds the tracer state used by trace/0 and spy/1
+	debug *debugger
+
+	// explain records a proof tree for each solution, see WithExplain
+	explain bool
+
+	// profile collects statistics about every query when set, see WithProfile
+	profile *Profile
+
+	// coverage counts how many times each clause is used when set, see WithCoverage
+	coverage *Coverage
+
+	// ops are the operators used to read terms, see op/3
+	ops *syntax.Ops
+
+	// tables holds the answers of tabled predicates, see table/1
+	tables *tableStore
 }
 
 func (r *R) AddFactResolver(nr FactResolver) {
@@ -39,24 +72,57 @@ func (r *R) AddFactResolvers(rs []FactResolver) {
 	}
 }
 
-func New(i indexer.Indexer) *R {
+func New(i indexer.Indexer, opts ...Option) *R {
 	r := &R{
-		i: i,
+		i:       i,
+		natives: make(map[string]nativePredicate),
+		streams: newStreamTable(),
+		ops:     syntax.NewOps(),
+		tables:  newTableStore(),
 	}
-	r.AddFactResolvers([]FactResolver{
-		&Equals{},
-		&Writeln{},
-		&True{},
-		&Fail{},
-		&Assert{i},
-	})
+	r.debug = newDebugger(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.Register("=", 2, equals)
+	r.Register("true", 0, succeed)
+	r.Register("fail", 0, fail)
+	r.Register("writeln", 1, r.writeln)
+	r.Register("writeln", 2, r.writeln)
+	r.Register("assert", 1, assert(i))
+	r.Register("compare", 3, compare)
+	r.registerNatives(
+		nativePredicates{
+			"findall/3":       r.findall,
+			"findall/4":       r.findall,
+			"bagof/3":         r.bagof(false),
+			"setof/3":         r.bagof(true),
+			"aggregate_all/3": r.aggregateAll,
+		},
+		newCall(r),
+		newCompare(),
+		newTypeChecks(),
+		newLists(r),
+		newText(),
+		newWrite(r),
+		newExceptions(r),
+		newStreamIO(r),
+		newLimits(r),
+		newDebug(r),
+		newProfile(r),
+		newOps(r),
+		newPhrase(r),
+		newModules(r),
+		newTabling(r),
+	)
 	return r
 }
 
-func (r *R) ResolveStatementList(sl []ast.Statement, c *Bindings, out chan<- *Bindings) {
+func (r *R) ResolveStatementList(ctx context.Context, sl []ast.Statement, c *Bindings, out chan<- *Bindings) {
 	defer close(out)
+	ctx = r.startQuery(ctx)
 	if len(sl) == 0 {
-		out <- c
+		send(ctx, out, c)
 		return
 	}
 
@@ -64,24 +130,32 @@ func (r *R) ResolveStatementList(sl []ast.Statement, c *Bindings, out chan<- *Bi
 	headBindings := make(chan *Bindings, paralellism)
 	tail := sl[1:]
 
-	go r.ResolveStatement(sl[0], c, headBindings)
+	go r.ResolveStatement(ctx, sl[0], c, headBindings)
 	for hb := range headBindings {
+		// exceptions skip the rest of the list
+		if hb.IsException() {
+			send(ctx, out, hb)
+			return
+		}
+
 		// for each binding of the first element of the list, try to resolve the next
 		tailBindings := make(chan *Bindings, paralellism)
-		go r.ResolveStatementList(tail, hb, tailBindings)
+		go r.ResolveStatementList(ctx, tail, hb, tailBindings)
 		for ob := range tailBindings {
-			out <- ob
+			if !send(ctx, out, ob) || ob.IsException() {
+				return
+			}
 		}
 	}
 }
 
-func (r *R) ResolveStatement(s ast.Statement, c *Bindings, out chan<- *Bindings) {
+func (r *R) ResolveStatement(ctx context.Context, s ast.Statement, c *Bindings, out chan<- *Bindings) {
 	t := s.GetType()
 	log.Printf("[DEBUG][ResolveStatement] %s (%s)", s, t)
 
 	switch t {
 	case ast.T_Query:
-		go r.ResolveQuery(s.(*ast.Query), c, out)
+		go r.ResolveQuery(ctx, s.(*ast.Query), c, out)
 	case ast.T_Rule:
 		fallthrough
 	case ast.T_Fact:
@@ -92,13 +166,13 @@ func (r *R) ResolveStatement(s ast.Statement, c *Bindings, out chan<- *Bindings)
 	}
 }
 
-func (r *R) ResolveQuery(q *ast.Query, c *Bindings, out chan<- *Bindings) {
+func (r *R) ResolveQuery(ctx context.Context, q *ast.Query, c *Bindings, out chan<- *Bindings) {
 	defer close(out)
 	log.Printf("[DEBUG][ResolveQuery] %s", q)
 
 	// If there are no statements in the list, accept the current binding
 	if q.Empty() {
-		out <- c
+		send(ctx, out, c)
 		return
 	}
 
@@ -107,25 +181,33 @@ func (r *R) ResolveQuery(q *ast.Query, c *Bindings, out chan<- *Bindings) {
 	tail := q.Tail()
 	headType := q.Head().GetType()
 	if headType == ast.T_Fact {
-		go r.ResolveFact(q.Head().(*ast.Fact), c, headBindings)
+		go r.ResolveFact(ctx, q.Head().(*ast.Fact), c, headBindings)
 	} else if headType == ast.T_MathAssignment {
-		go r.ResolveMathAssignment(q.Head().(*ast.MathAssignment), c, headBindings)
+		go r.ResolveMathAssignment(ctx, q.Head().(*ast.MathAssignment), c, headBindings)
 	} else {
-		// should really never get here...
-		panic(fmt.Sprintf("Can't resolve query item (not a fact or math assignment): %s", headType))
+		// should really never get here, the reader and the raw format only put facts and assignments in a query
+		send(ctx, out, typeError("callable", q.Head()))
+		return
 	}
 
 	for hb := range headBindings {
+		if hb.IsException() {
+			send(ctx, out, hb)
+			return
+		}
+
 		// find all resolutions of the tail and run them back to out
 		tailBindings := make(chan *Bindings, paralellism)
-		go r.ResolveQuery(tail, hb, tailBindings)
+		go r.ResolveQuery(ctx, tail, hb, tailBindings)
 		for ob := range tailBindings {
-			out <- ob
+			if !send(ctx, out, ob) || ob.IsException() {
+				return
+			}
 		}
 	}
 }
 
-func (r *R) ResolveMathAssignment(ma *ast.MathAssignment, c *Bindings, out chan<- *Bindings) {
+func (r *R) ResolveMathAssignment(ctx context.Context, ma *ast.MathAssignment, c *Bindings, out chan<- *Bindings) {
 	defer close(out)
 	log.Printf("[DEBUG][ResolveMathAssignment] %s", ma)
 
@@ -150,7 +232,7 @@ func (r *R) ResolveMathAssignment(ma *ast.MathAssignment, c *Bindings, out chan<
 	// if there were no errors, bind the numeric value to the variable in the LHS
 	output := c.Clone()
 	output.Bind(ma.LHS.String(), ast.CreateNumericLiteral(val))
-	out <- output
+	send(ctx, out, output)
 }
 
 func (r *R) ResolveMathExpr(me *ast.MathExpr, c *Bindings) (float64, error) {
@@ -197,7 +279,7 @@ func (r *R) ResolveMult(m *ast.Mult, c *Bindings) (float64, error) {
 	// resolve the rhs
 	rhs, err := r.ResolveFactor(m.RHS, c)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 
 	switch op {
@@ -230,16 +312,52 @@ func (r *R) ResolveFactor(f *ast.Factor, c *Bindings) (float64, error) {
 	return 0, ErrInvalidFactor
 }
 
-func (r *R) ResolveFact(f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+func (r *R) ResolveFact(ctx context.Context, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	if fr := frameFrom(ctx); fr != nil && fr.profile != nil {
+		r.profileFact(ctx, fr, f, c, out)
+		return
+	}
+	r.debugFact(ctx, f, c, out)
+}
+
+// debugFact resolves the fact, reporting it to the tracer while debugging
+func (r *R) debugFact(ctx context.Context, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	if r.debug.active() {
+		r.traceFact(ctx, f, c, out)
+		return
+	}
+	r.resolveFact(ctx, f, c, out)
+}
+
+func (r *R) resolveFact(ctx context.Context, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
 	defer close(out)
 	groundedF := c.Ground(f)
 	log.Printf("[DEBUG][ResolveFact] %s (from %s)\n", groundedF, f)
 
+	if ex, ok := checkLimits(ctx, c); !ok {
+		if ex != nil {
+			send(ctx, out, ex)
+		}
+		return
+	}
+
+	// builtins are looked up by their signature
+	if p, ok := r.natives[f.Signature().String()]; ok {
+		if r.explain {
+			r.explainNative(ctx, p, f, c, out)
+		} else {
+			p(ctx, f.Args, c, out)
+		}
+		return
+	}
+
 	// loop over all the resolvers one at a time until one matches (indicated by writing true on `mChan`)
+	// mChan has room for the answer so a resolver which closes rChan first isnt left waiting to give it
 	rChan := make(chan *Bindings, paralellism)
-	mChan := make(chan bool, paralellism)
+	mChan := make(chan bool, 1)
+	thrown := false
 	for _, resolver := range r.fr {
-		go resolver.Resolve(f, c, rChan, mChan)
+		go resolver.Resolve(ctx, f, c, rChan, mChan)
 	ResultLoop:
 		for {
 			select {
@@ -247,34 +365,101 @@ func (r *R) ResolveFact(f *ast.Fact, c *Bindings, out chan<- *Bindings) {
 				if !ok {
 					return
 				}
-				out <- b
+				// anything sent after an exception is dropped, but the channels are still drained
+				// so that the resolver can finish the protocol
+				if !thrown {
+					send(ctx, out, b)
+				}
+				thrown = thrown || b.IsException()
 			case m := <-mChan:
 				if m {
 					return
 				}
 				break ResultLoop
+			case <-ctx.Done():
+				go drainFactResolver(rChan, mChan)
+				return
 			}
 		}
 	}
 
 	// If we didnt find a matching resolver, follow the default behavior
 	// Find all of the statements that match the signature
-	matching := r.i.StatementsForSignature(f.Signature())
+	module, matching := r.clauses(ctx, f)
+	groundedF = r.qualifyMetaArgs(ctx, module, groundedF.(*ast.Fact))
 	log.Printf("[DEBUG][ResolveFact][%s][%s] Matching statements: %v", groundedF, c.ShortString(), matching)
 
+	if spec, ok := r.tables.spec(module, f.Signature()); ok {
+		r.resolveTabled(ctx, spec, module, matching, groundedF.(*ast.Fact), c, out)
+		return
+	}
+	r.resolveClauses(ctx, module, matching, groundedF.(*ast.Fact), c, out)
+}
+
+// drainFactResolver throws away whatever a FactResolver sends once nobody is listening, until it says it is done
+func drainFactResolver(rChan <-chan *Bindings, mChan <-chan bool) {
+	for {
+		select {
+		case _, ok := <-rChan:
+			if !ok {
+				return
+			}
+		case <-mChan:
+			return
+		}
+	}
+}
+
+// resolveClauses resolves the goal against each of the clauses of its predicate, which are defined in module
+func (r *R) resolveClauses(ctx context.Context, module string, matching []ast.Statement, goal *ast.Fact, c *Bindings, out chan<- *Bindings) {
 	// attempt to unify the input fact with each of the matching statements
 	// return each one that does unify as a result binding
 	for _, s := range matching {
+		if ctx.Err() != nil {
+			return
+		}
 		t := s.GetType()
 		if t == ast.T_Fact {
-			newBinding := unifyFacts(s.(*ast.Fact), f, c)
-			if newBinding != nil {
-				log.Printf("[DEBUG][ResolveFact][%s][%s] Returning fact binding: %s", groundedF, c.ShortString(), newBinding.ShortString())
-				out <- newBinding
+			fact := s.(*ast.Fact)
+
+			// ground facts can be unified directly against the current bindings
+			if len(fact.ExtractVariables()) == 0 {
+				newBinding := unifyFacts(fact, goal, c)
+				if newBinding != nil {
+					r.coverage.hit(fact, fact.Pos)
+					if r.explain {
+						newBinding.addProof(&Proof{Goal: fact, Clause: r.sourceClause(fact)})
+					}
+					log.Printf("[DEBUG][ResolveFact][%s][%s] Returning fact binding: %s", goal, c.ShortString(), newBinding.ShortString())
+					if !send(ctx, out, newBinding) {
+						return
+					}
+				}
+				continue
+			}
+
+			// facts containing variables need a fresh copy of those variables for each use
+			// otherwise bindings made in one branch would leak into the next one.
+			// After that they are treated like a rule with an empty body.
+			af, mappings := r.renameFact(fact)
+			r.debug.remember(ctx, mappings)
+			initialBinding := unifyFacts(af, goal, EmptyBindings())
+			if initialBinding == nil {
+				continue
+			}
+			if outBinding := r.projectBindings(goal, initialBinding, c); outBinding != nil {
+				r.coverage.hit(fact, fact.Pos)
+				if r.explain {
+					outBinding.addProof(&Proof{Goal: proofTerm(initialBinding.Ground(af)), Clause: r.sourceClause(fact)})
+				}
+				log.Printf("[DEBUG][ResolveFact][%s][%s] Returning fact binding: %s", goal, c.ShortString(), outBinding.ShortString())
+				if !send(ctx, out, outBinding) {
+					return
+				}
 			}
 		} else if t == ast.T_Rule {
 			rule := s.(*ast.Rule)
-			log.Printf("[DEBUG][ResolveFact][%s][%s] Attempting to unify with: %s", groundedF, c.ShortString(), rule)
+			log.Printf("[DEBUG][ResolveFact][%s][%s] Attempting to unify with: %s", goal, c.ShortString(), rule)
 
 			// We are trying to unify a Fact (the query) and a Rule (the base)
 			// To unify a fact with a rule, follow this procedure:
@@ -282,49 +467,37 @@ func (r *R) ResolveFact(f *ast.Fact, c *Bindings, out chan<- *Bindings) {
 			//    2. create an initial "stack frame" by anonymizing the variables in the rule
 			//        then unifying that rules head to the fact we are resolvaing using clean bindings.
 			//    3. With that binding, resolve the rule body
-			//    4. For each resulting binding:
-			//       Ground each variable in the
-			//       TODO: document this part, find the variables bound that map to the original head
-			//              and try to unify those against the current binding.
-			// TODO: we need to get the mapped variables back somehow...
-			ar, ruleMappings, j := rule.Anonymize(r.nextVar, "_sf")
-			r.nextVar = r.nextVar + j
-			log.Printf("[DEBUG][ResolveFact][%s][%s] Anonymized rule: %v ( mappings: %v )", groundedF, c.ShortString(), ar, ruleMappings)
-			initialBinding := unifyFacts(ar.Head, groundedF.(*ast.Fact), EmptyBindings())
-			log.Printf("[DEBUG][ResolveFact][%s][%s] Initial Bindings: %v", groundedF, c.ShortString(), initialBinding.ShortString())
+			//    4. For each resulting binding, ground each of the variables in the fact we are
+			//       resolving and unify them against the current binding (see projectBindings).
+			ar, ruleMappings := r.renameRule(rule)
+			r.debug.remember(ctx, ruleMappings)
+			log.Printf("[DEBUG][ResolveFact][%s][%s] Anonymized rule: %v ( mappings: %v )", goal, c.ShortString(), ar, ruleMappings)
+			initialBinding := unifyFacts(ar.Head, goal, EmptyBindings())
+			log.Printf("[DEBUG][ResolveFact][%s][%s] Initial Bindings: %v", goal, c.ShortString(), initialBinding)
 
 			if initialBinding == nil {
-				log.Printf("[DEBUG][ResolveFact][%s][%s] Unable to unify with rule head", groundedF, c.ShortString())
+				log.Printf("[DEBUG][ResolveFact][%s][%s] Unable to unify with rule head", goal, c.ShortString())
 				continue
 			}
+			r.coverage.hit(rule, rule.Pos)
 
 			discoveredBindings := make(chan *Bindings, paralellism)
-			variablesToProve := initialBinding.Ground(groundedF).(*ast.Fact).ExtractVariables()
-			go r.ResolveStatementList([]ast.Statement{ar.Body}, initialBinding, discoveredBindings)
-			log.Printf("[DEBUG][ResolveFact][%s][%s] variables to prove: %v", groundedF, c.ShortString(), variablesToProve)
+			go r.ResolveStatementList(inModule(deeper(ctx, c), module), []ast.Statement{ar.Body}, initialBinding, discoveredBindings)
 			for db := range discoveredBindings {
-				log.Printf("[DEBUG][ResolveFact][%s][%s] Discovered binding: %s", groundedF, c.ShortString(), db.ShortString())
-
-				outBinding := c.Clone()
-				valid := true
-				for _, variable := range variablesToProve {
-					deref := db.Dereference(variable)
-					if deref == nil {
-						continue
-					}
-
-					derefType := deref.GetType()
-					if derefType != ast.T_Variable {
-						if !outBinding.Bind(variable.String(), deref) {
-							valid = false
-							break
-						}
-					}
+				if db.IsException() {
+					send(ctx, out, db)
+					return
 				}
+				log.Printf("[DEBUG][ResolveFact][%s][%s] Discovered binding: %s", goal, c.ShortString(), db.ShortString())
 
-				if valid {
-					out <- outBinding
-					log.Printf("[DEBUG][ResolveFact][%s][%s] Returning rule binding: %s", groundedF, c.ShortString(), db.ShortString())
+				if outBinding := r.projectBindings(goal, db, c); outBinding != nil {
+					if r.explain {
+						outBinding.addProof(r.ruleProof(ar.Head, rule, ruleMappings, db))
+					}
+					log.Printf("[DEBUG][ResolveFact][%s][%s] Returning rule binding: %s", goal, c.ShortString(), db.ShortString())
+					if !send(ctx, out, outBinding) {
+						return
+					}
 				}
 			}
 		} else {
@@ -332,3 +505,59 @@ func (r *R) ResolveFact(f *ast.Fact, c *Bindings, out chan<- *Bindings) {
 		}
 	}
 }
+
+/**
+ * projectBindings takes the bindings discovered while proving a clause (which live in their own "stack frame")
+ * and maps them back onto the callers bindings.
+ * Each variable in the grounded goal is fully grounded in the discovered bindings and unified against
+ * a copy of the callers bindings. If that unification fails, nil is returned.
+ */
+func (r *R) projectBindings(goal *ast.Fact, db *Bindings, c *Bindings) *Bindings {
+	outBinding := c.Clone()
+	for _, variable := range goal.ExtractVariables() {
+		value := db.Ground(db.Dereference(variable))
+		if value.GetType() == ast.T_Variable && value.String() == variable.String() {
+			continue
+		}
+
+		outBinding = unifyTerms(variable, value, outBinding)
+		if outBinding == nil {
+			return nil
+		}
+	}
+	return outBinding
+}
+
+// renameRule gives the rule a fresh set of variables so it can be used as a new stack frame
+func (r *R) renameRule(rule *ast.Rule) (*ast.Rule, map[string]string) {
+	r.varLock.Lock()
+	defer r.varLock.Unlock()
+	ar, mappings, used := rule.Anonymize(r.nextVar, "_sf")
+	r.nextVar = r.nextVar + used
+	return ar, mappings
+}
+
+// renameFact gives the fact a fresh set of variables
+func (r *R) renameFact(fact *ast.Fact) (*ast.Fact, map[string]string) {
+	r.varLock.Lock()
+	defer r.varLock.Unlock()
+	mappings := make(map[string]string)
+	af, used := fact.Anonymize(r.nextVar, "_sf", &mappings)
+	r.nextVar = r.nextVar + used
+	return af, mappings
+}
+
+// renameTerm gives any term a fresh set of variables, much like copy_term/2
+func (r *R) renameTerm(t ast.Term) ast.Term {
+	af, _ := r.renameFact(ast.CreateFact("", t))
+	return af.Args[0]
+}
+
+// freshVariable creates a new variable which is guaranteed not to clash with any other variable
+func (r *R) freshVariable() *ast.Variable {
+	r.varLock.Lock()
+	defer r.varLock.Unlock()
+	v := ast.CreateVariable(fmt.Sprintf("_sf%d", r.nextVar))
+	r.nextVar = r.nextVar + 1
+	return v
+}
@@ -1,6 +1,7 @@
 package resolver_test
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -28,7 +29,7 @@ func runTestCase(t *testing.T, v resolverTestCase) {
 	// create a resolver and resolve the input statement
 	r := resolver.New(i)
 	out := make(chan *resolver.Bindings, 1)
-	go r.ResolveStatementList([]ast.Statement{v.Input}, v.ExistingBindings, out)
+	go r.ResolveStatementList(context.Background(), []ast.Statement{v.Input}, v.ExistingBindings, out)
 
 	results := []*resolver.Bindings{}
 	for outputBinding := range out {
@@ -0,0 +1,218 @@
+package resolver
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+const (
+	streamUserInput  = 0
+	streamUserOutput = 1
+	streamUserError  = 2
+)
+
+/**
+ * stream is an open input or output stream.
+ * Prolog code never sees the stream itself, only an opaque handle (`'$stream'(Id)`) or its alias.
+ */
+type stream struct {
+	id    int
+	alias string
+	// mode is one of read, write or append
+	mode   string
+	reader *bufio.Reader
+	writer io.Writer
+	closer io.Closer
+
+	// eofAction controls what happens when reading past the end of the stream (error, eof_code or reset)
+	eofAction string
+	pastEOF   bool
+}
+
+// handle returns the term used to refer to the stream from prolog code
+func (s *stream) handle() ast.Term {
+	return ast.CreateFact("$stream", ast.CreateNumericLiteral(float64(s.id)))
+}
+
+func (s *stream) isInput() bool {
+	return s.mode == "read"
+}
+
+func (s *stream) write(text string) error {
+	_, err := io.WriteString(s.writer, text)
+	return err
+}
+
+/**
+ * streamTable tracks all of the open streams along with the current input and output.
+ * The standard streams user_input, user_output and user_error are always open.
+ */
+type streamTable struct {
+	lock    sync.Mutex
+	streams map[int]*stream
+	aliases map[string]*stream
+	nextID  int
+	input   *stream
+	output  *stream
+}
+
+func newStreamTable() *streamTable {
+	t := &streamTable{
+		streams: make(map[int]*stream),
+		aliases: make(map[string]*stream),
+		nextID:  streamUserError + 1,
+	}
+	t.register(&stream{id: streamUserInput, alias: "user_input", mode: "read", reader: bufio.NewReader(os.Stdin), eofAction: "reset"})
+	t.register(&stream{id: streamUserOutput, alias: "user_output", mode: "append", writer: os.Stdout})
+	t.register(&stream{id: streamUserError, alias: "user_error", mode: "append", writer: os.Stderr})
+	t.input = t.streams[streamUserInput]
+	t.output = t.streams[streamUserOutput]
+	return t
+}
+
+func (t *streamTable) register(s *stream) {
+	t.streams[s.id] = s
+	if s.alias != "" {
+		t.aliases[s.alias] = s
+	}
+}
+
+// add gives the stream an id and adds it to the table
+func (t *streamTable) add(s *stream) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	s.id = t.nextID
+	t.nextID = t.nextID + 1
+	t.register(s)
+}
+
+// remove takes the stream out of the table, if it was the current input or output they are reset to the user streams
+func (t *streamTable) remove(s *stream) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.streams, s.id)
+	if s.alias != "" {
+		delete(t.aliases, s.alias)
+	}
+	if t.input == s {
+		t.input = t.streams[streamUserInput]
+	}
+	if t.output == s {
+		t.output = t.streams[streamUserOutput]
+	}
+}
+
+func (t *streamTable) aliasTaken(alias string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.aliases[alias] != nil
+}
+
+func (t *streamTable) currentInput() *stream {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.input
+}
+
+func (t *streamTable) currentOutput() *stream {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.output
+}
+
+func (t *streamTable) setInput(s *stream) *stream {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	prev := t.input
+	t.input = s
+	return prev
+}
+
+func (t *streamTable) setOutput(s *stream) *stream {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	prev := t.output
+	t.output = s
+	return prev
+}
+
+func (t *streamTable) user(id int) *stream {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.streams[id]
+}
+
+/**
+ * lookup finds the stream referred to by a handle or alias.
+ * If there is no such stream, the matching ISO error is returned instead.
+ */
+func (t *streamTable) lookup(term ast.Term, c *Bindings) (*stream, *Bindings) {
+	term = c.Ground(term)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch term.GetType() {
+	case ast.T_Variable:
+		return nil, instantiationError()
+	case ast.T_Atom:
+		if s := t.aliases[term.String()]; s != nil {
+			return s, nil
+		}
+		return nil, existenceError("stream", term)
+	case ast.T_Fact:
+		f := term.(*ast.Fact)
+		if f.Head == "$stream" && len(f.Args) == 1 && f.Args[0].GetType() == ast.T_Number {
+			if s := t.streams[int(f.Args[0].(*ast.NumericLiteral).Value())]; s != nil {
+				return s, nil
+			}
+			return nil, existenceError("stream", term)
+		}
+	}
+	return nil, domainError("stream_or_alias", term)
+}
+
+// lookupInput is like lookup but also makes sure the stream can be read from
+func (t *streamTable) lookupInput(term ast.Term, c *Bindings) (*stream, *Bindings) {
+	s, ex := t.lookup(term, c)
+	if ex == nil && !s.isInput() {
+		return nil, permissionError("input", "stream", c.Ground(term))
+	}
+	return s, ex
+}
+
+// lookupOutput is like lookup but also makes sure the stream can be written to
+func (t *streamTable) lookupOutput(term ast.Term, c *Bindings) (*stream, *Bindings) {
+	s, ex := t.lookup(term, c)
+	if ex == nil && s.isInput() {
+		return nil, permissionError("output", "stream", c.Ground(term))
+	}
+	return s, ex
+}
+
+// SetOutput changes where user_output is written and returns the previous writer
+func (r *R) SetOutput(w io.Writer) io.Writer {
+	s := r.streams.user(streamUserOutput)
+	r.streams.lock.Lock()
+	defer r.streams.lock.Unlock()
+	prev := s.writer
+	s.writer = w
+	return prev
+}
+
+// SetInput changes where user_input is read from
+func (r *R) SetInput(rd io.Reader) {
+	s := r.streams.user(streamUserInput)
+	r.streams.lock.Lock()
+	defer r.streams.lock.Unlock()
+	s.reader = bufio.NewReader(rd)
+	s.pastEOF = false
+}
+
+// Output returns the writer for the current output stream
+func (r *R) Output() io.Writer {
+	return r.streams.currentOutput().writer
+}
@@ -0,0 +1,386 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * newTabling provides the builtins for tabled predicates.
+ *   table(Spec)            - Spec is a predicate indicator such as `path/2`, a mode spec or a conjunction or list
+ *                            of them. The predicate is defined in the context module.
+ *   abolish_all_tables     - throws away every complete table so the next call evaluates it again
+ *
+ * Calls to a tabled predicate are evaluated once for each variant of the goal and the answers are kept in a table,
+ * so left recursive predicates terminate and nothing is proved twice. A table is evaluated by running the
+ * predicate's clauses again and again until no new answers turn up. Calls to a variant which is still being
+ * evaluated get the answers found so far, the tables that depend on each other like this (an SCC) are completed
+ * together once the oldest of them reaches its fixpoint.
+ *
+ * A mode spec such as `:- table path(_, _, min).` turns on answer subsumption, only the smallest (min) or
+ * largest (max) value of the moded argument is kept for each combination of the other arguments, which
+ * are written as `_` or `index`. Values are compared in the standard order of terms.
+ */
+func newTabling(r *R) nativePredicates {
+	return nativePredicates{
+		"table/1": r.table,
+		"abolish_all_tables/0": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			r.tables.abolish()
+			send(ctx, out, c)
+		},
+	}
+}
+
+// tableMode describes how a tabled predicate keeps its answers, arg is -1 unless it uses answer subsumption
+type tableMode struct {
+	arg  int
+	mode string
+}
+
+/**
+ * tableStore holds the tables of every tabled predicate.
+ * Only one evaluation runs at a time, eval is held by the outermost tabled call until its table is
+ * complete. Calls made while evaluating a table carry the chain of tables they were made from, see tableCall.
+ */
+type tableStore struct {
+	lock sync.Mutex
+	eval sync.Mutex
+
+	// modes holds the declared predicates by module and signature
+	modes  map[string]*tableMode
+	tables map[string]*table
+	nextID int
+	// answers counts every answer added to any table, evaluations use it to find their fixpoint
+	answers int
+}
+
+type table struct {
+	id      int
+	answers []ast.Term
+	// index maps the variant of each answer (or of its index args with answer subsumption) to its position in answers
+	index    map[string]int
+	complete bool
+	active   bool
+	// leader links the table to the others in its SCC, the oldest table is the root
+	leader *table
+}
+
+// tableCall is a table being evaluated, parent is the table whose evaluation called it
+type tableCall struct {
+	table  *table
+	parent *tableCall
+}
+
+func newTableStore() *tableStore {
+	return &tableStore{modes: make(map[string]*tableMode), tables: make(map[string]*table)}
+}
+
+// spec reports whether a predicate defined in the module is tabled
+func (s *tableStore) spec(module string, sig *ast.Signature) (*tableMode, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	m, ok := s.modes[module+":"+sig.String()]
+	return m, ok
+}
+
+// declare marks the predicate as tabled, dropping any tables it already has
+func (s *tableStore) declare(module string, sig *ast.Signature, m *tableMode) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := module + ":" + sig.String()
+	s.modes[key] = m
+	for k, t := range s.tables {
+		if !t.active && len(k) > len(key) && k[:len(key)+1] == key+":" {
+			delete(s.tables, k)
+		}
+	}
+}
+
+// abolish throws away every table that isnt being evaluated
+func (s *tableStore) abolish() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for k, t := range s.tables {
+		if !t.active {
+			delete(s.tables, k)
+		}
+	}
+}
+
+// find returns the root of the table's SCC
+func (s *tableStore) find(t *table) *table {
+	for t.leader != t {
+		t = t.leader
+	}
+	return t
+}
+
+// union puts two tables in the same SCC
+func (s *tableStore) union(a *table, b *table) {
+	ra, rb := s.find(a), s.find(b)
+	if ra == rb {
+		return
+	}
+	if ra.id < rb.id {
+		rb.leader = ra
+	} else {
+		ra.leader = rb
+	}
+}
+
+/**
+ * dependsOn records that the tables in the call chain depend on t, which is still being evaluated.
+ * When t is one of the callers every table from it down to the caller is in the same SCC,
+ * otherwise the whole chain is merged with it.
+ */
+func (s *tableStore) dependsOn(caller *tableCall, t *table) {
+	for n := caller; n != nil; n = n.parent {
+		s.union(n.table, t)
+		if n.table == t {
+			return
+		}
+	}
+}
+
+// add adds an answer to the table, returning false if it didnt change the table
+func (s *tableStore) add(t *table, answer *ast.Fact, m *tableMode) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if m == nil || m.arg < 0 {
+		key := variantKey(answer)
+		if _, ok := t.index[key]; ok {
+			return false
+		}
+		t.index[key] = len(t.answers)
+		t.answers = append(t.answers, answer)
+		s.answers++
+		return true
+	}
+
+	index := make([]ast.Term, 0, len(answer.Args)-1)
+	index = append(index, answer.Args[:m.arg]...)
+	index = append(index, answer.Args[m.arg+1:]...)
+	key := variantKey(ast.CreateFact("", index...))
+	p, ok := t.index[key]
+	if !ok {
+		t.index[key] = len(t.answers)
+		t.answers = append(t.answers, answer)
+		s.answers++
+		return true
+	}
+	cmp := ast.CompareTerms(answer.Args[m.arg], t.answers[p].(*ast.Fact).Args[m.arg])
+	if (m.mode == "min" && cmp < 0) || (m.mode == "max" && cmp > 0) {
+		t.answers[p] = answer
+		s.answers++
+		return true
+	}
+	return false
+}
+
+func (s *tableStore) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.answers
+}
+
+// snapshot returns a copy of the answers in the table so far
+func (s *tableStore) snapshot(t *table) []ast.Term {
+	answers := make([]ast.Term, len(t.answers))
+	copy(answers, t.answers)
+	return answers
+}
+
+// tableCallFrom returns the table being evaluated on this branch, if any
+func tableCallFrom(ctx context.Context) *tableCall {
+	if f := frameFrom(ctx); f != nil {
+		return f.table
+	}
+	return nil
+}
+
+// withTableCall returns the context used to evaluate a table
+func withTableCall(ctx context.Context, call *tableCall) context.Context {
+	f := frameFrom(ctx)
+	if f == nil {
+		f = &frame{Context: ctx, query: &query{}}
+	} else {
+		f = f.with(ctx)
+	}
+	f.table = call
+	return f
+}
+
+// resolveTabled resolves a call to a tabled predicate by unifying the goal with each answer in its table
+func (r *R) resolveTabled(ctx context.Context, m *tableMode, module string, matching []ast.Statement, goal *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	answers, ex := r.tabledAnswers(ctx, m, module, matching, goal)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	for _, a := range answers {
+		if b := unifyTerms(goal, r.renameTerm(a), c); b != nil {
+			if !send(ctx, out, b) {
+				return
+			}
+		}
+	}
+}
+
+/**
+ * tabledAnswers returns the answers in the table for the goal's variant, evaluating it first unless it is
+ * complete or already being evaluated. If evaluating the table raises an exception it is left incomplete
+ * and the exception is returned.
+ */
+func (r *R) tabledAnswers(ctx context.Context, m *tableMode, module string, matching []ast.Statement, goal *ast.Fact) ([]ast.Term, *Bindings) {
+	s := r.tables
+	key := module + ":" + goal.Signature().String() + ":" + variantKey(goal)
+	caller := tableCallFrom(ctx)
+	if caller == nil {
+		s.lock.Lock()
+		if t, ok := s.tables[key]; ok && t.complete {
+			defer s.lock.Unlock()
+			return s.snapshot(t), nil
+		}
+		s.lock.Unlock()
+		s.eval.Lock()
+		defer s.eval.Unlock()
+	}
+
+	s.lock.Lock()
+	t, ok := s.tables[key]
+	if !ok {
+		s.nextID++
+		t = &table{id: s.nextID, index: make(map[string]int)}
+		s.tables[key] = t
+	}
+	if t.complete || t.active {
+		defer s.lock.Unlock()
+		if t.active {
+			s.dependsOn(caller, t)
+		}
+		return s.snapshot(t), nil
+	}
+	t.active = true
+	t.leader = t
+	s.lock.Unlock()
+
+	ctx = withTableCall(ctx, &tableCall{table: t, parent: caller})
+	tableGoal := r.renameTerm(goal).(*ast.Fact)
+	for ctx.Err() == nil {
+		before := s.count()
+		if ex := r.evaluate(ctx, m, module, matching, tableGoal, t); ex != nil {
+			s.lock.Lock()
+			t.active = false
+			s.lock.Unlock()
+			return nil, ex
+		}
+		if s.count() == before {
+			break
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t.active = false
+	if ctx.Err() == nil && s.find(t) == t {
+		for _, other := range s.tables {
+			if !other.complete && !other.active && s.find(other) == t {
+				other.complete = true
+			}
+		}
+	}
+	return s.snapshot(t), nil
+}
+
+// evaluate runs the clauses of a tabled predicate once, adding every solution to the table
+func (r *R) evaluate(ctx context.Context, m *tableMode, module string, matching []ast.Statement, goal *ast.Fact, t *table) *Bindings {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	solutions := make(chan *Bindings, paralellism)
+	go func() {
+		defer close(solutions)
+		r.resolveClauses(ctx, module, matching, goal, EmptyBindings(), solutions)
+	}()
+	for b := range solutions {
+		if b.IsException() {
+			return b
+		}
+		r.tables.add(t, b.Ground(goal).(*ast.Fact), m)
+	}
+	return nil
+}
+
+// table(Spec)
+func (r *R) table(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	items := tableSpecs(c.Ground(args[0]))
+
+	type declaration struct {
+		sig  *ast.Signature
+		mode *tableMode
+	}
+	declarations := []declaration{}
+	for _, item := range items {
+		switch item.GetType() {
+		case ast.T_Variable:
+			send(ctx, out, instantiationError())
+			return
+		case ast.T_Fact:
+		default:
+			send(ctx, out, typeError("predicate_indicator", item))
+			return
+		}
+
+		f := item.(*ast.Fact)
+		if f.Head == "/" && len(f.Args) == 2 {
+			name, arity := f.Args[0], f.Args[1]
+			if name.GetType() == ast.T_Variable || arity.GetType() == ast.T_Variable {
+				send(ctx, out, instantiationError())
+				return
+			}
+			n, ok := arity.(*ast.NumericLiteral)
+			if name.GetType() != ast.T_Atom || !ok || n.Value() != float64(int(n.Value())) || n.Value() < 0 {
+				send(ctx, out, typeError("predicate_indicator", item))
+				return
+			}
+			sig := &ast.Signature{Functor: name.String(), Arity: int(n.Value())}
+			declarations = append(declarations, declaration{sig: sig, mode: &tableMode{arg: -1}})
+			continue
+		}
+
+		mode := &tableMode{arg: -1}
+		for i, a := range f.Args {
+			if a.GetType() == ast.T_Variable || (a.GetType() == ast.T_Atom && a.String() == "index") {
+				continue
+			}
+			if a.GetType() != ast.T_Atom || (a.String() != "min" && a.String() != "max") || mode.arg >= 0 {
+				send(ctx, out, domainError("table_mode", a))
+				return
+			}
+			mode.arg, mode.mode = i, a.String()
+		}
+		declarations = append(declarations, declaration{sig: f.Signature(), mode: mode})
+	}
+
+	for _, d := range declarations {
+		r.tables.declare(contextModule(ctx), d.sig, d.mode)
+	}
+	send(ctx, out, c)
+}
+
+// tableSpecs flattens the conjunctions and lists given to table/1
+func tableSpecs(t ast.Term) []ast.Term {
+	items := []ast.Term{}
+	for _, item := range ast.Conjuncts(t) {
+		if item.GetType() == ast.T_Fact && ast.IsProperList(item) {
+			list, _ := ast.ListToSlice(item)
+			for _, l := range list {
+				items = append(items, tableSpecs(l)...)
+			}
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
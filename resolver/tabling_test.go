@@ -0,0 +1,69 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func TestTabling(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+
+	src := `
+edge(a, b).
+edge(b, c).
+edge(c, a).
+edge(c, d).
+path(X, Y) :- path(X, Z), edge(Z, Y).
+path(X, Y) :- edge(X, Y).
+
+left(X, Y) :- edge(X, Y).
+left(X, Y) :- right(X, Z), edge(Z, Y).
+right(X, Y) :- left(X, Y).
+
+cost(a, b, 1).
+cost(b, c, 1).
+cost(a, c, 5).
+cost(c, a, 1).
+dist(X, Y, C) :- cost(X, Y, C).
+dist(X, Y, C) :- dist(X, Z, C1), cost(Z, Y, W), C is C1 + W.
+longest(X, C) :- cost(X, _, C).
+`
+	statements, err := syntax.Parse([]rune(src), "tabling.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := indexer.NewDefault()
+	for _, st := range statements {
+		i.IndexStatement(st)
+	}
+	r := resolver.New(i)
+
+	declare := f("table", f(",", f("/", a("path"), num(2)), f(",",
+		ast.CreateList(f("/", a("left"), num(2)), f("/", a("right"), num(2))),
+		f(",", f("dist", v("_"), v("_"), a("min")), f("longest", a("index"), a("max"))))))
+	expectOne(t, "table/1", solve(r, declare), map[string]string{})
+
+	expectOne(t, "left recursion", solve(r, f("setof", v("Y"), f("path", a("a"), v("Y")), v("Ys"))), map[string]string{"Ys": "L[a,b,c,d]"})
+	expectOne(t, "complete table", solve(r, f("findall", v("Y"), f("path", a("a"), v("Y")), v("Ys")), f("length", v("Ys"), v("N"))), map[string]string{"N": "4.000000"})
+	expectOne(t, "all variants", solve(r, f("findall", f("-", v("X"), v("Y")), f("path", v("X"), v("Y")), v("Ps")), f("length", v("Ps"), v("N"))), map[string]string{"N": "12.000000"})
+	expectOne(t, "mutual recursion", solve(r, f("setof", v("Y"), f("right", a("b"), v("Y")), v("Ys"))), map[string]string{"Ys": "L[a,b,c,d]"})
+
+	expectOne(t, "min", solve(r, f("dist", a("a"), a("c"), v("C"))), map[string]string{"C": "2.000000"})
+	expectOne(t, "min answers", solve(r, f("setof", f("-", v("Y"), v("C")), f("dist", a("a"), v("Y"), v("C")), v("Ds"))), map[string]string{"Ds": "L[-(a,3.000000),-(b,1.000000),-(c,2.000000)]"})
+	expectOne(t, "max", solve(r, f("longest", a("a"), v("C"))), map[string]string{"C": "5.000000"})
+
+	expectError(t, "unbound spec", solve(r, f("table", v("P"))), "instantiation_error")
+	expectError(t, "bad mode", solve(r, f("table", f("dist", v("_"), v("_"), a("sum")))), "domain_error(table_mode,sum)")
+
+	// complete tables dont see new clauses until they are abolished
+	expectOne(t, "assert", solve(r, f("assert", f("edge", a("d"), a("e")))), map[string]string{})
+	expectOne(t, "stale table", solve(r, f("findall", v("Y"), f("path", a("a"), v("Y")), v("Ys")), f("length", v("Ys"), v("N"))), map[string]string{"N": "4.000000"})
+	expectOne(t, "abolish_all_tables", solve(r, f("abolish_all_tables")), map[string]string{})
+	expectOne(t, "fresh table", solve(r, f("setof", v("Y"), f("path", a("a"), v("Y")), v("Ys"))), map[string]string{"Ys": "L[a,b,c,d,e]"})
+}
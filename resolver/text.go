@@ -0,0 +1,583 @@
+package resolver
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+var numberPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+/**
+ * Text provides the builtins for inspecting and building atoms and strings.
+ * Anywhere a piece of text is expected an atom, string or number can be used.
+ * The atom_* builtins produce atoms while the string_* builtins produce strings.
+ * They raise an instantiation_error when there isnt enough bound to run them and a type_error for an argument
+ * which is bound to the wrong kind of term.
+ */
+type Text struct{}
+
+func newText() nativePredicates {
+	w := &Text{}
+	return nativePredicates{
+		"atom_length/2":   w.length,
+		"string_length/2": w.length,
+		"atom_concat/3": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.concat(ctx, atomTerm, a, c, out)
+		},
+		"string_concat/3": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.concat(ctx, stringTerm, a, c, out)
+		},
+		"sub_atom/5": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.sub(ctx, atomTerm, a, c, out)
+		},
+		"sub_string/5": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.sub(ctx, stringTerm, a, c, out)
+		},
+		"atom_chars/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.chars(ctx, atomTerm, a, c, out)
+		},
+		"string_chars/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.chars(ctx, stringTerm, a, c, out)
+		},
+		"atom_codes/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.codes(ctx, atomTerm, a, c, out)
+		},
+		"string_codes/2": func(ctx context.Context, a []ast.Term, c *Bindings, out chan<- *Bindings) {
+			w.codes(ctx, stringTerm, a, c, out)
+		},
+		"char_code/2":          w.charCode,
+		"atom_number/2":        w.atomNumber,
+		"number_codes/2":       w.numberCodes,
+		"number_chars/2":       w.numberChars,
+		"upcase_atom/2":        w.upcaseAtom,
+		"downcase_atom/2":      w.downcaseAtom,
+		"atom_string/2":        w.atomString,
+		"string_to_atom/2":     w.stringToAtom,
+		"atomic_list_concat/2": w.atomicListConcat,
+		"atomic_list_concat/3": w.atomicListConcatSep,
+		"split_string/4":       w.splitString,
+	}
+}
+
+func atomTerm(s string) ast.Term {
+	return ast.CreateAtom(s)
+}
+
+func stringTerm(s string) ast.Term {
+	return ast.CreateStringLiteral(s)
+}
+
+// parseNumber parses text as a number, returning false if it isnt one
+func parseNumber(s string) (float64, bool) {
+	if !numberPattern.MatchString(s) {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// textOf returns the text of an atom, string or number
+func textOf(t ast.Term, c *Bindings) (string, bool) {
+	t = c.Dereference(t)
+	switch t.GetType() {
+	case ast.T_Atom, ast.T_String:
+		return t.String(), true
+	case ast.T_Number:
+		return ast.FormatNumber(t.(*ast.NumericLiteral).Value()), true
+	}
+	return "", false
+}
+
+// charsText converts a proper list of one character atoms into a string
+func charsText(t ast.Term, c *Bindings) (string, bool) {
+	items, ok := properList(t, c)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, item := range items {
+		s, ok := textOf(item, c)
+		if !ok || utf8.RuneCountInString(s) != 1 {
+			return "", false
+		}
+		sb.WriteString(s)
+	}
+	return sb.String(), true
+}
+
+// codesText converts a proper list of character codes into a string
+func codesText(t ast.Term, c *Bindings) (string, bool) {
+	items, ok := properList(t, c)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, item := range items {
+		code, ok := intValue(item, c)
+		if !ok || code < 0 {
+			return "", false
+		}
+		sb.WriteRune(rune(code))
+	}
+	return sb.String(), true
+}
+
+// unbound reports whether the term is an unbound variable
+func unbound(t ast.Term, c *Bindings) bool {
+	return c.Dereference(t).GetType() == ast.T_Variable
+}
+
+// expected is the exception for an argument which isnt the kind of term a builtin needs, or is unbound
+func expected(kind string, t ast.Term, c *Bindings) *Bindings {
+	if unbound(t, c) {
+		return instantiationError()
+	}
+	return typeError(kind, c.Ground(t))
+}
+
+// textArg returns the text of an atom, string or number, or the exception to raise if the term isnt one
+func textArg(t ast.Term, c *Bindings) (string, *Bindings) {
+	if s, ok := textOf(t, c); ok {
+		return s, nil
+	}
+	return "", expected("atomic", t, c)
+}
+
+// optionalText checks an argument which can either be unbound or text, see textArg
+func optionalText(t ast.Term, c *Bindings) *Bindings {
+	if unbound(t, c) {
+		return nil
+	}
+	_, ex := textArg(t, c)
+	return ex
+}
+
+// optionalInt checks an argument which can either be unbound or an integer
+func optionalInt(t ast.Term, c *Bindings) *Bindings {
+	if _, ok := intValue(t, c); ok || unbound(t, c) {
+		return nil
+	}
+	return typeError("integer", c.Ground(t))
+}
+
+// listArg returns the items of a proper list, a partial list raises an instantiation_error and anything else a type_error
+func listArg(t ast.Term, c *Bindings) ([]ast.Term, *Bindings) {
+	items, tail := ast.ListToSlice(c.Ground(t))
+	if ast.IsEmptyList(tail) {
+		return items, nil
+	} else if tail.GetType() == ast.T_Variable {
+		return nil, instantiationError()
+	}
+	return nil, typeError("list", c.Ground(t))
+}
+
+// charsArg is charsText raising the exception for a list which isnt made of characters
+func charsArg(t ast.Term, c *Bindings) (string, *Bindings) {
+	items, ex := listArg(t, c)
+	if ex != nil {
+		return "", ex
+	}
+	var sb strings.Builder
+	for _, item := range items {
+		s, ok := textOf(item, c)
+		if !ok || utf8.RuneCountInString(s) != 1 {
+			return "", expected("character", item, c)
+		}
+		sb.WriteString(s)
+	}
+	return sb.String(), nil
+}
+
+// codesArg is codesText raising the exception for a list which isnt made of character codes
+func codesArg(t ast.Term, c *Bindings) (string, *Bindings) {
+	items, ex := listArg(t, c)
+	if ex != nil {
+		return "", ex
+	}
+	var sb strings.Builder
+	for _, item := range items {
+		code, ok := intValue(item, c)
+		if !ok {
+			return "", expected("integer", item, c)
+		} else if code < 0 || code > utf8.MaxRune {
+			return "", representationError("character_code")
+		}
+		sb.WriteRune(rune(code))
+	}
+	return sb.String(), nil
+}
+
+func charList(s string) *ast.Fact {
+	items := []ast.Term{}
+	for _, r := range s {
+		items = append(items, ast.CreateAtom(string(r)))
+	}
+	return ast.CreateList(items...)
+}
+
+func codeList(s string) *ast.Fact {
+	items := []ast.Term{}
+	for _, r := range s {
+		items = append(items, ast.CreateNumericLiteral(float64(r)))
+	}
+	return ast.CreateList(items...)
+}
+
+// atom_length(Atom, Length) and string_length(String, Length)
+func (w *Text) length(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, ex := textArg(args[0], c)
+	if ex == nil {
+		ex = optionalInt(args[1], c)
+	}
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(float64(utf8.RuneCountInString(s))), c, out)
+}
+
+/**
+ * atom_concat(A1, A2, A3) and string_concat(S1, S2, S3)
+ * If the first two are bound they are joined, otherwise every way of splitting the third is enumerated.
+ */
+func (w *Text) concat(ctx context.Context, mk func(string) ast.Term, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	for _, arg := range args {
+		if ex := optionalText(arg, c); ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+	}
+	lhs, lok := textOf(args[0], c)
+	rhs, rok := textOf(args[1], c)
+	if lok && rok {
+		unifyAndSend(ctx, args[2], mk(lhs+rhs), c, out)
+		return
+	}
+
+	whole, ex := textArg(args[2], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	runes := []rune(whole)
+	for i := 0; i <= len(runes); i++ {
+		b := unifyTerms(args[0], mk(string(runes[:i])), c)
+		if b == nil {
+			continue
+		}
+		unifyAndSend(ctx, args[1], mk(string(runes[i:])), b, out)
+	}
+}
+
+/**
+ * sub_atom(Atom, Before, Length, After, Sub) and sub_string(String, Before, Length, After, Sub)
+ * Sub is a part of Atom with Before characters before it and After characters after it.
+ * Any combination of the last four args can be unbound, all of the matching sub atoms are enumerated.
+ */
+func (w *Text) sub(ctx context.Context, mk func(string) ast.Term, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	whole, ex := textArg(args[0], c)
+	for _, arg := range args[1:4] {
+		if ex == nil {
+			ex = optionalInt(arg, c)
+		}
+	}
+	if ex == nil {
+		ex = optionalText(args[4], c)
+	}
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	runes := []rune(whole)
+	n := len(runes)
+
+	emit := func(before int, length int) {
+		b := unifyTerms(args[1], ast.CreateNumericLiteral(float64(before)), c)
+		if b == nil {
+			return
+		}
+		b = unifyTerms(args[2], ast.CreateNumericLiteral(float64(length)), b)
+		if b == nil {
+			return
+		}
+		b = unifyTerms(args[3], ast.CreateNumericLiteral(float64(n-before-length)), b)
+		if b == nil {
+			return
+		}
+		unifyAndSend(ctx, args[4], mk(string(runes[before:before+length])), b, out)
+	}
+
+	// if the sub atom is known, just look for each occurrence of it
+	if sub, ok := textOf(args[4], c); ok {
+		subRunes := []rune(sub)
+		for before := 0; before+len(subRunes) <= n; before++ {
+			if string(runes[before:before+len(subRunes)]) == sub {
+				emit(before, len(subRunes))
+			}
+		}
+		return
+	}
+
+	for before := 0; before <= n; before++ {
+		for length := 0; before+length <= n; length++ {
+			emit(before, length)
+		}
+	}
+}
+
+// atom_chars(Atom, Chars) and string_chars(String, Chars)
+func (w *Text) chars(ctx context.Context, mk func(string) ast.Term, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.list(ctx, mk, charList, charsArg, args, c, out)
+}
+
+// atom_codes(Atom, Codes) and string_codes(String, Codes)
+func (w *Text) codes(ctx context.Context, mk func(string) ast.Term, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.list(ctx, mk, codeList, codesArg, args, c, out)
+}
+
+// list converts text to a list of its characters or codes when it is bound and the list back to text when it isnt
+func (w *Text) list(ctx context.Context, mk func(string) ast.Term, toList func(string) *ast.Fact, fromList func(ast.Term, *Bindings) (string, *Bindings), args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if !unbound(args[0], c) {
+		s, ex := textArg(args[0], c)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		unifyAndSend(ctx, args[1], toList(s), c, out)
+		return
+	}
+	s, ex := fromList(args[1], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	unifyAndSend(ctx, args[0], mk(s), c, out)
+}
+
+// char_code(Char, Code)
+func (w *Text) charCode(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if !unbound(args[0], c) {
+		s, ok := textOf(args[0], c)
+		if !ok || utf8.RuneCountInString(s) != 1 {
+			send(ctx, out, typeError("character", c.Ground(args[0])))
+			return
+		}
+		if ex := optionalInt(args[1], c); ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		r, _ := utf8.DecodeRuneInString(s)
+		unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(float64(r)), c, out)
+		return
+	}
+	code, ok := intValue(args[1], c)
+	if !ok {
+		send(ctx, out, expected("integer", args[1], c))
+	} else if code < 0 || code > utf8.MaxRune {
+		send(ctx, out, representationError("character_code"))
+	} else {
+		unifyAndSend(ctx, args[0], ast.CreateAtom(string(rune(code))), c, out)
+	}
+}
+
+// atom_number(Atom, Number) fails if Atom is not the text of a number
+func (w *Text) atomNumber(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	a := c.Dereference(args[0])
+	if a.GetType() == ast.T_Atom || a.GetType() == ast.T_String {
+		if v, ok := parseNumber(a.String()); ok {
+			unifyAndSend(ctx, args[1], ast.CreateNumericLiteral(v), c, out)
+		}
+		return
+	} else if a.GetType() != ast.T_Variable {
+		send(ctx, out, typeError("atom", c.Ground(a)))
+		return
+	}
+	n := c.Dereference(args[1])
+	if n.GetType() != ast.T_Number {
+		send(ctx, out, expected("number", n, c))
+		return
+	}
+	unifyAndSend(ctx, args[0], ast.CreateAtom(ast.FormatNumber(n.(*ast.NumericLiteral).Value())), c, out)
+}
+
+// number_codes(Number, Codes)
+func (w *Text) numberCodes(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.number(ctx, codeList, codesArg, args, c, out)
+}
+
+// number_chars(Number, Chars)
+func (w *Text) numberChars(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.number(ctx, charList, charsArg, args, c, out)
+}
+
+// number converts a number to a list of its characters or codes when it is bound and reads the number from the list when it isnt
+func (w *Text) number(ctx context.Context, toList func(string) *ast.Fact, fromList func(ast.Term, *Bindings) (string, *Bindings), args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	if n := c.Dereference(args[0]); n.GetType() == ast.T_Number {
+		unifyAndSend(ctx, args[1], toList(ast.FormatNumber(n.(*ast.NumericLiteral).Value())), c, out)
+		return
+	} else if n.GetType() != ast.T_Variable {
+		send(ctx, out, typeError("number", c.Ground(n)))
+		return
+	}
+	s, ex := fromList(args[1], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	v, ok := parseNumber(s)
+	if !ok {
+		send(ctx, out, syntaxError("illegal_number"))
+		return
+	}
+	unifyAndSend(ctx, args[0], ast.CreateNumericLiteral(v), c, out)
+}
+
+// convert is used for the builtins which map text from the first argument onto the second (upcase_atom/2 etc..)
+// If the first argument is unbound and back is given, the text from the second argument is used to build the first.
+func (w *Text) convert(ctx context.Context, fn func(string) string, mk func(string) ast.Term, back func(string) ast.Term, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	ex := optionalText(args[1], c)
+	if ex == nil && (!unbound(args[0], c) || back == nil) {
+		var s string
+		if s, ex = textArg(args[0], c); ex == nil {
+			if fn != nil {
+				s = fn(s)
+			}
+			unifyAndSend(ctx, args[1], mk(s), c, out)
+			return
+		}
+	}
+	if ex == nil {
+		var s string
+		if s, ex = textArg(args[1], c); ex == nil {
+			unifyAndSend(ctx, args[0], back(s), c, out)
+			return
+		}
+	}
+	send(ctx, out, ex)
+}
+
+// upcase_atom(Atom, Upper)
+func (w *Text) upcaseAtom(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.convert(ctx, strings.ToUpper, atomTerm, nil, args, c, out)
+}
+
+// downcase_atom(Atom, Lower)
+func (w *Text) downcaseAtom(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.convert(ctx, strings.ToLower, atomTerm, nil, args, c, out)
+}
+
+// atom_string(Atom, String)
+func (w *Text) atomString(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.convert(ctx, nil, stringTerm, atomTerm, args, c, out)
+}
+
+// string_to_atom(String, Atom)
+func (w *Text) stringToAtom(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.convert(ctx, nil, atomTerm, stringTerm, args, c, out)
+}
+
+// listText returns the text of each item in a proper list of atomic terms, or the exception to raise if it isnt one
+func listText(t ast.Term, c *Bindings) ([]string, *Bindings) {
+	items, ex := listArg(t, c)
+	if ex != nil {
+		return nil, ex
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		if parts[i], ex = textArg(item, c); ex != nil {
+			return nil, ex
+		}
+	}
+	return parts, nil
+}
+
+// atomic_list_concat(List, Atom)
+func (w *Text) atomicListConcat(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	parts, ex := listText(args[0], c)
+	if ex == nil {
+		ex = optionalText(args[1], c)
+	}
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	unifyAndSend(ctx, args[1], ast.CreateAtom(strings.Join(parts, "")), c, out)
+}
+
+/**
+ * atomic_list_concat(List, Separator, Atom)
+ * If List is a proper list of atomic terms they are joined with Separator.
+ * Otherwise, if Atom is bound, it is split on Separator and the parts are unified with List.
+ */
+func (w *Text) atomicListConcatSep(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	sep, ex := textArg(args[1], c)
+	if ex == nil {
+		ex = optionalText(args[2], c)
+	}
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	parts, ex := listText(args[0], c)
+	if ex == nil {
+		unifyAndSend(ctx, args[2], ast.CreateAtom(strings.Join(parts, sep)), c, out)
+		return
+	}
+
+	// a list which isnt complete yet is made by splitting the atom
+	whole, ok := textOf(args[2], c)
+	if !ok {
+		send(ctx, out, ex)
+		return
+	} else if sep == "" {
+		send(ctx, out, domainError("non_empty_atom", c.Ground(args[1])))
+		return
+	}
+	items := []ast.Term{}
+	for _, part := range strings.Split(whole, sep) {
+		items = append(items, ast.CreateAtom(part))
+	}
+	unifyAndSend(ctx, args[0], ast.CreateList(items...), c, out)
+}
+
+/**
+ * split_string(String, SepChars, PadChars, SubStrings)
+ * String is split at every character in SepChars, then any of the characters in PadChars
+ * are removed from the start and end of each part. The parts are returned as strings.
+ */
+func (w *Text) splitString(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	text := make([]string, 3)
+	for i := range text {
+		var ex *Bindings
+		if text[i], ex = textArg(args[i], c); ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+	}
+	whole, sepChars, padChars := text[0], text[1], text[2]
+
+	parts := []string{whole}
+	if sepChars != "" {
+		parts = []string{}
+		start := 0
+		runes := []rune(whole)
+		for i, r := range runes {
+			if strings.ContainsRune(sepChars, r) {
+				parts = append(parts, string(runes[start:i]))
+				start = i + 1
+			}
+		}
+		parts = append(parts, string(runes[start:]))
+	}
+
+	items := []ast.Term{}
+	for _, p := range parts {
+		items = append(items, ast.CreateStringLiteral(strings.Trim(p, padChars)))
+	}
+	unifyAndSend(ctx, args[3], ast.CreateList(items...), c, out)
+}
@@ -0,0 +1,117 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+func TestTextCases(t *testing.T) {
+	a := ast.CreateAtom
+	s := ast.CreateStringLiteral
+	v := ast.CreateVariable
+	query := func(head string, args ...ast.Term) *ast.Query {
+		return &ast.Query{ast.CreateFact(head, args...)}
+	}
+	solutions := func(bindings ...map[string]ast.Term) []*resolver.Bindings {
+		ret := []*resolver.Bindings{}
+		for _, b := range bindings {
+			ret = append(ret, resolver.CreateBindings(b))
+		}
+		return ret
+	}
+	type m = map[string]ast.Term
+
+	cases := []resolverTestCase{
+		{"atom_length", nil, query("atom_length", a("hello"), v("L")), resolver.EmptyBindings(), solutions(m{"L": num(5)})},
+		{"atom_length number", nil, query("atom_length", num(123), v("L")), resolver.EmptyBindings(), solutions(m{"L": num(3)})},
+		{"string_length", nil, query("string_length", s("héllo"), v("L")), resolver.EmptyBindings(), solutions(m{"L": num(5)})},
+		{"atom_concat forward", nil, query("atom_concat", a("ab"), a("cd"), v("X")), resolver.EmptyBindings(), solutions(m{"X": a("abcd")})},
+		{"atom_concat reverse", nil, query("atom_concat", v("X"), v("Y"), a("ab")), resolver.EmptyBindings(), solutions(
+			m{"X": a(""), "Y": a("ab")},
+			m{"X": a("a"), "Y": a("b")},
+			m{"X": a("ab"), "Y": a("")},
+		)},
+		{"atom_concat suffix", nil, query("atom_concat", v("X"), a("b"), a("ab")), resolver.EmptyBindings(), solutions(m{"X": a("a")})},
+		{"string_concat", nil, query("string_concat", s("ab"), a("cd"), v("X")), resolver.EmptyBindings(), solutions(m{"X": s("abcd")})},
+		{"sub_atom known sub", nil, query("sub_atom", a("abcab"), v("B"), v("L"), v("A"), a("ab")), resolver.EmptyBindings(), solutions(
+			m{"B": num(0), "L": num(2), "A": num(3)},
+			m{"B": num(3), "L": num(2), "A": num(0)},
+		)},
+		{"sub_atom prefix", nil, query("sub_atom", a("hello"), num(0), num(2), v("A"), v("S")), resolver.EmptyBindings(), solutions(m{"A": num(3), "S": a("he")})},
+		{"sub_string suffix", nil, query("sub_string", s("hello"), v("B"), num(3), num(0), v("S")), resolver.EmptyBindings(), solutions(m{"B": num(2), "S": s("llo")})},
+		{"atom_chars", nil, query("atom_chars", a("ab"), v("X")), resolver.EmptyBindings(), solutions(m{"X": ast.CreateList(a("a"), a("b"))})},
+		{"atom_chars reverse", nil, query("atom_chars", v("X"), ast.CreateList(a("a"), a("b"))), resolver.EmptyBindings(), solutions(m{"X": a("ab")})},
+		{"string_chars reverse", nil, query("string_chars", v("X"), ast.CreateList(a("a"), a("b"))), resolver.EmptyBindings(), solutions(m{"X": s("ab")})},
+		{"atom_codes", nil, query("atom_codes", a("ab"), v("X")), resolver.EmptyBindings(), solutions(m{"X": ast.CreateList(num(97), num(98))})},
+		{"atom_codes reverse", nil, query("atom_codes", v("X"), ast.CreateList(num(97), num(98))), resolver.EmptyBindings(), solutions(m{"X": a("ab")})},
+		{"char_code", nil, query("char_code", a("a"), v("X")), resolver.EmptyBindings(), solutions(m{"X": num(97)})},
+		{"char_code reverse", nil, query("char_code", v("X"), num(98)), resolver.EmptyBindings(), solutions(m{"X": a("b")})},
+		{"atom_number", nil, query("atom_number", a("12.5"), v("X")), resolver.EmptyBindings(), solutions(m{"X": num(12.5)})},
+		{"atom_number not a number", nil, query("atom_number", a("abc"), v("X")), resolver.EmptyBindings(), solutions()},
+		{"atom_number reverse", nil, query("atom_number", v("X"), num(7)), resolver.EmptyBindings(), solutions(m{"X": a("7")})},
+		{"number_codes", nil, query("number_codes", v("X"), ast.CreateList(num(52), num(50))), resolver.EmptyBindings(), solutions(m{"X": num(42)})},
+		{"upcase_atom", nil, query("upcase_atom", a("hello World"), v("X")), resolver.EmptyBindings(), solutions(m{"X": a("HELLO WORLD")})},
+		{"atomic_list_concat/2", nil, query("atomic_list_concat", ast.CreateList(a("a"), num(1), s("b")), v("X")), resolver.EmptyBindings(), solutions(m{"X": a("a1b")})},
+		{"atomic_list_concat/3", nil, query("atomic_list_concat", ast.CreateList(a("a"), a("b"), a("c")), a(", "), v("X")), resolver.EmptyBindings(), solutions(m{"X": a("a, b, c")})},
+		{"atomic_list_concat/3 split", nil, query("atomic_list_concat", v("L"), a("-"), a("a-b--c")), resolver.EmptyBindings(), solutions(m{"L": ast.CreateList(a("a"), a("b"), a(""), a("c"))})},
+		{"split_string", nil, query("split_string", s("a, b ,c"), s(","), s(" "), v("L")), resolver.EmptyBindings(), solutions(m{"L": ast.CreateList(s("a"), s("b"), s("c"))})},
+		{"split_string only padding", nil, query("split_string", s("  hello  "), s(""), s(" "), v("L")), resolver.EmptyBindings(), solutions(m{"L": ast.CreateList(s("hello"))})},
+		{"split_string adjacent separators", nil, query("split_string", s("/home//jan///nice/path"), s("/"), s(""), v("L")), resolver.EmptyBindings(), solutions(m{"L": ast.CreateList(
+			s(""), s("home"), s(""), s("jan"), s(""), s(""), s("nice"), s("path"),
+		)})},
+	}
+
+	for _, c := range cases {
+		runTestCase(t, c)
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	a := ast.CreateAtom
+	v := ast.CreateVariable
+	f := ast.CreateFact
+	l := ast.CreateList
+
+	cases := []struct {
+		label  string
+		goal   *ast.Fact
+		formal string
+	}{
+		{"atom_length unbound", f("atom_length", v("A"), v("L")), "instantiation_error"},
+		{"atom_length compound", f("atom_length", f("f", a("x")), v("L")), "type_error(atomic,f(x))"},
+		{"atom_length length", f("atom_length", a("abc"), a("three")), "type_error(integer,three)"},
+		{"atom_concat unbound", f("atom_concat", v("A"), a("b"), v("C")), "instantiation_error"},
+		{"atom_concat compound", f("atom_concat", a("a"), f("f", a("x")), v("C")), "type_error(atomic,f(x))"},
+		{"sub_atom unbound", f("sub_atom", v("A"), v("B"), v("L"), v("R"), v("S")), "instantiation_error"},
+		{"sub_atom before", f("sub_atom", a("abc"), a("b"), v("L"), v("R"), v("S")), "type_error(integer,b)"},
+		{"atom_codes unbound", f("atom_codes", v("A"), v("L")), "instantiation_error"},
+		{"atom_codes partial list", f("atom_codes", v("A"), ast.CreateFact("|", num(97), v("T"))), "instantiation_error"},
+		{"atom_codes not a list", f("atom_codes", v("A"), a("abc")), "type_error(list,abc)"},
+		{"atom_codes not a code", f("atom_codes", v("A"), l(a("a"))), "type_error(integer,a)"},
+		{"atom_codes invalid code", f("atom_codes", v("A"), l(num(-1))), "representation_error(character_code)"},
+		{"atom_chars not a char", f("atom_chars", v("A"), l(a("ab"))), "type_error(character,ab)"},
+		{"char_code unbound", f("char_code", v("A"), v("C")), "instantiation_error"},
+		{"char_code not a char", f("char_code", a("ab"), v("C")), "type_error(character,ab)"},
+		{"atom_number unbound", f("atom_number", v("A"), v("N")), "instantiation_error"},
+		{"atom_number compound", f("atom_number", f("f", a("x")), v("N")), "type_error(atom,f(x))"},
+		{"atom_number not a number", f("atom_number", v("A"), a("one")), "type_error(number,one)"},
+		{"number_codes unbound", f("number_codes", v("N"), v("L")), "instantiation_error"},
+		{"number_codes not a number", f("number_codes", v("N"), l(num(97))), "syntax_error(illegal_number)"},
+		{"number_chars atom", f("number_chars", a("a"), v("L")), "type_error(number,a)"},
+		{"upcase_atom unbound", f("upcase_atom", v("A"), v("U")), "instantiation_error"},
+		{"upcase_atom compound", f("upcase_atom", f("f", a("x")), v("U")), "type_error(atomic,f(x))"},
+		{"atomic_list_concat unbound", f("atomic_list_concat", v("L"), v("A")), "instantiation_error"},
+		{"atomic_list_concat item", f("atomic_list_concat", l(a("a"), v("X")), v("A")), "instantiation_error"},
+		{"atomic_list_concat separator", f("atomic_list_concat", l(a("a")), v("S"), v("A")), "instantiation_error"},
+		{"atomic_list_concat split", f("atomic_list_concat", v("L"), a(""), a("abc")), "domain_error(non_empty_atom,)"},
+		{"split_string unbound", f("split_string", v("S"), a(","), a(""), v("L")), "instantiation_error"},
+	}
+
+	for _, c := range cases {
+		r := resolver.New(indexer.NewDefault())
+		expectError(t, c.label, solve(r, c.goal), c.formal)
+	}
+}
@@ -0,0 +1,370 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+)
+
+// Port is one of the points in the box model where the tracer sees a goal
+type Port int
+
+const (
+	// CallPort is reached when a goal is first tried
+	CallPort Port = iota
+	// ExitPort is reached each time the goal succeeds
+	ExitPort
+	// RedoPort is reached when the goal is retried for another solution, just before that solution exits
+	RedoPort
+	// FailPort is reached when the goal has no solutions
+	FailPort
+	// ExceptionPort is reached when an exception passes through the goal
+	ExceptionPort
+)
+
+func (p Port) String() string {
+	return [...]string{"Call", "Exit", "Redo", "Fail", "Exception"}[p]
+}
+
+// Action tells the resolver how to continue after a trace event
+type Action int
+
+const (
+	// Creep shows the next event
+	Creep Action = iota
+	// Skip hides the events inside the current goal
+	Skip
+	// Leap hides everything until a spy point is reached
+	Leap
+	// Abort stops the query by throwing '$aborted'
+	Abort
+)
+
+/**
+ * TraceEvent describes a goal at one of its ports.
+ * Variables are shown with the names used in the clause they came from rather than the resolver's internal names.
+ */
+type TraceEvent struct {
+	Port  Port
+	Depth int
+	Goal  ast.Term
+	// Bindings holds the value of each named variable in the goal's clause
+	Bindings map[string]ast.Term
+	// Exception is the ball passing through the ExceptionPort
+	Exception ast.Term
+}
+
+func (e *TraceEvent) String() string {
+	text := fmt.Sprintf("   %s: (%d) %s", e.Port, e.Depth, ast.WriteTerm(e.Goal, ast.WriteOptions{Quoted: true}))
+	if e.Port == ExceptionPort {
+		text += " " + ast.WriteTerm(e.Exception, ast.WriteOptions{Quoted: true})
+	}
+	return text
+}
+
+// Tracer is told about each event while tracing and decides what to do next, see SetTracer
+type Tracer interface {
+	Trace(e *TraceEvent) Action
+}
+
+const (
+	debugOff int32 = iota
+	// debugTrace shows every event
+	debugTrace
+	// debugLeap only starts showing events again once a spy point is called
+	debugLeap
+)
+
+/**
+ * debugger holds the state behind trace/0, notrace/0, spy/1 and nospy/1.
+ * There is only one debugger for each resolver so every query running on it shares it.
+ */
+type debugger struct {
+	mode int32
+
+	lock   sync.Mutex
+	tracer Tracer
+	spies  map[string]bool
+	// skip hides all of the events deeper than skipDepth, -1 when nothing is being skipped
+	skipDepth int
+	// indexed maps the names in indexed clauses back to the source, if the indexer supports it
+	indexed indexer.VariableNamer
+}
+
+func newDebugger(r *R) *debugger {
+	d := &debugger{
+		tracer:    &printTracer{r},
+		spies:     make(map[string]bool),
+		skipDepth: -1,
+	}
+	d.indexed, _ = r.i.(indexer.VariableNamer)
+	return d
+}
+
+// sourceName finds the name a variable had in the source using the names remembered for the query
+func (d *debugger) sourceName(names map[string]string, name string) (string, bool) {
+	original, ok := names[name]
+	if !ok {
+		return "", false
+	}
+	if d.indexed != nil {
+		if source, ok := d.indexed.VariableName(original); ok {
+			return source, true
+		}
+	}
+	return original, true
+}
+
+func (d *debugger) active() bool {
+	return atomic.LoadInt32(&d.mode) != debugOff
+}
+
+/**
+ * remember records the original variable names for renamed variables so they can be shown in trace events.
+ * They are kept with the query that renamed them, so they go away once it is done.
+ */
+func (d *debugger) remember(ctx context.Context, mappings map[string]string) {
+	f := frameFrom(ctx)
+	if !d.active() || f == nil {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if f.query.names == nil {
+		f.query.names = make(map[string]string)
+	}
+	for original, renamed := range mappings {
+		f.query.names[renamed] = original
+	}
+}
+
+func (d *debugger) isSpy(f *ast.Fact) bool {
+	return d.spies[f.Head] || d.spies[f.Signature().String()]
+}
+
+// SetTracer changes what happens to trace events, by default they are printed to user_error
+func (r *R) SetTracer(t Tracer) {
+	r.debug.lock.Lock()
+	defer r.debug.lock.Unlock()
+	r.debug.tracer = t
+}
+
+// printTracer writes every event to user_error and keeps going
+type printTracer struct {
+	r *R
+}
+
+func (p *printTracer) Trace(e *TraceEvent) Action {
+	_ = p.r.streams.user(streamUserError).write(e.String() + "\n")
+	return Creep
+}
+
+/**
+ * event reports a port to the tracer if it should be shown and returns the action to take.
+ * Events are reported one at a time, so an interactive tracer never has two prompts open.
+ */
+func (r *R) event(ctx context.Context, port Port, depth int, f *ast.Fact, c *Bindings, ball ast.Term) Action {
+	d := r.debug
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	mode := atomic.LoadInt32(&d.mode)
+	if mode == debugOff {
+		return Creep
+	}
+	if mode == debugLeap {
+		if port != CallPort || !d.isSpy(f) {
+			return Creep
+		}
+		atomic.StoreInt32(&d.mode, debugTrace)
+	}
+	if d.skipDepth >= 0 {
+		if depth > d.skipDepth {
+			return Creep
+		}
+		d.skipDepth = -1
+	}
+
+	var names map[string]string
+	if fr := frameFrom(ctx); fr != nil {
+		names = fr.query.names
+	}
+	e := &TraceEvent{
+		Port:      port,
+		Depth:     depth + 1,
+		Goal:      d.display(names, c.Ground(f)),
+		Bindings:  make(map[string]ast.Term),
+		Exception: ball,
+	}
+	for name := range c.B {
+		if original, ok := d.sourceName(names, name); ok {
+			e.Bindings[original] = d.display(names, c.Ground(ast.CreateVariable(name)))
+		} else if !strings.HasPrefix(name, "_") {
+			e.Bindings[name] = d.display(names, c.Ground(ast.CreateVariable(name)))
+		}
+	}
+
+	action := d.tracer.Trace(e)
+	switch action {
+	case Skip:
+		if port == CallPort || port == RedoPort {
+			d.skipDepth = depth
+		}
+	case Leap:
+		atomic.StoreInt32(&d.mode, debugLeap)
+	case Abort:
+		atomic.StoreInt32(&d.mode, debugOff)
+		if len(d.spies) > 0 {
+			atomic.StoreInt32(&d.mode, debugLeap)
+		}
+	}
+	return action
+}
+
+// display replaces the resolver's variable names with the ones from the source, the caller must hold the lock
+func (d *debugger) display(names map[string]string, t ast.Term) ast.Term {
+	switch t.GetType() {
+	case ast.T_Variable:
+		if original, ok := d.sourceName(names, t.String()); ok {
+			return ast.CreateVariable(original)
+		}
+		if strings.HasPrefix(t.String(), "_sf") {
+			return ast.CreateVariable("_G" + strings.TrimPrefix(t.String(), "_sf"))
+		}
+	case ast.T_Fact:
+		f := t.(*ast.Fact)
+		if len(f.Args) == 0 && f.Head != "|" {
+			return ast.CreateAtom(f.Head)
+		}
+		args := make([]ast.Term, len(f.Args))
+		for i, a := range f.Args {
+			args[i] = d.display(names, a)
+		}
+		return ast.CreateFact(f.Head, args...)
+	}
+	return t
+}
+
+/**
+ * traceFact resolves the fact while reporting its ports to the tracer.
+ * Since solutions are searched for as soon as the previous one has been handed on, Redo is only reported
+ * when another solution is actually found and a goal which has already exited ends without a Fail.
+ */
+func (r *R) traceFact(ctx context.Context, f *ast.Fact, c *Bindings, out chan<- *Bindings) {
+	defer close(out)
+	depth := 0
+	if fr := frameFrom(ctx); fr != nil {
+		depth = fr.depth
+	}
+
+	if r.event(ctx, CallPort, depth, f, c, nil) == Abort {
+		send(ctx, out, Throw(ast.CreateAtom("$aborted")))
+		return
+	}
+
+	solutions := make(chan *Bindings, paralellism)
+	go r.resolveFact(ctx, f, c, solutions)
+	exits := 0
+	for b := range solutions {
+		if b.IsException() {
+			r.event(ctx, ExceptionPort, depth, f, c, b.Exception)
+			send(ctx, out, b)
+			return
+		}
+		if exits > 0 && r.event(ctx, RedoPort, depth, f, c, nil) == Abort {
+			send(ctx, out, Throw(ast.CreateAtom("$aborted")))
+			return
+		}
+		exits++
+		if r.event(ctx, ExitPort, depth, f, b, nil) == Abort {
+			send(ctx, out, Throw(ast.CreateAtom("$aborted")))
+			return
+		}
+		if !send(ctx, out, b) {
+			return
+		}
+	}
+	if exits == 0 && ctx.Err() == nil {
+		r.event(ctx, FailPort, depth, f, c, nil)
+	}
+}
+
+/**
+ * newDebug provides the debugging builtins.
+ *   trace/0 shows every port of every goal from now on
+ *   notrace/0 stops tracing
+ *   spy(Name) or spy(Name/Arity) starts tracing whenever the predicate is called
+ *   nospy(Name) or nospy(Name/Arity) removes a spy point
+ */
+func newDebug(r *R) nativePredicates {
+	return nativePredicates{
+		"trace/0":   r.trace,
+		"notrace/0": r.notrace,
+		"spy/1":     r.spy(true),
+		"nospy/1":   r.spy(false),
+	}
+}
+
+func (r *R) trace(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	atomic.StoreInt32(&r.debug.mode, debugTrace)
+	send(ctx, out, c)
+}
+
+func (r *R) notrace(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	r.debug.lock.Lock()
+	defer r.debug.lock.Unlock()
+	if len(r.debug.spies) > 0 {
+		atomic.StoreInt32(&r.debug.mode, debugLeap)
+	} else {
+		atomic.StoreInt32(&r.debug.mode, debugOff)
+	}
+	r.debug.skipDepth = -1
+	send(ctx, out, c)
+}
+
+func (r *R) spy(add bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		key, ex := spyKey(c.Ground(args[0]))
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+
+		d := r.debug
+		d.lock.Lock()
+		if add {
+			d.spies[key] = true
+			atomic.CompareAndSwapInt32(&d.mode, debugOff, debugLeap)
+		} else {
+			delete(d.spies, key)
+			if len(d.spies) == 0 {
+				atomic.CompareAndSwapInt32(&d.mode, debugLeap, debugOff)
+			}
+		}
+		d.lock.Unlock()
+		send(ctx, out, c)
+	}
+}
+
+// spyKey converts Name or Name/Arity into the key used in the spy table
+func spyKey(spec ast.Term) (string, *Bindings) {
+	switch spec.GetType() {
+	case ast.T_Variable:
+		return "", instantiationError()
+	case ast.T_Atom:
+		return spec.String(), nil
+	case ast.T_Fact:
+		f := spec.(*ast.Fact)
+		if f.Head == "/" && len(f.Args) == 2 && f.Args[0].GetType() == ast.T_Atom {
+			if arity, ok := intValue(f.Args[1], EmptyBindings()); ok && arity >= 0 {
+				return signature(f.Args[0].String(), arity), nil
+			}
+		}
+	}
+	return "", typeError("predicate_indicator", spec)
+}
@@ -0,0 +1,151 @@
+package resolver_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
+	"github.com/kkoch986/gopl/resolver"
+)
+
+// recordingTracer keeps every event it sees and answers with the scripted actions, then creeps
+type recordingTracer struct {
+	events  []string
+	last    []*resolver.TraceEvent
+	actions []resolver.Action
+}
+
+func (t *recordingTracer) Trace(e *resolver.TraceEvent) resolver.Action {
+	t.events = append(t.events, e.String())
+	t.last = append(t.last, e)
+	if len(t.actions) == 0 {
+		return resolver.Creep
+	}
+	a := t.actions[0]
+	t.actions = t.actions[1:]
+	return a
+}
+
+// traceIndex contains
+//
+//	p(X) :- q(X), r(X).
+//	q(a).
+//	r(a).
+//	s :- fail.
+//	t :- throw(oops).
+func traceIndex() indexer.Indexer {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+	i := indexer.NewDefault()
+	i.IndexStatement(ast.CreateRule(f("p", v("X")), f("q", v("X")), f("r", v("X"))))
+	i.IndexStatement(f("q", a("a")))
+	i.IndexStatement(f("r", a("a")))
+	i.IndexStatement(ast.CreateRule(f("s"), f("fail")))
+	i.IndexStatement(ast.CreateRule(f("t"), f("throw", a("oops"))))
+	return i
+}
+
+func TestTracePorts(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+
+	r := resolver.New(traceIndex())
+	tracer := &recordingTracer{}
+	r.SetTracer(tracer)
+
+	expectOne(t, "trace p(Y)", solve(r, f("trace"), f("p", v("Y")), f("notrace")), map[string]string{"Y": "a"})
+	expected := []string{
+		"   Call: (1) p(Y)",
+		"   Call: (2) q(X)",
+		"   Exit: (2) q(a)",
+		"   Call: (2) r(a)",
+		"   Exit: (2) r(a)",
+		"   Exit: (1) p(a)",
+		"   Call: (1) notrace",
+	}
+	if !reflect.DeepEqual(tracer.events, expected) {
+		t.Errorf("expected events\n%v\ngot\n%v", expected, tracer.events)
+	}
+	if x := tracer.last[4].Bindings["X"]; x == nil || x.String() != "a" {
+		t.Errorf("expected X = a in the bindings of %s, got %v", tracer.events[4], tracer.last[4].Bindings)
+	}
+
+	tracer.events = nil
+	if results := solve(r, f("trace"), f("s")); len(results) != 0 {
+		t.Errorf("expected s to fail, got %v", results)
+	}
+	expected = []string{
+		"   Call: (1) s",
+		"   Call: (2) fail",
+		"   Fail: (2) fail",
+		"   Fail: (1) s",
+	}
+	if !reflect.DeepEqual(tracer.events, expected) {
+		t.Errorf("expected events\n%v\ngot\n%v", expected, tracer.events)
+	}
+
+	tracer.events = nil
+	results := solve(r, f("t"))
+	if len(results) != 1 || results[0].Exception.String() != "oops" {
+		t.Errorf("expected oops to be thrown, got %v", results)
+	}
+	expected = []string{
+		"   Call: (1) t",
+		"   Call: (2) throw(oops)",
+		"   Exception: (2) throw(oops) oops",
+		"   Exception: (1) t oops",
+	}
+	if !reflect.DeepEqual(tracer.events, expected) {
+		t.Errorf("expected events\n%v\ngot\n%v", expected, tracer.events)
+	}
+}
+
+func TestTraceActions(t *testing.T) {
+	f := ast.CreateFact
+	v := ast.CreateVariable
+	a := ast.CreateAtom
+
+	r := resolver.New(traceIndex())
+	tracer := &recordingTracer{actions: []resolver.Action{resolver.Skip}}
+	r.SetTracer(tracer)
+
+	// skipping hides everything inside p
+	expectOne(t, "skip", solve(r, f("trace"), f("p", v("Y")), f("notrace")), nil)
+	expected := []string{"   Call: (1) p(Y)", "   Exit: (1) p(a)", "   Call: (1) notrace"}
+	if !reflect.DeepEqual(tracer.events, expected) {
+		t.Errorf("expected events\n%v\ngot\n%v", expected, tracer.events)
+	}
+
+	// leaping hides everything until the spy point
+	tracer.events = nil
+	expectOne(t, "spy", solve(r, f("spy", a("r")), f("p", v("Y")), f("nospy", a("r")), f("notrace")), nil)
+	expected = []string{
+		"   Call: (2) r(a)",
+		"   Exit: (2) r(a)",
+		"   Exit: (1) p(a)",
+		"   Call: (1) nospy(r)",
+		"   Exit: (1) nospy(r)",
+		"   Call: (1) notrace",
+	}
+	if !reflect.DeepEqual(tracer.events, expected) {
+		t.Errorf("expected events\n%v\ngot\n%v", expected, tracer.events)
+	}
+
+	// once the spy point is removed and tracing is stopped nothing is traced
+	tracer.events = nil
+	expectOne(t, "nospy", solve(r, f("p", v("Y"))), nil)
+	if len(tracer.events) != 0 {
+		t.Errorf("expected no events, got %v", tracer.events)
+	}
+
+	tracer.actions = []resolver.Action{resolver.Abort}
+	results := solve(r, f("trace"), f("p", v("Y")))
+	if len(results) != 1 || results[0].Exception.String() != "$aborted" {
+		t.Errorf("expected the query to be aborted, got %v", results)
+	}
+
+	expectError(t, "spy(X)", solve(r, f("spy", v("X"))), "instantiation_error")
+	expectError(t, "spy(1)", solve(r, f("spy", num(1))), "type_error(predicate_indicator,1.000000)")
+}
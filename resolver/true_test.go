@@ -2,66 +2,35 @@ package resolver
 
 import "testing"
 import "github.com/kkoch986/gopl/ast"
+import "github.com/kkoch986/gopl/indexer"
 
 /**
- * TestTruePassthrough ensures that this does not respond to anything but true/0
+ * TestTruePassthrough ensures that true is only registered as true/0
  */
 func TestTruePassthrough(t *testing.T) {
-	f := &True{}
-	m := make(chan bool)
-	out := make(chan *Bindings)
-
-	go f.Resolve(&ast.Fact{Head: "something", Args: []ast.Term{}}, EmptyBindings(), out, m)
-
-	select {
-	case o := <-out:
-		t.Errorf("True resolver wrote results for something/0 (got %s)", o)
-	case matched := <-m:
-		if matched {
-			t.Errorf("True resolver matched for something/0")
-		}
+	r := New(indexer.NewDefault())
+	if !r.Registered("true", 0) {
+		t.Errorf("true/0 is not registered")
 	}
 
-	// make sure it doesnt match "fail/1"
-	m = make(chan bool)
-	out = make(chan *Bindings)
-	go f.Resolve(&ast.Fact{Head: "true", Args: []ast.Term{ast.CreateAtom("A")}}, EmptyBindings(), out, m)
-	select {
-	case o := <-out:
-		t.Errorf("True resolver wrote results for true/1 (got %s)", o)
-	case matched := <-m:
-		if matched {
-			t.Errorf("True resolver matched for true/1")
-		}
+	// make sure it doesnt match "true/1"
+	if r.Registered("true", 1) {
+		t.Errorf("true/1 is registered")
 	}
-
 }
 
 /**
- * TestTrueResults affirms that true matches true/0 and returns the given bindings
+ * TestTrueResults affirms that true/0 returns the given bindings
  */
 func TestTrueResults(t *testing.T) {
-	f := &True{}
-	m := make(chan bool)
-	out := make(chan *Bindings)
+	r := New(indexer.NewDefault())
 	inputBindings := EmptyBindings()
 
-	go f.Resolve(&ast.Fact{Head: "true", Args: []ast.Term{}}, inputBindings, out, m)
-
-	foundResults := false
-	select {
-	case o := <-out:
-		foundResults = true
-		if o != inputBindings {
-			t.Errorf("True resolver wrote incorrect results for true/0 (expected %s, got %s)", inputBindings, o)
-		}
-	case matched := <-m:
-		if !matched {
-			t.Errorf("True resolver did not match for true/0")
-		}
+	results := resolveFact(r, &ast.Fact{Head: "true", Args: []ast.Term{}}, inputBindings)
+	if len(results) != 1 {
+		t.Fatalf("true/0 should write exactly one result (got %s)", results)
 	}
-
-	if !foundResults {
-		t.Errorf("True resolver did not write results for true/0")
+	if results[0] != inputBindings {
+		t.Errorf("true/0 wrote incorrect results (expected %s, got %s)", inputBindings, results[0])
 	}
 }
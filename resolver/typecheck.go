@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"context"
+	"math"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * newTypeChecks provides the builtins which test what kind of term their argument is bound to:
+ *   var/1, nonvar/1, atom/1, number/1, integer/1, float/1, atomic/1, compound/1, callable/1, string/1, is_list/1 and ground/1
+ * Numbers dont keep whether they were written as integers, so integer/1 holds for a whole number and float/1 for any other.
+ * `[]` is an atom and every other list is compound.
+ */
+func newTypeChecks() nativePredicates {
+	return nativePredicates{
+		"var/1":    typeCheck(func(t ast.Term) bool { return t.GetType() == ast.T_Variable }),
+		"nonvar/1": typeCheck(func(t ast.Term) bool { return t.GetType() != ast.T_Variable }),
+		"atom/1":   typeCheck(isAtom),
+		"number/1": typeCheck(func(t ast.Term) bool { return t.GetType() == ast.T_Number }),
+		"integer/1": typeCheck(func(t ast.Term) bool {
+			n, ok := t.(*ast.NumericLiteral)
+			return ok && n.Value() == math.Trunc(n.Value())
+		}),
+		"float/1": typeCheck(func(t ast.Term) bool {
+			n, ok := t.(*ast.NumericLiteral)
+			return ok && n.Value() != math.Trunc(n.Value())
+		}),
+		"atomic/1": typeCheck(func(t ast.Term) bool {
+			return isAtom(t) || t.GetType() == ast.T_Number || t.GetType() == ast.T_String
+		}),
+		"compound/1": typeCheck(func(t ast.Term) bool {
+			f, ok := t.(*ast.Fact)
+			return ok && len(f.Args) > 0
+		}),
+		"callable/1": typeCheck(func(t ast.Term) bool {
+			return t.GetType() == ast.T_Atom || t.GetType() == ast.T_Fact
+		}),
+		"string/1": typeCheck(func(t ast.Term) bool { return t.GetType() == ast.T_String }),
+		"is_list/1": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			if _, ok := properList(args[0], c); ok {
+				send(ctx, out, c)
+			}
+		},
+		"ground/1": func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+			if isGround(c.Ground(args[0])) {
+				send(ctx, out, c)
+			}
+		},
+	}
+}
+
+// typeCheck succeeds if test holds for what the argument is bound to
+func typeCheck(test func(ast.Term) bool) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		if test(c.Dereference(args[0])) {
+			send(ctx, out, c)
+		}
+	}
+}
+
+// isAtom reports whether the term is an atom, which includes a compound without arguments like `foo()` and `[]`
+func isAtom(t ast.Term) bool {
+	if t.GetType() == ast.T_Atom {
+		return true
+	}
+	f, ok := t.(*ast.Fact)
+	return ok && len(f.Args) == 0
+}
+
+// isGround reports whether there are no variables in the (already grounded) term
+func isGround(t ast.Term) bool {
+	switch t := t.(type) {
+	case *ast.Variable:
+		return false
+	case *ast.Fact:
+		for _, a := range t.Args {
+			if !isGround(a) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -4,24 +4,10 @@ import (
 	"github.com/kkoch986/gopl/ast"
 )
 
-/**
- * Equals (=/2) will simly try to unify the two given args
- */
-type Equals struct{}
-
-func (w *Equals) Resolve(fact *ast.Fact, c *Bindings, out chan<- *Bindings, m chan<- bool) {
-	if fact.Signature().String() != "=/2" {
-		m <- false
-		return
-	}
-	defer close(out)
-	defer close(m)
-
-	b := unifyTerms(fact.Args[0], fact.Args[1], c)
-	if b != nil {
-		out <- b
-	}
-	m <- true
+// equals (=/2) will simly try to unify the two given args
+func equals(args []ast.Term, c *Bindings) (*Bindings, bool, error) {
+	b := unifyTerms(args[0], args[1], c)
+	return b, b != nil, nil
 }
 
 /**
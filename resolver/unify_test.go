@@ -1,9 +1,11 @@
 package resolver_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/indexer"
 	"github.com/kkoch986/gopl/resolver"
 )
 
@@ -15,23 +17,16 @@ type testCase struct {
 }
 
 func runUnifyTestCase(c *testCase, t *testing.T) {
-	r := &resolver.Equals{}
-	m := make(chan bool)
-	out := make(chan *resolver.Bindings)
-	go r.Resolve(c.F, c.InitialBindings, out, m)
+	r := resolver.New(indexer.NewDefault())
+	if matched := r.Registered(c.F.Head, len(c.F.Args)); matched != c.ShouldMatch {
+		t.Errorf("unexpected matched value, expected %v, got %v", c.ShouldMatch, matched)
+	}
 
+	out := make(chan *resolver.Bindings)
+	go r.ResolveFact(context.Background(), c.F, c.InitialBindings, out)
 	bindings := []*resolver.Bindings{}
-	done := false
-	for !done {
-		select {
-		case b := <-out:
-			bindings = append(bindings, b)
-		case matched := <-m:
-			done = true
-			if matched != c.ShouldMatch {
-				t.Errorf("unexpected matched value, expected %v, got %v", c.ShouldMatch, matched)
-			}
-		}
+	for b := range out {
+		bindings = append(bindings, b)
 	}
 	if len(bindings) != len(c.ExpectedBindings) {
 		t.Fatalf(
@@ -0,0 +1,235 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+
+	"github.com/kkoch986/gopl/ast"
+)
+
+/**
+ * Write provides the term output predicates (write/1, print/1, writeq/1, write_canonical/1, nl/0, tab/1),
+ * format/1,2,3 and with_output_to/2.
+ * Everything is written to the resolver's current output, see R.SetOutput.
+ */
+type Write struct {
+	r *R
+}
+
+func newWrite(r *R) nativePredicates {
+	w := &Write{r: r}
+	return nativePredicates{
+		"write/1":           w.writeTerm(ast.WriteOptions{Ops: r.ops}),
+		"write/2":           w.writeTerm(ast.WriteOptions{Ops: r.ops}),
+		"print/1":           w.writeTerm(ast.WriteOptions{Quoted: true, Ops: r.ops}),
+		"print/2":           w.writeTerm(ast.WriteOptions{Quoted: true, Ops: r.ops}),
+		"writeq/1":          w.writeTerm(ast.WriteOptions{Quoted: true, Ops: r.ops}),
+		"writeq/2":          w.writeTerm(ast.WriteOptions{Quoted: true, Ops: r.ops}),
+		"write_canonical/1": w.writeTerm(ast.WriteOptions{Quoted: true}),
+		"write_canonical/2": w.writeTerm(ast.WriteOptions{Quoted: true}),
+		"nl/0":              w.nl,
+		"nl/1":              w.nl,
+		"tab/1":             w.tab,
+		"tab/2":             w.tab,
+		"with_output_to/2":  w.withOutputTo,
+		"format/1":          w.format1,
+		"format/2":          w.format2,
+		"format/3":          w.format3,
+	}
+}
+
+/**
+ * target splits the arguments into the stream to write to and the rest of the arguments.
+ * If there are more than n arguments, the first one is the stream, otherwise the current output is used.
+ */
+func (r *R) target(args []ast.Term, n int, c *Bindings) (*stream, []ast.Term, *Bindings) {
+	if len(args) > n {
+		s, ex := r.streams.lookupOutput(args[0], c)
+		return s, args[1:], ex
+	}
+	return r.streams.currentOutput(), args, nil
+}
+
+// emit writes the text to the stream, then sends either the bindings or an io_error to out
+func emit(ctx context.Context, s *stream, text string, c *Bindings, out chan<- *Bindings) {
+	if err := s.write(text); err != nil {
+		send(ctx, out, ioError("write", s.handle()))
+		return
+	}
+	send(ctx, out, c)
+}
+
+// writeTerm creates write/1,2 and its variants which only differ in their options
+func (w *Write) writeTerm(opts ast.WriteOptions) nativePredicate {
+	return func(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+		s, args, ex := w.r.target(args, 1, c)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		emit(ctx, s, ast.WriteTerm(c.Ground(args[0]), opts), c, out)
+	}
+}
+
+func (w *Write) nl(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, _, ex := w.r.target(args, 0, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	emit(ctx, s, "\n", c, out)
+}
+
+// tab(N) writes N spaces, N can be any arithmetic expression
+func (w *Write) tab(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	s, args, ex := w.r.target(args, 1, c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	n, err := evalArithmetic(args[0], c)
+	if err != nil {
+		log.Printf("[ERROR][tab/1] %s", err)
+		return
+	}
+	emit(ctx, s, strings.Repeat(" ", int(n)), c, out)
+}
+
+/**
+ * capture runs fn with the current output redirected into a temporary stream and returns what was written.
+ * The previous output is restored before returning.
+ */
+func (w *Write) capture(ctx context.Context, fn func()) string {
+	var buf bytes.Buffer
+	s := &stream{mode: "write", writer: &buf}
+	w.r.streams.add(s)
+	prev := w.r.streams.setOutput(s)
+	defer w.r.streams.remove(s)
+	defer w.r.streams.setOutput(prev)
+	fn()
+	return buf.String()
+}
+
+// sinkValue converts captured text into the term required by a sink (atom(A), string(S), codes(C) or chars(C))
+func sinkValue(sink ast.Term, c *Bindings) (ast.Term, func(string) ast.Term, bool) {
+	sink = c.Dereference(sink)
+	if sink.GetType() != ast.T_Fact {
+		return nil, nil, false
+	}
+	f := sink.(*ast.Fact)
+	if len(f.Args) != 1 {
+		return nil, nil, false
+	}
+	switch f.Head {
+	case "atom":
+		return f.Args[0], atomTerm, true
+	case "string":
+		return f.Args[0], stringTerm, true
+	case "codes":
+		return f.Args[0], func(s string) ast.Term { return codeList(s) }, true
+	case "chars":
+		return f.Args[0], func(s string) ast.Term { return charList(s) }, true
+	}
+	return nil, nil, false
+}
+
+/**
+ * with_output_to(Sink, Goal)
+ * Runs Goal once, capturing anything it writes into Sink.
+ * Sink is one of atom(A), string(S), codes(C) or chars(C).
+ */
+func (w *Write) withOutputTo(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	target, mk, ok := sinkValue(args[0], c)
+	if !ok {
+		if c.Dereference(args[0]).GetType() == ast.T_Variable {
+			send(ctx, out, instantiationError())
+		} else {
+			send(ctx, out, domainError("output_sink", c.Ground(args[0])))
+		}
+		return
+	}
+
+	var b *Bindings
+	text := w.capture(ctx, func() {
+		b = w.r.solveOnce(ctx, args[1], c)
+	})
+	if b == nil {
+		return
+	}
+	if b.IsException() {
+		send(ctx, out, b)
+		return
+	}
+	unifyAndSend(ctx, target, mk(text), b, out)
+}
+
+func (w *Write) format1(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	w.format2(ctx, []ast.Term{args[0], ast.CreateList()}, c, out)
+}
+
+// format(Format, Args) writes Args to the current output according to Format
+func (w *Write) format2(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	text, ex := w.formatText(args[0], args[1], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	emit(ctx, w.r.streams.currentOutput(), text, c, out)
+}
+
+/**
+ * format(Output, Format, Args) is like format/2 but writes to Output instead.
+ * Output is either a stream or a sink like atom(A) (see with_output_to/2).
+ */
+func (w *Write) format3(ctx context.Context, args []ast.Term, c *Bindings, out chan<- *Bindings) {
+	target, mk, ok := sinkValue(args[0], c)
+	if !ok {
+		s, ex := w.r.streams.lookupOutput(args[0], c)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		text, ex := w.formatText(args[1], args[2], c)
+		if ex != nil {
+			send(ctx, out, ex)
+			return
+		}
+		emit(ctx, s, text, c, out)
+		return
+	}
+
+	text, ex := w.formatText(args[1], args[2], c)
+	if ex != nil {
+		send(ctx, out, ex)
+		return
+	}
+	unifyAndSend(ctx, target, mk(text), c, out)
+}
+
+// formatText runs the format directives, any problems are returned as a `format(Message)` error
+func (w *Write) formatText(format ast.Term, args ast.Term, c *Bindings) (string, *Bindings) {
+	f, ok := textOf(format, c)
+	if !ok {
+		if f, ok = codesText(format, c); !ok {
+			if f, ok = charsText(format, c); !ok {
+				if c.Dereference(format).GetType() == ast.T_Variable {
+					return "", instantiationError()
+				}
+				return "", typeError("text", c.Ground(format))
+			}
+		}
+	}
+
+	// a single argument doesnt have to be wrapped in a list
+	items, ok := properList(args, c)
+	if !ok {
+		items = []ast.Term{c.Ground(args)}
+	}
+	text, err := formatString(f, items, w.r.ops)
+	if err != nil {
+		return "", isoError(ast.CreateFact("format", ast.CreateStringLiteral(err.Error())))
+	}
+	return text, nil
+}
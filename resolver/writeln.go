@@ -1,23 +1,17 @@
 package resolver
 
 import (
-	"fmt"
-
 	"github.com/kkoch986/gopl/ast"
 )
 
-type Writeln struct{}
-
-func (w *Writeln) Resolve(fact *ast.Fact, c *Bindings, out chan<- *Bindings, m chan<- bool) {
-	if fact.Signature().String() != "writeln/1" {
-		m <- false
-		return
+// writeln/1,2 writes the term followed by a newline to the current output or the given stream
+func (r *R) writeln(args []ast.Term, c *Bindings) (*Bindings, bool, error) {
+	s, args, ex := r.target(args, 1, c)
+	if ex != nil {
+		return nil, false, &Exception{ex.Exception}
 	}
-
-	defer close(out)
-	defer close(m)
-
-	fmt.Println(c.Dereference(fact.Args[0]))
-	out <- c
-	m <- true
+	if err := s.write(ast.WriteTerm(c.Ground(args[0]), ast.WriteOptions{Ops: r.ops}) + "\n"); err != nil {
+		return nil, false, &Exception{ioError("write", s.handle()).Exception}
+	}
+	return c, true, nil
 }
@@ -0,0 +1,185 @@
+package syntax
+
+import (
+	"sort"
+	"sync"
+)
+
+// Op is an operator, Type is one of the ISO specifiers: xfx, xfy, yfx, fy, fx, xf or yf
+type Op struct {
+	Priority int
+	Type     string
+	Name     string
+}
+
+/**
+ * Ops is a table of operators used to read terms. Each name can be a prefix operator and either an infix
+ * or a postfix one at the same time. The table is safe to change while it is being read from.
+ */
+type Ops struct {
+	lock    sync.RWMutex
+	prefix  map[string]Op
+	infix   map[string]Op
+	postfix map[string]Op
+}
+
+// isoOps are the operators every table starts with, the ISO ones along with the declarations SWI-Prolog adds
+var isoOps = []Op{
+	{1200, "xfx", ":-"}, {1200, "xfx", "-->"},
+	{1200, "fx", ":-"}, {1200, "fx", "?-"},
+	{1150, "fx", "dynamic"}, {1150, "fx", "discontiguous"}, {1150, "fx", "initialization"},
+	{1150, "fx", "multifile"}, {1150, "fx", "meta_predicate"}, {1150, "fx", "table"},
+	{1100, "xfy", ";"},
+	{1050, "xfy", "->"}, {1050, "xfy", "*->"},
+	{1000, "xfy", ","},
+	{900, "fy", "\\+"},
+	{700, "xfx", "="}, {700, "xfx", "\\="}, {700, "xfx", "=="}, {700, "xfx", "\\=="},
+	{700, "xfx", "@<"}, {700, "xfx", "@>"}, {700, "xfx", "@=<"}, {700, "xfx", "@>="},
+	{700, "xfx", "=.."}, {700, "xfx", "is"}, {700, "xfx", "=:="}, {700, "xfx", "=\\="},
+	{700, "xfx", "<"}, {700, "xfx", ">"}, {700, "xfx", "=<"}, {700, "xfx", ">="},
+	{600, "xfy", ":"},
+	{500, "yfx", "+"}, {500, "yfx", "-"}, {500, "yfx", "/\\"}, {500, "yfx", "\\/"}, {500, "yfx", "xor"},
+	{400, "yfx", "*"}, {400, "yfx", "/"}, {400, "yfx", "//"}, {400, "yfx", "rem"}, {400, "yfx", "mod"},
+	{400, "yfx", "div"}, {400, "yfx", "<<"}, {400, "yfx", ">>"},
+	{200, "xfx", "**"}, {200, "xfy", "^"},
+	{200, "fy", "-"}, {200, "fy", "+"}, {200, "fy", "\\"},
+}
+
+// NewOps returns a table holding the default operators
+func NewOps() *Ops {
+	o := &Ops{
+		prefix:  make(map[string]Op),
+		infix:   make(map[string]Op),
+		postfix: make(map[string]Op),
+	}
+	for _, op := range isoOps {
+		o.Add(op.Priority, op.Type, op.Name)
+	}
+	return o
+}
+
+// IsOpType reports whether typ is one of the operator specifiers
+func IsOpType(typ string) bool {
+	return opClass(typ) != ""
+}
+
+// opClass returns whether an operator type is a prefix, infix or postfix operator
+func opClass(typ string) string {
+	switch typ {
+	case "fx", "fy":
+		return "prefix"
+	case "xfx", "xfy", "yfx":
+		return "infix"
+	case "xf", "yf":
+		return "postfix"
+	}
+	return ""
+}
+
+/**
+ * Add defines an operator, replacing the operator of the same class (prefix, infix or postfix) with that name.
+ * A priority of 0 removes the operator instead. Defining an infix operator removes the postfix one with the same
+ * name and the other way around, since a term could be read either way otherwise.
+ */
+func (o *Ops) Add(priority int, typ string, name string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	op := Op{Priority: priority, Type: typ, Name: name}
+	switch opClass(typ) {
+	case "prefix":
+		o.set(o.prefix, op)
+	case "infix":
+		delete(o.postfix, name)
+		o.set(o.infix, op)
+	case "postfix":
+		delete(o.infix, name)
+		o.set(o.postfix, op)
+	}
+}
+
+func (o *Ops) set(table map[string]Op, op Op) {
+	if op.Priority == 0 {
+		delete(table, op.Name)
+		return
+	}
+	table[op.Name] = op
+}
+
+// All returns every operator ordered by name and then type
+func (o *Ops) All() []Op {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	ret := []Op{}
+	for _, table := range []map[string]Op{o.prefix, o.infix, o.postfix} {
+		for _, op := range table {
+			ret = append(ret, op)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].Type < ret[j].Type
+	})
+	return ret
+}
+
+// Prefix returns the prefix operator with the name, if there is one
+func (o *Ops) Prefix(name string) (Op, bool) {
+	return o.lookup(o.prefix, name)
+}
+
+// Infix returns the infix operator with the name, if there is one
+func (o *Ops) Infix(name string) (Op, bool) {
+	return o.lookup(o.infix, name)
+}
+
+// Postfix returns the postfix operator with the name, if there is one
+func (o *Ops) Postfix(name string) (Op, bool) {
+	return o.lookup(o.postfix, name)
+}
+
+func (o *Ops) lookup(table map[string]Op, name string) (Op, bool) {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	op, ok := table[name]
+	return op, ok
+}
+
+// isOp reports whether the name is an operator of any kind
+func (o *Ops) isOp(name string) bool {
+	_, prefix := o.Prefix(name)
+	_, infix := o.Infix(name)
+	_, postfix := o.Postfix(name)
+	return prefix || infix || postfix
+}
+
+// argMax returns the highest priority the arguments on each side of an operator can have
+func (op Op) argMax() (left int, right int) {
+	left, right = op.Priority-1, op.Priority-1
+	if op.Type[0] == 'y' {
+		left = op.Priority
+	}
+	if op.Type[len(op.Type)-1] == 'y' {
+		right = op.Priority
+	}
+	return left, right
+}
+
+/**
+ * Operator returns the priority and type of the operator with the name, the prefix or postfix one for an arity of 1
+ * and the infix one for 2. It lets terms be written with the table, see ast.WriteOptions.
+ */
+func (o *Ops) Operator(name string, arity int) (int, string, bool) {
+	var op Op
+	var ok bool
+	switch arity {
+	case 1:
+		if op, ok = o.Prefix(name); !ok {
+			op, ok = o.Postfix(name)
+		}
+	case 2:
+		op, ok = o.Infix(name)
+	}
+	return op.Priority, op.Type, ok
+}
@@ -0,0 +1,578 @@
+package syntax
+
+import (
+	"strings"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/lexer"
+	"github.com/kkoch986/gopl/token"
+)
+
+/**
+ * reader reads terms from the tokens of a source with an operator precedence parser driven by an operator table.
+ * Each term is read with a maximum priority, arguments and list items are read at 999 so a `,` separates them
+ * and whole clauses are read at 1200.
+ */
+type reader struct {
+	ops    *Ops
+	tokens []*token.Token
+	lines  *ast.LineIndex
+	// source holds the text of each line for the errors
+	source []string
+	pos    int
+}
+
+func newReader(ops *Ops, src []rune) *reader {
+	return &reader{
+		ops:    ops,
+		tokens: lexer.New(src).Tokens,
+		lines:  ast.NewLineIndex(src),
+		source: strings.Split(string(src), "\n"),
+	}
+}
+
+func (r *reader) peek() *token.Token {
+	return r.tokens[r.pos]
+}
+
+func (r *reader) next() *token.Token {
+	t := r.tokens[r.pos]
+	if t.Type() != token.EOF {
+		r.pos++
+	}
+	return t
+}
+
+func (r *reader) atEOF() bool {
+	return r.peek().Type() == token.EOF
+}
+
+// name returns the atom a token stands for, the lexer gives the operators which are part of the grammar their own types
+func (r *reader) name(t *token.Token) (string, bool) {
+	switch t.Type() {
+	case token.Atom:
+		if curly(t, "{") || curly(t, "}") {
+			return "", false
+		}
+		return lexer.Unquote(t.LiteralString()), true
+	case token.Star, token.Plus, token.Minus, token.Slash, token.Neck, token.QueryPrefix, token.InfixOperator, token.Is:
+		return t.LiteralString(), true
+	}
+	return "", false
+}
+
+// curly reports whether the token is the curly bracket, which the lexer gives the type of an atom
+func curly(t *token.Token, bracket string) bool {
+	return t.Type() == token.Atom && t.LiteralString() == bracket
+}
+
+// opName returns the name of a token which could be an infix or postfix operator
+func (r *reader) opName(t *token.Token) (string, bool) {
+	if t.Type() == token.Comma {
+		return ",", true
+	}
+	return r.name(t)
+}
+
+// adjacent reports whether the token straight after t starts where it ends, i.e. the `(` of `foo(`
+func (r *reader) adjacent(t *token.Token, typ token.Type) bool {
+	n := r.peek()
+	return n.Type() == typ && n.Lext() == t.Rext()
+}
+
+/**
+ * clause reads a term ending with a `.` and returns it along with its position including the `.`.
+ * On an error the rest of the clause is skipped so that the next one can be read.
+ */
+func (r *reader) clause() (ast.Term, ast.Position, *Error) {
+	start := r.pos
+	t, _, err := r.term(1200)
+	if err == nil {
+		if r.peek().Type() != token.End {
+			err = r.unexpected(r.peek(), "'.'", "an operator")
+		} else {
+			end := r.next()
+			return t, r.lines.Span(r.tokens[start], end), nil
+		}
+	}
+	for !r.atEOF() && r.peek().Type() != token.End {
+		r.pos++
+	}
+	r.next()
+	return nil, ast.Position{}, err
+}
+
+// term reads a term with a priority no higher than max, returning the term and its priority
+func (r *reader) term(max int) (ast.Term, int, *Error) {
+	start := r.pos
+	left, priority, err := r.primary(max)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for {
+		name, ok := r.opName(r.peek())
+		if !ok {
+			return left, priority, nil
+		}
+		if op, ok := r.ops.Infix(name); ok {
+			leftMax, rightMax := op.argMax()
+			if op.Priority <= max && priority <= leftMax {
+				r.next()
+				right, _, err := r.term(rightMax)
+				if err != nil {
+					return nil, 0, err
+				}
+				left = r.compound(name, start, left, right)
+				priority = op.Priority
+				continue
+			}
+		}
+		if op, ok := r.ops.Postfix(name); ok {
+			leftMax, _ := op.argMax()
+			if op.Priority <= max && priority <= leftMax {
+				r.next()
+				left = r.compound(name, start, left)
+				priority = op.Priority
+				continue
+			}
+		}
+		return left, priority, nil
+	}
+}
+
+// primary reads a term which doesnt start with an operand, i.e. an atom, a compound term or a prefix operator
+func (r *reader) primary(max int) (ast.Term, int, *Error) {
+	t := r.peek()
+	name, isName := r.name(t)
+	switch t.Type() {
+	case token.Number, token.Var, token.String, token.EmptyList, token.OpenList, token.OpenParen:
+	default:
+		if !isName && !curly(t, "{") {
+			return nil, 0, r.unexpected(t, "a term")
+		}
+	}
+	r.next()
+	switch t.Type() {
+	case token.Number:
+		n := ast.CreateNumericLiteral(lexer.NumberValue(t.LiteralString()))
+		n.Pos = r.lines.Token(t)
+		return n, 0, nil
+	case token.Var:
+		v := ast.CreateVariable(t.LiteralString())
+		if t.LiteralString() == "_" {
+			v = ast.CreateAnonymousVariable()
+		}
+		v.Pos = r.lines.Token(t)
+		return v, 0, nil
+	case token.String:
+		if t.Literal()[0] == '`' {
+			return r.codes(t), 0, nil
+		}
+		if r.adjacent(t, token.OpenParen) || r.adjacent(t, token.EmptyParens) {
+			return r.functional(t, lexer.Unquote(t.LiteralString()))
+		}
+		s := ast.CreateStringLiteral(lexer.Unquote(t.LiteralString()))
+		s.Pos = r.lines.Token(t)
+		return s, 0, nil
+	case token.EmptyList:
+		list := ast.CreateList()
+		list.Pos = r.lines.Token(t)
+		return list, 0, nil
+	case token.OpenList:
+		return r.list(t)
+	case token.OpenParen:
+		inner, _, err := r.term(1200)
+		if err != nil {
+			return nil, 0, err
+		}
+		if r.peek().Type() != token.CloseParen {
+			return nil, 0, r.unexpected(r.peek(), "')'", "an operator")
+		}
+		r.next()
+		return inner, 0, nil
+	}
+	if curly(t, "{") {
+		return r.braces(t)
+	}
+
+	if r.adjacent(t, token.OpenParen) || r.adjacent(t, token.EmptyParens) {
+		return r.functional(t, name)
+	}
+
+	if op, ok := r.ops.Prefix(name); ok && !r.operand() {
+		if op.Priority > max {
+			r.pos--
+			return nil, 0, r.message(t, "operator priority clash")
+		}
+		_, argMax := op.argMax()
+		arg, _, err := r.term(argMax)
+		if err != nil {
+			return nil, 0, err
+		}
+		return r.compound(name, r.indexOf(t), arg), op.Priority, nil
+	}
+
+	a := ast.CreateAtom(name)
+	a.Pos = r.lines.Token(t)
+	return a, 0, nil
+}
+
+/**
+ * operand reports whether a prefix operator is an atom rather than being applied to what follows it,
+ * which is when nothing that could be its argument follows it. I.e. in `foo(-)`, `[-|T]` and `- = X`.
+ */
+func (r *reader) operand() bool {
+	t := r.peek()
+	switch t.Type() {
+	case token.EOF, token.CloseParen, token.Comma, token.End, token.CloseList, token.Bar:
+		return true
+	}
+	if curly(t, "}") {
+		return true
+	}
+	if name, ok := r.name(t); ok && !r.adjacent(t, token.OpenParen) {
+		_, prefix := r.ops.Prefix(name)
+		_, infix := r.ops.Infix(name)
+		_, postfix := r.ops.Postfix(name)
+		return (infix || postfix) && !prefix
+	}
+	return false
+}
+
+// functional reads the arguments of a compound term written as `name(Arg, ...)`, `name()` is a term with no arguments
+func (r *reader) functional(t *token.Token, name string) (ast.Term, int, *Error) {
+	start := r.indexOf(t)
+	if r.next().Type() == token.EmptyParens {
+		return r.compound(name, start), 0, nil
+	}
+	args := []ast.Term{}
+	for {
+		arg, _, err := r.term(999)
+		if err != nil {
+			return nil, 0, err
+		}
+		args = append(args, arg)
+		switch r.peek().Type() {
+		case token.Comma:
+			r.next()
+		case token.CloseParen:
+			r.next()
+			return r.compound(name, start, args...), 0, nil
+		default:
+			return nil, 0, r.unexpected(r.peek(), "')'", "','", "an operator")
+		}
+	}
+}
+
+/**
+ * list reads the items of a list after its `[`. Each cell of the list runs from its item to the `]`
+ * and the `[]` which ends a proper list is given the position of the `]`.
+ */
+func (r *reader) list(open *token.Token) (ast.Term, int, *Error) {
+	items, starts := []ast.Term{}, []int{}
+	var tail ast.Term
+	for {
+		starts = append(starts, r.pos)
+		item, _, err := r.term(999)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+		if r.peek().Type() == token.Comma {
+			r.next()
+			continue
+		}
+		if r.peek().Type() == token.Bar {
+			r.next()
+			if tail, _, err = r.term(999); err != nil {
+				return nil, 0, err
+			}
+			if r.peek().Type() != token.CloseList {
+				return nil, 0, r.unexpected(r.peek(), "']'", "an operator")
+			}
+		}
+		if r.peek().Type() != token.CloseList {
+			return nil, 0, r.unexpected(r.peek(), "']'", "','", "'|'", "an operator")
+		}
+		break
+	}
+	close := r.next()
+	if tail == nil {
+		empty := ast.CreateList()
+		empty.Pos = r.lines.Token(close)
+		tail = empty
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		cell := ast.CreatePartialList(items[i:i+1], tail).(*ast.Fact)
+		cell.Pos = r.lines.Span(r.tokens[starts[i]], close)
+		tail = cell
+	}
+	tail.(*ast.Fact).Pos = r.lines.Span(open, close)
+	return tail, 0, nil
+}
+
+// braces reads a term in curly brackets after the `{`, which is the term `{}`(Term)
+func (r *reader) braces(open *token.Token) (ast.Term, int, *Error) {
+	inner, _, err := r.term(1200)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !curly(r.peek(), "}") {
+		return nil, 0, r.unexpected(r.peek(), "'}'", "an operator")
+	}
+	r.next()
+	return r.compound("{}", r.indexOf(open), inner), 0, nil
+}
+
+// codes builds a back quoted string, which is the list of the codes of its characters
+func (r *reader) codes(t *token.Token) ast.Term {
+	pos := r.lines.Token(t)
+	items := []ast.Term{}
+	for _, c := range lexer.Unquote(t.LiteralString()) {
+		n := ast.CreateNumericLiteral(float64(c))
+		// the characters cant be told apart once the escapes are gone, so every code has the position of the string
+		n.Pos = pos
+		items = append(items, n)
+	}
+	list := ast.CreateList(items...)
+	cell := list
+	for len(cell.Args) == 2 {
+		cell.Pos = pos
+		cell = cell.Args[1].(*ast.Fact)
+	}
+	cell.Pos = pos
+	return list
+}
+
+// compound creates a compound term running from the token at start to the last one read
+func (r *reader) compound(name string, start int, args ...ast.Term) *ast.Fact {
+	f := ast.CreateFact(name, args...)
+	f.Pos = r.lines.Span(r.tokens[start], r.tokens[r.pos-1])
+	return f
+}
+
+func (r *reader) indexOf(t *token.Token) int {
+	for i := r.pos - 1; i >= 0; i-- {
+		if r.tokens[i] == t {
+			return i
+		}
+	}
+	return 0
+}
+
+// unexpected reports that the token isnt one of the things which could have come next
+func (r *reader) unexpected(t *token.Token, expected ...string) *Error {
+	if t.Type() == token.Error {
+		return r.message(t, lexer.ErrorMessage(t))
+	}
+	if name, ok := r.opName(t); ok && r.ops.isOp(name) && len(expected) > 0 && expected[len(expected)-1] == "an operator" {
+		// the operator is there, it just cant go there because of its priority
+		return r.message(t, "operator priority clash")
+	}
+	err := r.message(t, "")
+	err.Expected = expected
+	return err
+}
+
+// message reports an error at the token
+func (r *reader) message(t *token.Token, message string) *Error {
+	err := &Error{Found: describeToken(t), Message: message}
+	if t.Type() != token.EOF {
+		err.Pos = r.lines.Token(t)
+	} else {
+		// the end of the file is pointed at straight after the last token rather than wherever the file ends
+		err.Pos = ast.Position{Line: 1, Column: 1, EndLine: 1, EndColumn: 1}
+		if last := len(r.tokens) - 2; last >= 0 {
+			err.Pos.Line, err.Pos.Column = r.lines.LineColumn(r.tokens[last].Rext())
+			err.Pos.EndLine, err.Pos.EndColumn = err.Pos.Line, err.Pos.Column
+		}
+	}
+	if err.Pos.Line <= len(r.source) {
+		err.Line = strings.TrimRight(r.source[err.Pos.Line-1], "\r")
+	}
+	return err
+}
+
+/**
+ * statement turns a clause into the statement it stands for. `Head :- Body` is a rule, `:- Goal` and `?- Goal`
+ * are queries and anything else is a fact. A variable in a body is called, like call(Goal).
+ */
+func (r *reader) statement(t ast.Term, pos ast.Position) (ast.Statement, *Error) {
+	if f, ok := t.(*ast.Fact); ok {
+		switch {
+		case f.Head == ":-" && len(f.Args) == 2:
+			head, err := r.head(f.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			body, err := r.goals(f.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			return &ast.Rule{Head: head, Body: &body, Pos: pos}, nil
+		case (f.Head == ":-" || f.Head == "?-") && len(f.Args) == 1:
+			body, err := r.goals(f.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &body, nil
+		}
+	}
+	fact, err := r.head(t)
+	if err != nil {
+		return nil, err
+	}
+	fact.Pos = pos
+	if ast.IsDCG(fact) {
+		// grammar rules are translated when they are indexed, they are only checked here
+		if _, dcgErr := ast.TranslateDCG(fact); dcgErr != nil {
+			return nil, r.at(pos, dcgErr.Error())
+		}
+	}
+	return fact, nil
+}
+
+// goals flattens a conjunction into the goals of a query
+func (r *reader) goals(t ast.Term) (ast.Query, *Error) {
+	switch g := t.(type) {
+	case *ast.Variable:
+		call := ast.CreateFact("call", g)
+		call.Pos = g.Pos
+		return ast.Query{call}, nil
+	case *ast.Fact:
+		if g.Head == "," && len(g.Args) == 2 {
+			lhs, err := r.goals(g.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := r.goals(g.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			return append(lhs, rhs...), nil
+		}
+		if ma := mathAssignment(g); ma != nil {
+			return ast.Query{ma}, nil
+		}
+	}
+	f, err := r.callable(t)
+	if err != nil {
+		return nil, err
+	}
+	return ast.Query{f}, nil
+}
+
+// callable returns the term as a fact, an atom is a fact with no arguments
+func (r *reader) callable(t ast.Term) (*ast.Fact, *Error) {
+	switch c := t.(type) {
+	case *ast.Fact:
+		return c, nil
+	case *ast.Atom:
+		f := ast.CreateFact(c.String())
+		f.Pos = c.Pos
+		return f, nil
+	}
+	return nil, r.at(ast.PositionOf(t), "callable expected but found "+ast.WriteTerm(t, ast.WriteOptions{Quoted: true}))
+}
+
+/**
+ * controlConstructs are the control constructs of ISO prolog, a program cant add clauses to them.
+ * The resolver runs `,/2`, call/1, catch/3, true/0 and fail/0 and raises an existence_error for the others.
+ */
+var controlConstructs = map[string]bool{
+	",/2": true, ";/2": true, "->/2": true, "*->/2": true, "!/0": true,
+	"call/1": true, "catch/3": true, "true/0": true, "fail/0": true,
+}
+
+// head returns the head of a clause, which has to be callable and cant be a control construct
+func (r *reader) head(t ast.Term) (*ast.Fact, *Error) {
+	f, err := r.callable(t)
+	if err != nil {
+		return nil, err
+	}
+	if sig := f.Signature().String(); controlConstructs[sig] {
+		return nil, r.at(ast.PositionOf(t), "permission_error(modify, static_procedure, "+sig+"), the control construct "+sig+" cant be redefined")
+	}
+	return f, nil
+}
+
+// at reports an error about a term rather than a token
+func (r *reader) at(pos ast.Position, message string) *Error {
+	err := &Error{Pos: pos, Message: message}
+	if err.Pos.Line > 0 && err.Pos.Line <= len(r.source) {
+		err.Line = strings.TrimRight(r.source[err.Pos.Line-1], "\r")
+	}
+	return err
+}
+
+/**
+ * mathAssignment returns `X is Expr` as a MathAssignment when Expr only uses the operators it supports,
+ * anything else is left to the is/2 builtin.
+ */
+func mathAssignment(f *ast.Fact) *ast.MathAssignment {
+	if f.Head != "is" || len(f.Args) != 2 {
+		return nil
+	}
+	v, ok := f.Args[0].(*ast.Variable)
+	if !ok {
+		return nil
+	}
+	rhs := mathExpr(f.Args[1])
+	if rhs == nil {
+		return nil
+	}
+	return &ast.MathAssignment{LHS: v, RHS: rhs, Pos: f.Pos}
+}
+
+func mathExpr(t ast.Term) *ast.MathExpr {
+	if f, ok := t.(*ast.Fact); ok && len(f.Args) == 2 && (f.Head == "+" || f.Head == "-") {
+		lhs, rhs := mult(f.Args[0]), mult(f.Args[1])
+		if lhs == nil || rhs == nil {
+			return nil
+		}
+		if f.Head == "+" {
+			return &ast.MathExpr{LHS: lhs, Operator: ast.OP_Add, RHS: rhs}
+		}
+		return &ast.MathExpr{LHS: lhs, Operator: ast.OP_Subtract, RHS: rhs}
+	}
+	if m := mult(t); m != nil {
+		return &ast.MathExpr{LHS: m, Operator: ast.OP_MathExprNoOp}
+	}
+	return nil
+}
+
+func mult(t ast.Term) *ast.Mult {
+	if f, ok := t.(*ast.Fact); ok && len(f.Args) == 2 && (f.Head == "*" || f.Head == "/") {
+		lhs, rhs := factor(f.Args[0]), factor(f.Args[1])
+		if lhs == nil || rhs == nil {
+			return nil
+		}
+		if f.Head == "*" {
+			return &ast.Mult{LHS: lhs, Operator: ast.OP_Mult, RHS: rhs}
+		}
+		return &ast.Mult{LHS: lhs, Operator: ast.OP_Divide, RHS: rhs}
+	}
+	if f := factor(t); f != nil {
+		return &ast.Mult{LHS: f, Operator: ast.OP_MultNoOp}
+	}
+	return nil
+}
+
+func factor(t ast.Term) *ast.Factor {
+	switch v := t.(type) {
+	case *ast.NumericLiteral:
+		return &ast.Factor{Num: v}
+	case *ast.Variable:
+		return &ast.Factor{Var: v}
+	case *ast.Fact:
+		// only operators are nested, anything else (i.e. max(X, Y)) isnt something a MathExpr can hold
+		if len(v.Args) != 2 || !strings.Contains("+-*/", v.Head) || len(v.Head) != 1 {
+			return nil
+		}
+		if e := mathExpr(v); e != nil {
+			return &ast.Factor{Expr: e}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+/**
+ * Package syntax parses source text into statements, reporting every syntax error in the source rather than just the first.
+ * Errors are written the way compilers usually write them, with the source line and a caret under the token that was wrong:
+ *
+ *	family.pl:3:12: expected ')' or ',' but found 'bob'
+ *	parent(tom bob).
+ *	           ^
+ */
+package syntax
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/lexer"
+	"github.com/kkoch986/gopl/token"
+)
+
+// Error is a syntax error in a single clause
+type Error struct {
+	Pos ast.Position
+	// Expected describes each of the tokens which could have come next
+	Expected []string
+	// Found describes the token which was there instead
+	Found string
+	// Message describes errors which arent about a particular token, i.e. an operator priority clash
+	Message string
+	// Line is the line of source the error is on
+	Line string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+	}
+	if len(e.Expected) == 0 {
+		return fmt.Sprintf("%s: unexpected %s", e.Pos, e.Found)
+	}
+	return fmt.Sprintf("%s: expected %s but found %s", e.Pos, orList(e.Expected), e.Found)
+}
+
+// Caret returns the source line with a caret under the column of the error
+func (e *Error) Caret() string {
+	// the lexer counts a tab as 4 columns so they are expanded to keep the caret in line
+	line := strings.Replace(e.Line, "\t", "    ", -1)
+	return line + "\n" + strings.Repeat(" ", e.Pos.Column-1) + "^"
+}
+
+// Errors are all of the syntax errors in a source in the order they appear
+type Errors []*Error
+
+// Error writes each error followed by the line it is on and a caret pointing at it
+func (e Errors) Error() string {
+	reports := []string{}
+	for _, err := range e {
+		reports = append(reports, err.Error()+"\n"+err.Caret())
+	}
+	return strings.Join(reports, "\n")
+}
+
+/**
+ * Parse parses the source with the default operators, file is used in the positions of the statements and errors
+ * and can be empty. When a clause has a syntax error it is skipped up to the `.` which ends it and the rest of the
+ * source is parsed, so the statements from every other clause are returned along with an Errors listing each clause
+ * that was skipped.
+ */
+func Parse(src []rune, file string) ([]ast.Statement, error) {
+	return NewOps().Parse(src, file)
+}
+
+/**
+ * Parse parses the source with the operators in the table, see Parse.
+ * The op/3 directives in the source are applied to the table as they are read so the clauses after them can use
+ * the operators they define.
+ */
+func (o *Ops) Parse(src []rune, file string) ([]ast.Statement, error) {
+	statements, errs := o.parse(src, file, true)
+	if len(errs) > 0 {
+		return statements, errs
+	}
+	return statements, nil
+}
+
+// ParseFile parses the source in the file, see Parse
+func ParseFile(filename string) ([]ast.Statement, error) {
+	if strings.HasSuffix(filename, ".md") {
+		// only the code blocks of a markdown file are source, the lexer blanks out the rest
+		l, err := lexer.NewFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return Parse(l.I, filename)
+	}
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse([]rune(string(src)), filename)
+}
+
+/**
+ * ParseQuery parses a goal typed without the `?-` and `.` around it, i.e. from the shell or engine.Query.
+ * The columns of errors on the first line are those in the goal rather than in the query it was wrapped in.
+ */
+func ParseQuery(goal string) (*ast.Query, error) {
+	return NewOps().ParseQuery(goal)
+}
+
+// ParseQuery parses a goal with the operators in the table, see ParseQuery
+func (o *Ops) ParseQuery(goal string) (*ast.Query, error) {
+	// the `.` is kept apart from the goal so a goal ending in a number isnt read as `2.`
+	const prefix, suffix = "?- ", " ."
+	src := []rune(prefix + goal + suffix)
+	statements, errs := o.parse(src, "", false)
+	for _, err := range errs {
+		if err.Pos.Line == 1 {
+			err.Pos.Column -= len(prefix)
+			err.Pos.EndColumn -= len(prefix)
+			err.Line = strings.TrimPrefix(err.Line, prefix)
+		}
+		if !strings.HasSuffix(err.Line, suffix) {
+			continue
+		}
+		if err.Found == "'.'" && err.Pos.Column == len([]rune(err.Line)) {
+			// the error is at the `.` that was added, which is pointed at straight after the goal
+			err.Found = "the end of the query"
+			err.Pos.Column = len([]rune(strings.TrimRight(strings.TrimSuffix(err.Line, suffix), " \t"))) + 1
+			err.Pos.EndColumn = err.Pos.Column
+		}
+		err.Line = strings.TrimSuffix(err.Line, suffix)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(statements) != 1 || statements[0].GetType() != ast.T_Query {
+		return nil, fmt.Errorf("expected a single query, got %d statements", len(statements))
+	}
+	return statements[0].(*ast.Query), nil
+}
+
+/**
+ * ReadTerm reads a single term ending with a `.` with the operators in the table, as read_term does.
+ * Unlike Parse the term is returned as it was read rather than as a statement.
+ */
+func (o *Ops) ReadTerm(text string) (ast.Term, error) {
+	r := newReader(o, []rune(text))
+	t, _, err := r.clause()
+	if err != nil {
+		return nil, err
+	}
+	if !r.atEOF() {
+		return nil, r.unexpected(r.peek(), "the end of the term")
+	}
+	return t, nil
+}
+
+/**
+ * Clause turns a term into the statement it stands for, the way a clause read from source is, i.e. for the terms
+ * returned by term_expansion/2. pos is used as the position of the statement.
+ */
+func Clause(t ast.Term, pos ast.Position) (ast.Statement, error) {
+	s, err := (&reader{}).statement(t, pos)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Goals flattens a conjunction into the goals of a query, the way the body of a clause is read
+func Goals(t ast.Term) (ast.Query, error) {
+	q, err := (&reader{}).goals(t)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// parse reads each clause of the source in turn, skipping the ones with errors
+func (o *Ops) parse(src []rune, file string, directives bool) ([]ast.Statement, Errors) {
+	r := newReader(o, src)
+	statements, errs := []ast.Statement{}, Errors{}
+	for !r.atEOF() {
+		t, pos, err := r.clause()
+		var s ast.Statement
+		if err == nil {
+			s, err = r.statement(t, pos)
+		}
+		if err != nil {
+			err.Pos.File = file
+			errs = append(errs, err)
+			continue
+		}
+		if q, ok := s.(*ast.Query); ok && directives {
+			o.directive(*q)
+		}
+		statements = append(statements, s)
+	}
+	if file != "" {
+		ast.SetFile(statements, file)
+	}
+	return statements, errs
+}
+
+/**
+ * directive applies the op/3 goals of a directive to the table. Only ground ones are applied here,
+ * the rest are left for op/3 to report when the directive is run.
+ */
+func (o *Ops) directive(q ast.Query) {
+	for _, goal := range q {
+		f, ok := goal.(*ast.Fact)
+		if !ok || f.Head != "op" || len(f.Args) != 3 {
+			continue
+		}
+		priority, ok := f.Args[0].(*ast.NumericLiteral)
+		typ, typOk := f.Args[1].(*ast.Atom)
+		if !ok || !typOk || !IsOpType(typ.String()) {
+			continue
+		}
+		if p := priority.Value(); p != float64(int(p)) || p < 0 || p > 1200 {
+			continue
+		}
+		names := []ast.Term{f.Args[2]}
+		if ast.IsProperList(f.Args[2]) {
+			names, _ = ast.ListToSlice(f.Args[2])
+		}
+		for _, n := range names {
+			if a, ok := n.(*ast.Atom); ok && a.String() != "," {
+				o.Add(int(priority.Value()), typ.String(), a.String())
+			}
+		}
+	}
+}
+
+// describeToken describes a token that was found in the source
+func describeToken(t *token.Token) string {
+	if t.Type() == token.EOF {
+		return "the end of the file"
+	}
+	if t.Type() == token.Atom && t.LiteralString() == "." {
+		return "'.' without any layout after it"
+	}
+	return "'" + t.LiteralString() + "'"
+}
+
+// orList joins the items as `a, b or c`
+func orList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
@@ -0,0 +1,347 @@
+package syntax_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kkoch986/gopl/ast"
+	"github.com/kkoch986/gopl/syntax"
+)
+
+func TestParse(t *testing.T) {
+	src := "parent(tom, bob).\nparent(tom bob).\nparent(bob,\n  ann).\n\tfoo(X :- bar(X).\nlast([a|T]) :- x(\n"
+	statements, err := syntax.Parse([]rune(src), "family.pl")
+
+	var errs syntax.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected syntax errors, got %v", err)
+	}
+	expected := []string{
+		"family.pl:2:12: expected ')', ',' or an operator but found 'bob'",
+		"family.pl:5:11: operator priority clash",
+		"family.pl:6:18: expected a term but found the end of the file",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), err)
+	}
+	for i, e := range expected {
+		if errs[i].Error() != e {
+			t.Errorf("expected %q, got %q", e, errs[i].Error())
+		}
+	}
+	// tabs count for 4 columns
+	if caret := errs[1].Caret(); caret != "    foo(X :- bar(X).\n          ^" {
+		t.Errorf("unexpected caret\n%s", caret)
+	}
+	if !strings.Contains(err.Error(), "parent(tom bob).\n           ^\nfamily.pl:5:11") {
+		t.Errorf("expected each error to be followed by its caret\n%s", err)
+	}
+
+	// the clauses around the errors are still parsed in the right place
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %v", statements)
+	}
+	for i, pos := range []string{"family.pl:1:1", "family.pl:3:1"} {
+		if got := ast.PositionOf(statements[i]).String(); got != pos {
+			t.Errorf("expected statement %d at %s, got %s", i, pos, got)
+		}
+	}
+}
+
+func TestPositions(t *testing.T) {
+	src := "p(X, [a|T], `c`, - 2, {x}) :-\n  q(X), X is 1 + 2, Y.\n:- r(\"s\")."
+	statements, err := syntax.Parse([]rune(src), "p.pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, directive := statements[0].(*ast.Rule), *statements[1].(*ast.Query)
+	args := rule.Head.Args
+	codes := args[2].(*ast.Fact)
+	expected := []struct {
+		term ast.Term
+		pos  string
+	}{
+		{rule, "1:1"},
+		{rule.Head, "1:1"},
+		{args[0], "1:3"},
+		{args[1], "1:6"},
+		{args[1].(*ast.Fact).Args[0], "1:7"},
+		{args[1].(*ast.Fact).Args[1], "1:9"},
+		{codes, "1:13"},
+		{codes.Args[0], "1:13"},
+		{codes.Args[1], "1:13"},
+		{args[3], "1:18"},
+		{args[3].(*ast.Fact).Args[0], "1:20"},
+		{args[4], "1:23"},
+		{args[4].(*ast.Fact).Args[0], "1:24"},
+		{rule.Body, "2:3"},
+		{(*rule.Body)[1].(ast.Term), "2:9"},
+		{(*rule.Body)[2].(ast.Term), "2:21"},
+		{directive[0].(*ast.Fact), "3:4"},
+		{directive[0].(*ast.Fact).Args[0], "3:6"},
+	}
+	for _, c := range expected {
+		if pos := ast.PositionOf(c.term); pos.String() != "p.pl:"+c.pos {
+			t.Errorf("%s: expected it at %s, got %s", c.term, c.pos, pos)
+		}
+	}
+}
+
+func TestAnonymousVariables(t *testing.T) {
+	statements, err := syntax.Parse([]rune("any(_, _, X, X, _G1, _G1)."), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := statements[0].(*ast.Fact).Args
+	// a variable which happens to look like the name given to a `_` is an ordinary variable
+	if args[4].String() != args[5].String() || ast.IsAnonymous(args[4].(*ast.Variable)) {
+		t.Errorf("expected _G1 to be a named variable, got %s", statements[0])
+	}
+	for _, a := range args[:2] {
+		if a.String() == args[4].String() {
+			t.Errorf("expected _ not to be the same variable as _G1, got %s", statements[0])
+		}
+	}
+	if args[0].String() == args[1].String() {
+		t.Errorf("expected each _ to be a different variable, got %s", statements[0])
+	}
+	if args[2].String() != args[3].String() {
+		t.Errorf("expected X to be the same variable, got %s", statements[0])
+	}
+	for _, a := range args[:2] {
+		if !ast.IsAnonymous(a.(*ast.Variable)) {
+			t.Errorf("expected %s to be anonymous", a)
+		}
+	}
+}
+
+func TestParseWithoutErrors(t *testing.T) {
+	for _, src := range []string{"", "  \n", "a(). b() :- a()."} {
+		if _, err := syntax.Parse([]rune(src), ""); err != nil {
+			t.Errorf("%q: unexpected error %v", src, err)
+		}
+	}
+
+	statements, err := syntax.Parse([]rune("a(. b(."), "")
+	if len(statements) != 0 {
+		t.Errorf("expected no statements, got %v", statements)
+	}
+	if errs, ok := err.(syntax.Errors); !ok || len(errs) != 2 {
+		t.Errorf("expected an error for each clause, got %v", err)
+	}
+}
+
+func TestParseRecovery(t *testing.T) {
+	// after an error the rest of the clause is skipped up to its end, so each clause reports one error at most
+	statements, err := syntax.Parse([]rune("p :- a.b.\nq(X) :- X = 1.e2, r(X).\ns."), "")
+	errs, ok := err.(syntax.Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected one error for each of the first two clauses, got %v", err)
+	}
+	for i, pos := range []string{"1:7", "2:14"} {
+		if !strings.HasPrefix(errs[i].Error(), pos+": ") {
+			t.Errorf("expected error %d at %s, got %s", i, pos, errs[i].Error())
+		}
+	}
+	if len(statements) != 1 || statements[0].(*ast.Fact).Head != "s" {
+		t.Errorf("expected the last clause to be read, got %v", statements)
+	}
+
+	_, err = syntax.Parse([]rune("p :- a.b."), "")
+	if errs, ok := err.(syntax.Errors); !ok || len(errs) != 1 {
+		t.Errorf("expected exactly one error, got %v", err)
+	}
+}
+
+func TestClauseHeads(t *testing.T) {
+	cases := map[string]string{
+		"(a, b).":        "1:2: permission_error(modify, static_procedure, ,/2), the control construct ,/2 cant be redefined",
+		"(a ; b).":       "1:2: permission_error(modify, static_procedure, ;/2), the control construct ;/2 cant be redefined",
+		"(a -> b).":      "1:2: permission_error(modify, static_procedure, ->/2), the control construct ->/2 cant be redefined",
+		"(a, b) :- c.":   "1:2: permission_error(modify, static_procedure, ,/2), the control construct ,/2 cant be redefined",
+		"call(X) :- X.":  "1:1: permission_error(modify, static_procedure, call/1), the control construct call/1 cant be redefined",
+		"1.":             "1:1: callable expected but found 1",
+		"X :- a.":        "1:1: callable expected but found X",
+		"p((a, b)).":     "",
+		"p :- (a ; b).":  "",
+		"'->'(a, b, c).": "",
+	}
+	for src, expected := range cases {
+		_, err := syntax.Parse([]rune(src), "")
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", src, err)
+			}
+		} else if errs, ok := err.(syntax.Errors); !ok || len(errs) != 1 || errs[0].Error() != expected {
+			t.Errorf("%q: expected %s, got %v", src, expected, err)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := syntax.ParseQuery("member(X, [1, 2]), X = 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*q) != 2 {
+		t.Errorf("expected 2 goals, got %s", q)
+	}
+
+	cases := map[string]string{
+		"foo(X) bar":     "1:8: expected '.' or an operator but found 'bar'\nfoo(X) bar\n       ^",
+		"foo(X, ":        "1:7: expected a term but found the end of the query\nfoo(X, \n      ^",
+		"foo(X,\n  Y Z)": "2:5: expected ')', ',' or an operator but found 'Z'\n  Y Z)\n    ^",
+	}
+	for goal, expected := range cases {
+		_, err := syntax.ParseQuery(goal)
+		if err == nil || err.Error() != expected {
+			t.Errorf("%q: expected\n%s\ngot\n%v", goal, expected, err)
+		}
+	}
+}
+
+func TestParsePriorityClash(t *testing.T) {
+	// = is xfx so neither side of it can be another =
+	statements, err := syntax.Parse([]rune("x(). foo(a = b = c).\ny(Z)."), "")
+	if err == nil || err.Error() != "1:16: operator priority clash\nx(). foo(a = b = c).\n               ^" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(statements) != 2 {
+		t.Errorf("expected the other clauses to be parsed, got %v", statements)
+	}
+}
+
+func TestParseOperators(t *testing.T) {
+	src := "x(a- -1, 1 - (2 - 3) * 4, f(:-), [-|T], - - a, -(1), \\+ b, (c ; d -> e), (a :- b)).\n" +
+		":- op(700, xfx, likes), op(200, xfy, [++, &]).\n" +
+		"mary likes wine.\n" +
+		"y(a ++ b ++ c & d).\n"
+	ops := syntax.NewOps()
+	statements, err := ops.Parse([]rune(src), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`x(-(a,-1),-(1,*(-(2,3),4)),f(:-),[-|T],-(-(a)),-(1),\+(b),;(c,->(d,e)),:-(a,b))`,
+		"",
+		`likes(mary,wine)`,
+		`y(++(a,++(b,&(c,d))))`,
+	}
+	if len(statements) != len(expected) {
+		t.Fatalf("expected %d statements, got %v", len(expected), statements)
+	}
+	for i, e := range expected {
+		if e == "" {
+			continue
+		}
+		if got := ast.WriteTerm(statements[i].(ast.Term), ast.WriteOptions{Quoted: true}); got != e {
+			t.Errorf("expected %s, got %s", e, got)
+		}
+	}
+
+	// the directive changed the table it was parsed with, the default operators are left alone
+	if op, ok := ops.Infix("likes"); !ok || op.Priority != 700 || op.Type != "xfx" {
+		t.Errorf("expected likes to be an operator, got %v", op)
+	}
+	if _, err := syntax.Parse([]rune("mary likes wine."), ""); err == nil {
+		t.Errorf("expected likes not to be a default operator")
+	}
+	q, err := ops.ParseQuery("X likes wine")
+	if err != nil || (*q)[0].(*ast.Fact).Head != "likes" {
+		t.Errorf("expected the query to use the operator, got %v %v", q, err)
+	}
+
+	ops.Add(0, "xfx", "likes")
+	if _, ok := ops.Infix("likes"); ok {
+		t.Errorf("expected a priority of 0 to remove the operator")
+	}
+}
+
+func TestLexicalSyntax(t *testing.T) {
+	src := "% a comment\n/* a block\n   comment **/ quoted('Hello World', 'it''s', 'tab\\there', '\\x41\\\\101\\', \"say \\\"hi\\\"\").\n" +
+		"symbols(+, [-, *], =, =.., \\+, @>=, [], {}, !, ;).\n" +
+		"numbers(0'a, 0''', 0'\\n, 0x1F, 0o17, 0b101, 1.5e3, 1.0E-2, -3).\n" +
+		"codes(`ab`).\n" +
+		"'quoted head'(X, Y) :- Y is X-1.\n"
+	statements, err := syntax.Parse([]rune(src), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`quoted('Hello World','it\'s','tab\there','AA',"say \"hi\"")`,
+		`symbols(+,[-,*],=,=..,\+,@>=,[],{},!,;)`,
+		`numbers(97,39,10,31,15,5,1500,0.01,-3)`,
+		`codes([97,98])`,
+	}
+	if len(statements) != len(expected)+1 {
+		t.Fatalf("expected %d statements, got %v", len(expected)+1, statements)
+	}
+	if rule := statements[4].(*ast.Rule); rule.Head.Head != "quoted head" || rule.String() != "quoted head(X,Y) :- Y is (X - 1.000000)" {
+		t.Errorf("unexpected rule %s", rule)
+	}
+	for i, e := range expected {
+		if got := ast.WriteTerm(statements[i].(ast.Term), ast.WriteOptions{Quoted: true}); got != e {
+			t.Errorf("expected %s, got %s", e, got)
+		}
+	}
+
+	cases := map[string]string{
+		"a('open).\n":                "1:3: unterminated quoted atom",
+		"a(\"bad \\q\").":            "1:3: unknown escape sequence in string",
+		"a(). /* open":               "1:6: unterminated block comment",
+		"a(X) :- X = 2.":             "",
+		"t :- X = 1.e2, writeln(X).": "1:11: expected '.' or an operator but found '.' without any layout after it",
+		"a(1.5).%c\nb.":              "",
+		"a('.'(b)).":                 "",
+	}
+	for src, expected := range cases {
+		_, err := syntax.Parse([]rune(src), "")
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", src, err)
+			}
+		} else if errs, ok := err.(syntax.Errors); !ok || errs[0].Error() != expected {
+			t.Errorf("%q: expected %s, got %v", src, expected, err)
+		}
+	}
+}
+
+/**
+ * FuzzParse runs arbitrary source through the reader with the standard operators and with a table which also has
+ * user defined infix, prefix and postfix operators (some of them clashing with the standard ones), none of which should panic.
+ */
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"parent(tom, bob).",
+		"a = b = c.",
+		"foo(.",
+		"[a|b,c].",
+		"X is 1 + 2 * Y.",
+		"a() :- b(X), X is (1 + 2) / 3.\n?- a().",
+		"\"str\"(1, [], [X|Y]).",
+		"% comment\n/* block */ a('q''s\\n', `bq`, 0'c, 0x1F, 1.5e-3, =.., [+]).",
+		"a('\\x41\\', \"\\q\"). /* open",
+		":- op(700, xfx, =>), op(200, xfy, [&]). a => - b & c. f(-, [-|-])).",
+		"mary likes ~ wine & cheese ! => - - 1.",
+		"- (1) - - a ! ! & ~ ~ b.",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		_, _ = syntax.Parse([]rune(src), "fuzz.pl")
+		_, _ = syntax.ParseQuery(src)
+
+		ops := syntax.NewOps()
+		ops.Add(700, "xfx", "=>")
+		ops.Add(200, "xfy", "&")
+		ops.Add(900, "fy", "~")
+		ops.Add(100, "yf", "!")
+		ops.Add(400, "yfx", "-")
+		ops.Add(200, "fx", "-")
+		ops.Add(1100, "xfy", "likes")
+		_, _ = ops.Parse([]rune(src), "fuzz.pl")
+		_, _ = ops.ParseQuery(src)
+		_, _ = ops.ReadTerm(src)
+	})
+}
@@ -1,4 +1,4 @@
-// Package token is generated by GoGLL. Do not edit
+// Package token holds the tokens the lexer splits source into
 package token
 
 import (
@@ -73,11 +73,6 @@ func (t *Token) String() string {
 		t.TypeID(), t.lext, t.rext, t.LiteralString())
 }
 
-// Suppress returns true iff t is suppressed by the lexer
-func (t *Token) Suppress() bool {
-	return Suppress[t.typ]
-}
-
 // Type returns the token Type of t
 func (t *Token) Type() Type {
 	return t.typ
@@ -102,81 +97,81 @@ func (t Type) ID() string {
 }
 
 const (
-	Error Type = iota // Error
-	EOF               // $
-	T_0               // (
-	T_1               // ()
-	T_2               // )
-	T_3               // *
-	T_4               // +
-	T_5               // ,
-	T_6               // -
-	T_7               // .
-	T_8               // /
-	T_9               // :-
-	T_10              // ?-
-	T_11              // [
-	T_12              // []
-	T_13              // ]
-	T_14              // atom
-	T_15              // infix_operator
-	T_16              // is
-	T_17              // num_lit
-	T_18              // string_lit
-	T_19              // var
-	T_20              // |
+	Error         Type = iota // Error
+	EOF                       // $
+	OpenParen                 // (
+	EmptyParens               // ()
+	CloseParen                // )
+	Star                      // *
+	Plus                      // +
+	Comma                     // ,
+	Minus                     // -
+	End                       // .
+	Slash                     // /
+	Neck                      // :-
+	QueryPrefix               // ?-
+	OpenList                  // [
+	EmptyList                 // []
+	CloseList                 // ]
+	Atom                      // atom
+	InfixOperator             // infix_operator
+	Is                        // is
+	Number                    // num_lit
+	String                    // string_lit
+	Var                       // var
+	Bar                       // |
 )
 
 var TypeToString = []string{
 	"Error",
 	"EOF",
-	"T_0",
-	"T_1",
-	"T_2",
-	"T_3",
-	"T_4",
-	"T_5",
-	"T_6",
-	"T_7",
-	"T_8",
-	"T_9",
-	"T_10",
-	"T_11",
-	"T_12",
-	"T_13",
-	"T_14",
-	"T_15",
-	"T_16",
-	"T_17",
-	"T_18",
-	"T_19",
-	"T_20",
+	"OpenParen",
+	"EmptyParens",
+	"CloseParen",
+	"Star",
+	"Plus",
+	"Comma",
+	"Minus",
+	"End",
+	"Slash",
+	"Neck",
+	"QueryPrefix",
+	"OpenList",
+	"EmptyList",
+	"CloseList",
+	"Atom",
+	"InfixOperator",
+	"Is",
+	"Number",
+	"String",
+	"Var",
+	"Bar",
 }
 
 var StringToType = map[string]Type{
-	"Error": Error,
-	"EOF":   EOF,
-	"T_0":   T_0,
-	"T_1":   T_1,
-	"T_2":   T_2,
-	"T_3":   T_3,
-	"T_4":   T_4,
-	"T_5":   T_5,
-	"T_6":   T_6,
-	"T_7":   T_7,
-	"T_8":   T_8,
-	"T_9":   T_9,
-	"T_10":  T_10,
-	"T_11":  T_11,
-	"T_12":  T_12,
-	"T_13":  T_13,
-	"T_14":  T_14,
-	"T_15":  T_15,
-	"T_16":  T_16,
-	"T_17":  T_17,
-	"T_18":  T_18,
-	"T_19":  T_19,
-	"T_20":  T_20,
+	"Error":         Error,
+	"EOF":           EOF,
+	"OpenParen":     OpenParen,
+	"EmptyParens":   EmptyParens,
+	"CloseParen":    CloseParen,
+	"Star":          Star,
+	"Plus":          Plus,
+	"Comma":         Comma,
+	"Minus":         Minus,
+	"End":           End,
+	"Slash":         Slash,
+	"Neck":          Neck,
+	"QueryPrefix":   QueryPrefix,
+	"OpenList":      OpenList,
+	"EmptyList":     EmptyList,
+	"CloseList":     CloseList,
+	"Atom":          Atom,
+	"InfixOperator": InfixOperator,
+	"Is":            Is,
+	"Number":        Number,
+	"String":        String,
+	"Var":           Var,
+	"Bar":           Bar,
 }
 
 var TypeToID = []string{
@@ -204,29 +199,3 @@ var TypeToID = []string{
 	"var",
 	"|",
 }
-
-var Suppress = []bool{
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-	false,
-}